@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"fmt"
+	"sync"
 
+	"agent-coach/internal/agent"
 	"agent-coach/internal/llm"
 	"agent-coach/internal/models"
 	"agent-coach/internal/service"
@@ -13,10 +15,55 @@ import (
 )
 
 type App struct {
-	ctx       context.Context
+	ctx context.Context
+
+	// mu guards profile, which SwitchProfile replaces. It is only held long
+	// enough to take a reference, never around a call.
+	mu      sync.Mutex
+	profile *openProfile
+}
+
+// openProfile is an open profile's database and everything built on it.
+// Bindings take a reference for the length of their call, and the database
+// is closed once the profile has been replaced and the last reference is
+// released.
+type openProfile struct {
 	db        *storage.DB
 	llmRouter *llm.Router
 	service   *service.Service
+	location  *storage.Location
+
+	mu      sync.Mutex
+	users   int
+	retired bool
+}
+
+func (p *openProfile) acquire() {
+	p.mu.Lock()
+	p.users++
+	p.mu.Unlock()
+}
+
+func (p *openProfile) release() {
+	p.mu.Lock()
+	p.users--
+	done := p.retired && p.users == 0
+	p.mu.Unlock()
+	if done {
+		p.db.Close()
+	}
+}
+
+// retire marks the profile as replaced, closing its database now if no call
+// is using it and otherwise when the last one releases it.
+func (p *openProfile) retire() {
+	p.mu.Lock()
+	p.retired = true
+	done := p.users == 0
+	p.mu.Unlock()
+	if done {
+		p.db.Close()
+	}
 }
 
 func NewApp() *App {
@@ -26,28 +73,87 @@ func NewApp() *App {
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 
-	db, err := storage.NewDB()
+	location, err := storage.ResolveLocation()
+	if err == nil {
+		err = a.open(location)
+	}
 	if err != nil {
+		// Every App method needs the database, so there is nothing useful to
+		// run without it.
 		runtime.LogErrorf(ctx, "Failed to initialize database: %v", err)
+		runtime.MessageDialog(ctx, runtime.MessageDialogOptions{
+			Type:    runtime.ErrorDialog,
+			Title:   "Database error",
+			Message: fmt.Sprintf("agent-coach could not prepare its database and will exit.\n\n%v", err),
+		})
+		runtime.Quit(ctx)
 		return
 	}
-	a.db = db
 
-	a.llmRouter, err = llm.NewRouter(a.db)
+	runtime.LogInfof(ctx, "Application started successfully (profile %s, database %s)", location.Profile, location.Path)
+}
+
+// open connects to the database at location and swaps in everything that
+// depends on it. The previous database is closed once the calls using it
+// have returned.
+func (a *App) open(location *storage.Location) error {
+	db, err := storage.NewDB(location.Path)
+	if err != nil {
+		return err
+	}
+
+	router, err := llm.NewRouter(db)
 	if err != nil {
-		runtime.LogErrorf(ctx, "Failed to initialize LLM router: %v", err)
-		return
+		db.Close()
+		return fmt.Errorf("failed to initialize LLM router: %w", err)
 	}
 
-	a.service = service.NewService(a.db, a.llmRouter)
+	svc := service.NewService(db, router)
+	if err := svc.PrepareActivity(a.ctx); err != nil {
+		runtime.LogWarningf(a.ctx, "Failed to prepare activity history: %v", err)
+	}
 
-	runtime.LogInfo(ctx, "Application started successfully")
+	a.swap(&openProfile{db: db, llmRouter: router, service: svc, location: location})
+	return nil
 }
 
-func (a *App) shutdown(ctx context.Context) {
-	if a.db != nil {
-		a.db.Close()
+// swap makes p the open profile and retires the previous one.
+func (a *App) swap(p *openProfile) {
+	a.mu.Lock()
+	previous := a.profile
+	a.profile = p
+	a.mu.Unlock()
+
+	if previous != nil {
+		previous.retire()
 	}
+}
+
+// useProfile returns the open profile with a reference taken on it. The
+// caller must release it when done.
+func (a *App) useProfile() *openProfile {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.profile.acquire()
+	return a.profile
+}
+
+// useService returns the service of the open profile. The caller must call
+// release when done with it.
+func (a *App) useService() (svc *service.Service, release func()) {
+	p := a.useProfile()
+	return p.service, p.release
+}
+
+// useRouter returns the LLM router of the open profile. The caller must call
+// release when done with it.
+func (a *App) useRouter() (router *llm.Router, release func()) {
+	p := a.useProfile()
+	return p.llmRouter, p.release
+}
+
+func (a *App) shutdown(ctx context.Context) {
+	a.swap(nil)
 	runtime.LogInfo(ctx, "Application shutdown complete")
 }
 
@@ -56,22 +162,39 @@ func (a *App) shutdown(ctx context.Context) {
 // ============================================================================
 
 func (a *App) CreateGoal(goal models.Goal) (*models.Goal, error) {
-	if err := a.service.CreateGoal(a.ctx, &goal); err != nil {
+	svc, release := a.useService()
+	defer release()
+
+	if err := svc.CreateGoal(a.ctx, &goal); err != nil {
 		return nil, fmt.Errorf("failed to create goal: %w", err)
 	}
 	return &goal, nil
 }
 
 func (a *App) GetGoal(id string) (*models.Goal, error) {
-	return a.service.GetGoal(a.ctx, id)
+	svc, release := a.useService()
+	defer release()
+	return svc.GetGoal(a.ctx, id)
+}
+
+// ListGoals returns a page of goals matching the query, each with task counts,
+// last activity and the next task due.
+func (a *App) ListGoals(query models.GoalQuery) (*models.GoalPage, error) {
+	svc, release := a.useService()
+	defer release()
+	return svc.ListGoals(a.ctx, query)
 }
 
 func (a *App) UpdateGoal(goal models.Goal) error {
-	return a.service.UpdateGoal(a.ctx, &goal)
+	svc, release := a.useService()
+	defer release()
+	return svc.UpdateGoal(a.ctx, &goal)
 }
 
 func (a *App) DeleteGoal(id string) error {
-	return a.service.DeleteGoal(a.ctx, id)
+	svc, release := a.useService()
+	defer release()
+	return svc.DeleteGoal(a.ctx, id)
 }
 
 // ============================================================================
@@ -79,30 +202,208 @@ func (a *App) DeleteGoal(id string) error {
 // ============================================================================
 
 func (a *App) CreateTask(task models.Task) (*models.Task, error) {
-	if err := a.service.CreateTask(a.ctx, &task); err != nil {
+	svc, release := a.useService()
+	defer release()
+
+	if err := svc.CreateTask(a.ctx, &task); err != nil {
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
 	return &task, nil
 }
 
 func (a *App) GetTask(id string) (*models.Task, error) {
-	return a.service.GetTask(a.ctx, id)
+	svc, release := a.useService()
+	defer release()
+	return svc.GetTask(a.ctx, id)
 }
 
 func (a *App) GetTasksByGoalID(goalID string) ([]*models.Task, error) {
-	return a.service.GetTasksByGoalID(a.ctx, goalID)
+	svc, release := a.useService()
+	defer release()
+	return svc.GetTasksByGoalID(a.ctx, goalID)
+}
+
+// GetDependencyGraph returns the goal's tasks as nodes and their
+// prerequisites as edges, for drawing the plan as a graph.
+func (a *App) GetDependencyGraph(goalID string) (*models.DependencyGraph, error) {
+	svc, release := a.useService()
+	defer release()
+	return svc.GetDependencyGraph(a.ctx, goalID)
+}
+
+// AddTaskDependency makes taskID wait on dependsOnID.
+func (a *App) AddTaskDependency(taskID, dependsOnID string) error {
+	svc, release := a.useService()
+	defer release()
+	return svc.AddTaskDependency(a.ctx, taskID, dependsOnID)
+}
+
+func (a *App) RemoveTaskDependency(taskID, dependsOnID string) error {
+	svc, release := a.useService()
+	defer release()
+	return svc.RemoveTaskDependency(a.ctx, taskID, dependsOnID)
 }
 
 func (a *App) GetTodaysTasks() ([]*models.Task, error) {
-	return a.service.GetTodaysTasks(a.ctx)
+	svc, release := a.useService()
+	defer release()
+	return svc.GetTodaysTasks(a.ctx)
+}
+
+// GetThisWeeksTasks returns open tasks due Monday through Sunday of the
+// current week in the user's time zone.
+func (a *App) GetThisWeeksTasks() ([]*models.Task, error) {
+	svc, release := a.useService()
+	defer release()
+	return svc.GetThisWeeksTasks(a.ctx)
+}
+
+// GetOverdueTasks returns open tasks past their due date, for one goal or all
+// goals when goalID is empty.
+func (a *App) GetOverdueTasks(goalID string) ([]*models.Task, error) {
+	svc, release := a.useService()
+	defer release()
+	return svc.GetOverdueTasks(a.ctx, goalID)
+}
+
+// PreviewReschedule proposes new due days for overdue tasks, within the
+// daily minutes capacity and by priority, without changing anything.
+func (a *App) PreviewReschedule(goalID string) (*models.ReschedulePlan, error) {
+	svc, release := a.useService()
+	defer release()
+	return svc.PreviewReschedule(a.ctx, goalID)
+}
+
+// ApplyReschedule applies the moves of a previewed plan, which the user may
+// have trimmed, and returns the rescheduled tasks.
+func (a *App) ApplyReschedule(plan models.ReschedulePlan) ([]*models.Task, error) {
+	svc, release := a.useService()
+	defer release()
+
+	tasks, err := svc.ApplyReschedule(a.ctx, &plan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply reschedule: %w", err)
+	}
+	return tasks, nil
 }
 
 func (a *App) CompleteTask(id string, actualMinutes *int) error {
-	return a.service.CompleteTask(a.ctx, id, actualMinutes)
+	svc, release := a.useService()
+	defer release()
+	return svc.CompleteTask(a.ctx, id, actualMinutes)
 }
 
 func (a *App) LogStruggle(id string, notes string) error {
-	return a.service.LogStruggle(a.ctx, id, notes)
+	svc, release := a.useService()
+	defer release()
+	return svc.LogStruggle(a.ctx, id, notes)
+}
+
+// ============================================================================
+// Milestone Operations
+// ============================================================================
+
+// GetMilestonesByGoalID returns the goal's roadmap in week order, with task
+// progress for each milestone.
+func (a *App) GetMilestonesByGoalID(goalID string) ([]*models.Milestone, error) {
+	svc, release := a.useService()
+	defer release()
+	return svc.GetMilestonesByGoalID(a.ctx, goalID)
+}
+
+func (a *App) UpdateMilestone(milestone models.Milestone) error {
+	svc, release := a.useService()
+	defer release()
+	return svc.UpdateMilestone(a.ctx, &milestone)
+}
+
+func (a *App) DeleteMilestone(id string) error {
+	svc, release := a.useService()
+	defer release()
+	return svc.DeleteMilestone(a.ctx, id)
+}
+
+// ============================================================================
+// Resource Operations
+// ============================================================================
+
+func (a *App) GetResourcesByGoalID(goalID string) ([]*models.Resource, error) {
+	svc, release := a.useService()
+	defer release()
+	return svc.GetResourcesByGoalID(a.ctx, goalID)
+}
+
+// GetResourcesForTask returns the resources attached to the task or to its
+// milestone.
+func (a *App) GetResourcesForTask(taskID string) ([]*models.Resource, error) {
+	svc, release := a.useService()
+	defer release()
+	return svc.GetResourcesForTask(a.ctx, taskID)
+}
+
+// UpdateResource saves a resource's status and notes.
+func (a *App) UpdateResource(resource models.Resource) error {
+	svc, release := a.useService()
+	defer release()
+	return svc.UpdateResource(a.ctx, &resource)
+}
+
+func (a *App) DeleteResource(id string) error {
+	svc, release := a.useService()
+	defer release()
+	return svc.DeleteResource(a.ctx, id)
+}
+
+// ============================================================================
+// Activity & Settings Operations
+// ============================================================================
+
+// GetStreak returns the current and longest streak for a goal, or across all
+// goals when goalID is empty.
+func (a *App) GetStreak(goalID string) (*models.Streak, error) {
+	svc, release := a.useService()
+	defer release()
+	return svc.GetStreak(a.ctx, goalID)
+}
+
+// GetActivityCalendar returns daily activity for a heatmap, one entry per day
+// between from and to (YYYY-MM-DD; empty for the past year).
+func (a *App) GetActivityCalendar(goalID string, from string, to string) ([]*models.DailyActivity, error) {
+	svc, release := a.useService()
+	defer release()
+	return svc.GetActivityCalendar(a.ctx, goalID, from, to)
+}
+
+func (a *App) GetUserSettings() (*models.UserSettings, error) {
+	svc, release := a.useService()
+	defer release()
+	return svc.GetUserSettings(a.ctx)
+}
+
+func (a *App) SaveUserSettings(settings models.UserSettings) error {
+	svc, release := a.useService()
+	defer release()
+
+	if err := svc.SaveUserSettings(a.ctx, &settings); err != nil {
+		return fmt.Errorf("failed to save settings: %w", err)
+	}
+	return nil
+}
+
+func (a *App) GetAvailability() (*models.Availability, error) {
+	svc, release := a.useService()
+	defer release()
+	return svc.GetAvailability(a.ctx)
+}
+
+func (a *App) SaveAvailability(availability models.Availability) error {
+	svc, release := a.useService()
+	defer release()
+
+	if err := svc.SaveAvailability(a.ctx, &availability); err != nil {
+		return fmt.Errorf("failed to save availability: %w", err)
+	}
+	return nil
 }
 
 // ============================================================================
@@ -117,10 +418,19 @@ type ChatRequest struct {
 type ChatResponse struct {
 	Content   string `json:"content"`
 	AgentType string `json:"agentType"`
+	TurnID    string `json:"turnId"`
 }
 
+// Chat streams the agent's reply to the frontend as "chat:<type>" events
+// (chat:agent_routed, chat:chunk, chat:tool_call_start, chat:tool_call_finish)
+// and returns the complete response once the turn is saved.
 func (a *App) Chat(req ChatRequest) (*ChatResponse, error) {
-	output, err := a.service.Chat(a.ctx, req.Message, req.GoalID)
+	svc, release := a.useService()
+	defer release()
+
+	output, err := svc.Chat(a.ctx, req.Message, req.GoalID, func(event agent.Event) {
+		runtime.EventsEmit(a.ctx, "chat:"+string(event.Type), event)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -128,16 +438,28 @@ func (a *App) Chat(req ChatRequest) (*ChatResponse, error) {
 	response := &ChatResponse{
 		Content:   output.Response,
 		AgentType: string(output.AgentType),
+		TurnID:    output.TurnID,
 	}
 
 	return response, nil
 }
 
 func (a *App) GetConversationHistory(coachID string, limit int) ([]*models.Conversation, error) {
+	svc, release := a.useService()
+	defer release()
+
 	if limit <= 0 {
 		limit = 50
 	}
-	return a.service.GetConversationHistory(a.ctx, coachID, limit)
+	return svc.GetConversationHistory(a.ctx, coachID, limit)
+}
+
+// GetTurnTrace returns every assistant message, tool call and tool result of
+// a turn, in order. turnID is the ID of the turn's assistant message.
+func (a *App) GetTurnTrace(turnID string) ([]*models.TurnStep, error) {
+	svc, release := a.useService()
+	defer release()
+	return svc.GetTurnTrace(a.ctx, turnID)
 }
 
 // ============================================================================
@@ -145,30 +467,164 @@ func (a *App) GetConversationHistory(coachID string, limit int) ([]*models.Conve
 // ============================================================================
 
 func (a *App) GetLLMProviders() ([]llm.ProviderType, error) {
-	return a.llmRouter.GetAvailableProviders(a.ctx)
+	router, release := a.useRouter()
+	defer release()
+	return router.GetAvailableProviders(a.ctx)
 }
 
 func (a *App) GetLLMProviderConfigs() ([]*models.LLMProviderConfig, error) {
-	return a.llmRouter.GetProviderConfigs(a.ctx)
+	router, release := a.useRouter()
+	defer release()
+	return router.GetProviderConfigs(a.ctx)
 }
 
 func (a *App) SaveLLMProviderConfig(config models.LLMProviderConfig) (*models.LLMProviderConfig, error) {
-	if err := a.llmRouter.SaveProviderConfig(a.ctx, &config); err != nil {
+	router, release := a.useRouter()
+	defer release()
+
+	if err := router.SaveProviderConfig(a.ctx, &config); err != nil {
 		return nil, fmt.Errorf("failed to save provider config: %w", err)
 	}
 	return &config, nil
 }
 
 func (a *App) UpdateLLMProviderConfig(config models.LLMProviderConfig) error {
-	if err := a.llmRouter.UpdateProviderConfig(a.ctx, &config); err != nil {
+	router, release := a.useRouter()
+	defer release()
+
+	if err := router.UpdateProviderConfig(a.ctx, &config); err != nil {
 		return fmt.Errorf("failed to update provider config: %w", err)
 	}
 	return nil
 }
 
 func (a *App) DeleteLLMProviderConfig(id int) error {
-	if err := a.llmRouter.DeleteProviderConfig(a.ctx, id); err != nil {
+	router, release := a.useRouter()
+	defer release()
+
+	if err := router.DeleteProviderConfig(a.ctx, id); err != nil {
 		return fmt.Errorf("failed to delete provider config: %w", err)
 	}
 	return nil
 }
+
+func (a *App) GetAgentModelConfigs() ([]*models.AgentModelConfig, error) {
+	router, release := a.useRouter()
+	defer release()
+	return router.GetAgentModelConfigs(a.ctx)
+}
+
+func (a *App) SaveAgentModelConfig(config models.AgentModelConfig) (*models.AgentModelConfig, error) {
+	router, release := a.useRouter()
+	defer release()
+
+	if err := router.SaveAgentModelConfig(a.ctx, &config); err != nil {
+		return nil, fmt.Errorf("failed to save agent model config: %w", err)
+	}
+	return &config, nil
+}
+
+func (a *App) UpdateAgentModelConfig(config models.AgentModelConfig) error {
+	router, release := a.useRouter()
+	defer release()
+
+	if err := router.UpdateAgentModelConfig(a.ctx, &config); err != nil {
+		return fmt.Errorf("failed to update agent model config: %w", err)
+	}
+	return nil
+}
+
+func (a *App) DeleteAgentModelConfig(id int) error {
+	router, release := a.useRouter()
+	defer release()
+
+	if err := router.DeleteAgentModelConfig(a.ctx, id); err != nil {
+		return fmt.Errorf("failed to delete agent model config: %w", err)
+	}
+	return nil
+}
+
+// ============================================================================
+// Usage & Cost Operations
+// ============================================================================
+
+func (a *App) GetUsageSummary(query models.UsageQuery) ([]*models.UsageAggregate, error) {
+	svc, release := a.useService()
+	defer release()
+	return svc.GetUsageSummary(a.ctx, query)
+}
+
+func (a *App) GetMonthToDateCost() (float64, error) {
+	svc, release := a.useService()
+	defer release()
+	return svc.GetMonthToDateCost(a.ctx)
+}
+
+func (a *App) GetModelPrices() ([]*models.ModelPrice, error) {
+	svc, release := a.useService()
+	defer release()
+	return svc.GetModelPrices(a.ctx)
+}
+
+func (a *App) SaveModelPrice(price models.ModelPrice) error {
+	svc, release := a.useService()
+	defer release()
+
+	if err := svc.SaveModelPrice(a.ctx, &price); err != nil {
+		return fmt.Errorf("failed to save model price: %w", err)
+	}
+	return nil
+}
+
+func (a *App) DeleteModelPrice(id int) error {
+	svc, release := a.useService()
+	defer release()
+	return svc.DeleteModelPrice(a.ctx, id)
+}
+
+func (a *App) GetUsageBudget() (*models.UsageBudget, error) {
+	svc, release := a.useService()
+	defer release()
+	return svc.GetUsageBudget(a.ctx)
+}
+
+func (a *App) SaveUsageBudget(budget models.UsageBudget) error {
+	svc, release := a.useService()
+	defer release()
+
+	if err := svc.SaveUsageBudget(a.ctx, &budget); err != nil {
+		return fmt.Errorf("failed to save usage budget: %w", err)
+	}
+	return nil
+}
+
+// ============================================================================
+// Data Location & Profile Operations
+// ============================================================================
+
+// GetDataLocation returns the data directory, profile and database file in use.
+func (a *App) GetDataLocation() *storage.Location {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.profile.location
+}
+
+func (a *App) ListProfiles() ([]string, error) {
+	return storage.ListProfiles(a.GetDataLocation().DataDir)
+}
+
+// SwitchProfile opens the named profile's database, creating it if it does
+// not exist, and remembers it as the active profile for future launches.
+func (a *App) SwitchProfile(profile string) (*storage.Location, error) {
+	location, err := storage.NewLocation(a.GetDataLocation().DataDir, profile)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.open(location); err != nil {
+		return nil, fmt.Errorf("failed to open profile %s: %w", profile, err)
+	}
+	if err := storage.SaveProfile(profile); err != nil {
+		runtime.LogWarningf(a.ctx, "Failed to save active profile: %v", err)
+	}
+	return location, nil
+}
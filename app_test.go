@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"agent-coach/internal/storage"
+)
+
+func TestSwitchingProfilesClosesDatabaseAfterLastCall(t *testing.T) {
+	open := func() *storage.DB {
+		t.Helper()
+		db, err := storage.Open(":memory:")
+		if err != nil {
+			t.Fatalf("open db: %v", err)
+		}
+		return db
+	}
+	first, second := open(), open()
+	t.Cleanup(func() { second.Close() })
+
+	a := &App{profile: &openProfile{db: first}}
+	inFlight := a.useProfile()
+
+	a.swap(&openProfile{db: second})
+
+	if err := first.Ping(); err != nil {
+		t.Fatalf("database closed while a call was using it: %v", err)
+	}
+	if a.useProfile().db != second {
+		t.Error("new calls still get the replaced profile")
+	}
+
+	inFlight.release()
+	if err := first.Ping(); err == nil {
+		t.Error("replaced database still open after its last call returned")
+	}
+}
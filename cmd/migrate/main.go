@@ -0,0 +1,95 @@
+// Command migrate is a maintenance tool for the app database. The app applies
+// pending migrations on startup; this command is for inspecting the schema
+// version and rolling migrations back.
+//
+//	go run ./cmd/migrate [-db path] status
+//	go run ./cmd/migrate [-db path] up
+//	go run ./cmd/migrate [-db path] down [steps]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"agent-coach/internal/storage"
+)
+
+func main() {
+	dbPath := flag.String("db", "", "path to the SQLite database (default: the active profile's database)")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: migrate [-db path] status | up | down [steps]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *dbPath == "" {
+		location, err := storage.ResolveLocation()
+		if err != nil {
+			log.Fatalf("resolve database location: %v", err)
+		}
+		*dbPath = location.Path
+	}
+	if _, err := os.Stat(*dbPath); err != nil {
+		log.Fatalf("database %s: %v", *dbPath, err)
+	}
+	db, err := storage.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("open %s: %v", *dbPath, err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	switch flag.Arg(0) {
+	case "status":
+		status, err := db.MigrationStatus(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, m := range status {
+			state := "pending"
+			if m.Applied {
+				state = "applied"
+			}
+			fmt.Printf("%-8s %s\n", state, m.Name)
+		}
+
+	case "up":
+		applied, err := db.Migrate(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("applied %d migration(s)\n", applied)
+
+	case "down":
+		steps := 1
+		if flag.NArg() > 1 {
+			steps, err = strconv.Atoi(flag.Arg(1))
+			if err != nil || steps < 1 {
+				log.Fatalf("invalid steps %q", flag.Arg(1))
+			}
+		}
+		rolledBack, err := db.MigrateDown(ctx, steps)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, name := range rolledBack {
+			fmt.Printf("rolled back %s\n", name)
+		}
+		if len(rolledBack) == 0 {
+			fmt.Println("no migrations to roll back")
+		}
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,168 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"agent-coach/internal/llm"
+	"agent-coach/internal/models"
+	"agent-coach/internal/tool"
+)
+
+type AccountabilityAgent struct {
+	*BaseAgent
+}
+
+func NewAccountabilityAgent(router *llm.Router, executor *tool.ToolExecutor) *AccountabilityAgent {
+	tools := []string{
+		"send_checkin",
+		"record_checkin",
+		"adjust_pace",
+		"celebrate_win",
+	}
+
+	return &AccountabilityAgent{
+		BaseAgent: NewBaseAgent(models.AgentTypeAccountability, router, executor, tools),
+	}
+}
+
+func (a *AccountabilityAgent) SystemPrompt(ctx *models.AgentContext) string {
+	basePrompt := `You are an Accountability Coach in a multi-agent coaching system. Your role is to run short, supportive check-ins that keep users consistent with their plan.
+
+## Your Role
+You are the Accountability Agent, one of several specialized agents working together to support users. You focus specifically on:
+1. Checking in on how the user is doing since the last conversation
+2. Recording what went well, what was hard, and how they feel
+3. Noticing missed tasks and adjusting the pace of the plan when needed
+4. Celebrating wins, however small
+5. Helping the user commit to a realistic next step for today
+
+You do NOT handle:
+- Designing or restructuring plans, milestones, or tasks (Planning Agent)
+- Walking the user through a task step by step (Execution Agent)
+- Detailed performance reviews and statistics (Evaluation Agent)
+
+Stay focused on consistency, honesty, and momentum.
+
+## Check-in Principles
+- Keep check-ins short: a couple of questions, not an interrogation
+- Be warm and non-judgmental, especially about missed tasks
+- Ask about energy and mood as well as progress
+- Slow the pace when the user is overwhelmed or repeatedly missing tasks; speed up only when they ask or are clearly ahead
+- Celebrate specific achievements rather than giving generic praise
+`
+
+	basePrompt += a.BuildContextPrompt(ctx)
+	basePrompt += a.buildCheckInPrompt(ctx)
+
+	basePrompt += `
+
+## How to Think and Respond
+1. If the user has not yet told you how things are going, open the check-in:
+   - Greet them briefly and ask one or two focused questions (progress since last time, mood/energy, blockers).
+2. Once they have answered:
+   - Record the check-in with a summary, wins, struggles and a mood rating if they gave one.
+   - If tasks were missed or they feel overwhelmed, consider slowing the pace; explain what changes.
+   - Celebrate any completed tasks or wins they mention.
+3. Close by agreeing on the single most important thing to do today.
+
+## Tool Usage
+You are using a model that can call tools directly. You have access to these tools:
+
+- ToolSendCheckIn: open a check-in with a message and the questions you want answered.
+- ToolRecordCheckIn: save the check-in once the user has responded (summary, wins, struggles, mood 1-5).
+- ToolAdjustPace: reschedule pending tasks "slower", "faster" or "maintain", with the reason.
+- ToolCelebrateWin: acknowledge a specific achievement.
+
+Prefer using tools to actually record check-ins and adjust the plan instead of only describing them in text. After your tool calls for this turn:
+- Make sure your response matches what you recorded or changed (mention any rescheduled tasks).
+- Keep it brief and encouraging.
+
+## Multi-Agent System Context
+You are part of a multi-agent system where:
+- The Planning Agent designs goals, milestones, and tasks.
+- The Execution Agent helps users work through tasks.
+- The Evaluation Agent reviews progress and performance.
+- The Accountability Agent (you) runs check-ins and keeps the pace realistic.
+
+Focus on helping the user show up consistently, without guilt.`
+
+	return basePrompt
+}
+
+func (a *AccountabilityAgent) buildCheckInPrompt(ctx *models.AgentContext) string {
+	var sb strings.Builder
+
+	if ctx.LastCheckIn != nil {
+		sb.WriteString(fmt.Sprintf("\n**Last Check-in** (%s): %s\n", ctx.LastCheckIn.CreatedAt.Format("2006-01-02"), ctx.LastCheckIn.Summary))
+		if ctx.LastCheckIn.MoodRating != nil {
+			sb.WriteString(fmt.Sprintf("- Mood: %d/5\n", *ctx.LastCheckIn.MoodRating))
+		}
+		if len(ctx.LastCheckIn.Struggles) > 0 {
+			sb.WriteString(fmt.Sprintf("- Struggles: %s\n", strings.Join(ctx.LastCheckIn.Struggles, "; ")))
+		}
+	}
+
+	return sb.String()
+}
+
+func (a *AccountabilityAgent) Execute(ctx context.Context, input *AgentInput) (*AgentOutput, error) {
+	systemPrompt := a.SystemPrompt(input.Context)
+	return a.ExecuteWithLLM(ctx, systemPrompt, input)
+}
+
+// checkInReason decides, without the LLM classifier, whether this message
+// should start a check-in. A check-in is due once per day for a goal with
+// tasks, on the first message of the day or when tasks have been missed,
+// unless one was already recorded or started today.
+func checkInReason(agentCtx *models.AgentContext, now time.Time) string {
+	if agentCtx.Goal == nil || len(agentCtx.Tasks) == 0 {
+		return ""
+	}
+	if agentCtx.LastCheckIn != nil && sameDay(agentCtx.LastCheckIn.CreatedAt, now) {
+		return ""
+	}
+
+	firstMessageToday := true
+	for _, conv := range agentCtx.Conversations {
+		if !sameDay(conv.CreatedAt, now) {
+			continue
+		}
+		if conv.AgentType == models.AgentTypeAccountability {
+			return ""
+		}
+		firstMessageToday = false
+	}
+
+	if firstMessageToday {
+		return "Daily check-in: first message today"
+	}
+	if missed := missedTasks(agentCtx.Tasks, now); len(missed) > 0 {
+		return fmt.Sprintf("Check-in: %d missed task(s)", len(missed))
+	}
+	return ""
+}
+
+// missedTasks returns unfinished tasks due on a day before today, with days
+// taken in now's location.
+func missedTasks(tasks []*models.Task, now time.Time) []*models.Task {
+	today := models.CalendarDate(now)
+	var missed []*models.Task
+	for _, task := range tasks {
+		if task.Status != models.TaskStatusPending && task.Status != models.TaskStatusInProgress {
+			continue
+		}
+		due, ok := task.DueDay(now.Location())
+		if ok && due.Before(today) {
+			missed = append(missed, task)
+		}
+	}
+	return missed
+}
+
+func sameDay(a, b time.Time) bool {
+	a = a.In(b.Location())
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
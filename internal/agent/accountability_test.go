@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"agent-coach/internal/models"
+)
+
+func TestCheckInReason(t *testing.T) {
+	now := time.Date(2026, 1, 20, 9, 0, 0, 0, time.Local)
+	yesterday := now.AddDate(0, 0, -1)
+	twoDaysAgo := time.Date(2026, 1, 18, 0, 0, 0, 0, time.UTC)
+
+	goal := &models.Goal{ID: "goal-1"}
+	pending := []*models.Task{{Status: models.TaskStatusPending}}
+	missed := []*models.Task{{Status: models.TaskStatusPending, DueDate: &twoDaysAgo}}
+
+	tests := []struct {
+		name string
+		ctx  *models.AgentContext
+		want bool
+	}{
+		{
+			name: "no goal",
+			ctx:  &models.AgentContext{},
+		},
+		{
+			name: "goal without tasks",
+			ctx:  &models.AgentContext{Goal: goal},
+		},
+		{
+			name: "first message today",
+			ctx: &models.AgentContext{Goal: goal, Tasks: pending, Conversations: []*models.Conversation{
+				{Role: models.RoleUser, CreatedAt: yesterday},
+			}},
+			want: true,
+		},
+		{
+			name: "already chatting today",
+			ctx: &models.AgentContext{Goal: goal, Tasks: pending, Conversations: []*models.Conversation{
+				{Role: models.RoleUser, CreatedAt: now.Add(-time.Hour)},
+			}},
+		},
+		{
+			name: "missed tasks while chatting today",
+			ctx: &models.AgentContext{Goal: goal, Tasks: missed, Conversations: []*models.Conversation{
+				{Role: models.RoleUser, CreatedAt: now.Add(-time.Hour)},
+			}},
+			want: true,
+		},
+		{
+			name: "check-in already started today",
+			ctx: &models.AgentContext{Goal: goal, Tasks: missed, Conversations: []*models.Conversation{
+				{Role: models.RoleAssistant, AgentType: models.AgentTypeAccountability, CreatedAt: now.Add(-time.Hour)},
+			}},
+		},
+		{
+			name: "check-in recorded today",
+			ctx:  &models.AgentContext{Goal: goal, Tasks: missed, LastCheckIn: &models.CheckIn{CreatedAt: now.Add(-time.Hour)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := checkInReason(tt.ctx, now)
+			if (reason != "") != tt.want {
+				t.Errorf("checkInReason = %q, want check-in: %v", reason, tt.want)
+			}
+		})
+	}
+}
@@ -3,30 +3,82 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"agent-coach/internal/llm"
 	"agent-coach/internal/models"
 	"agent-coach/internal/tool"
 )
 
+// LoopPolicy bounds the tool-calling loop of a single turn. A zero field
+// disables that limit. When a limit stops the loop while the model still
+// wants to call tools, one more tool-less request asks it to summarize what it
+// did, so the user always gets a response.
+type LoopPolicy struct {
+	MaxIterations  int
+	MaxTotalTokens int
+	Timeout        time.Duration
+}
+
+var DefaultLoopPolicy = LoopPolicy{
+	MaxIterations:  3,
+	MaxTotalTokens: 0,
+	Timeout:        2 * time.Minute,
+}
+
+const summarizePrompt = "You have reached the limit for this turn and cannot call any more tools. " +
+	"Briefly tell the user what you did, using the tool results above, and what is still left to do."
+
 type BaseAgent struct {
-	agentType     models.AgentType
-	llmRouter     *llm.Router
-	toolExecutor  *tool.ToolExecutor
-	tools         []models.Tool
-	maxIterations int
+	agentType    models.AgentType
+	llmRouter    *llm.Router
+	toolExecutor *tool.ToolExecutor
+	tools        []string
+	policy       LoopPolicy
 }
 
-func NewBaseAgent(agentType models.AgentType, router *llm.Router, executor *tool.ToolExecutor, tools []models.Tool) *BaseAgent {
+// NewBaseAgent creates an agent that may call the named tools, which must be
+// registered with executor.
+func NewBaseAgent(agentType models.AgentType, router *llm.Router, executor *tool.ToolExecutor, tools []string) *BaseAgent {
 	return &BaseAgent{
-		agentType:     agentType,
-		llmRouter:     router,
-		toolExecutor:  executor,
-		tools:         tools,
-		maxIterations: 3,
+		agentType:    agentType,
+		llmRouter:    router,
+		toolExecutor: executor,
+		tools:        tools,
+		policy:       DefaultLoopPolicy,
+	}
+}
+
+// SetLoopPolicy replaces the agent's default loop policy. Limits configured
+// for the agent in its AgentModelConfig still take precedence.
+func (a *BaseAgent) SetLoopPolicy(policy LoopPolicy) {
+	a.policy = policy
+}
+
+// loopPolicy returns the policy for the next turn, applying the limits set in
+// the agent's model configuration.
+func (a *BaseAgent) loopPolicy() LoopPolicy {
+	policy := a.policy
+	config := a.llmRouter.AgentModelConfig(a.agentType)
+	if config == nil {
+		return policy
+	}
+	if config.MaxIterations != nil {
+		policy.MaxIterations = *config.MaxIterations
 	}
+	if config.MaxTotalTokens != nil {
+		policy.MaxTotalTokens = *config.MaxTotalTokens
+	}
+	if config.TimeoutSeconds != nil {
+		policy.Timeout = time.Duration(*config.TimeoutSeconds) * time.Second
+	}
+	return policy
 }
 
 func (a *BaseAgent) Type() models.AgentType {
@@ -34,7 +86,11 @@ func (a *BaseAgent) Type() models.AgentType {
 }
 
 func (a *BaseAgent) AvailableTools() []models.Tool {
-	return a.tools
+	tools, err := a.toolExecutor.Definitions(a.tools...)
+	if err != nil {
+		log.Printf("[%s] %v", a.agentType, err)
+	}
+	return tools
 }
 
 func (a *BaseAgent) BuildContextPrompt(ctx *models.AgentContext) string {
@@ -42,21 +98,39 @@ func (a *BaseAgent) BuildContextPrompt(ctx *models.AgentContext) string {
 
 	sb.WriteString("\n\n## Current Context\n\n")
 
+	now := ctx.Now()
+	sb.WriteString(fmt.Sprintf("**Today**: %s (%s, time zone %s)\n\n", now.Format("2006-01-02"), now.Weekday(), now.Location()))
+
 	if ctx.Goal != nil {
 		sb.WriteString(fmt.Sprintf("**Current Goal**: %s\n", ctx.Goal.Title))
 		if ctx.Goal.Description != "" {
 			sb.WriteString(fmt.Sprintf("Description: %s\n", ctx.Goal.Description))
 		}
 		sb.WriteString(fmt.Sprintf("Status: %s\n", ctx.Goal.Status))
-		if ctx.Goal.Context != nil {
+		if len(ctx.Goal.Context) > 0 {
 			sb.WriteString("\nUser's onboarding answers:\n")
-			for k, v := range ctx.Goal.Context {
-				sb.WriteString(fmt.Sprintf("- %s: %v\n", k, v))
+			keys := make([]string, 0, len(ctx.Goal.Context))
+			for k := range ctx.Goal.Context {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				sb.WriteString(fmt.Sprintf("- %s: %v\n", k, ctx.Goal.Context[k]))
 			}
 		}
 		sb.WriteString("\n")
 	}
 
+	if len(ctx.Milestones) > 0 {
+		sb.WriteString("**Milestones**:\n")
+		for _, milestone := range ctx.Milestones {
+			sb.WriteString(fmt.Sprintf("- [%s] Weeks %d-%d: %s (%d/%d tasks done, id: %s)\n",
+				milestone.Status, milestone.WeekStart, milestone.WeekEnd, milestone.Title,
+				milestone.CompletedTasks, milestone.TotalTasks, milestone.ID))
+		}
+		sb.WriteString("\n")
+	}
+
 	if len(ctx.TodaysTasks) > 0 {
 		sb.WriteString("**Today's Tasks**:\n")
 		for _, task := range ctx.TodaysTasks {
@@ -70,17 +144,40 @@ func (a *BaseAgent) BuildContextPrompt(ctx *models.AgentContext) string {
 			if task.EstimatedMinutes != nil {
 				sb.WriteString(fmt.Sprintf(" (~%d min)", *task.EstimatedMinutes))
 			}
+			if task.DueKind == models.DueKindDateTime {
+				sb.WriteString(fmt.Sprintf(" due at %s", task.DueDate.In(ctx.TimeZone()).Format("15:04")))
+			}
 			sb.WriteString("\n")
 		}
 		sb.WriteString("\n")
 	}
 
+	if len(ctx.OverdueTasks) > 0 {
+		sb.WriteString("**Overdue Tasks** (past due, not completed):\n")
+		for _, task := range ctx.OverdueTasks {
+			sb.WriteString(fmt.Sprintf("- %s (due %s, id: %s)\n", task.Title, task.FormatDue(ctx.TimeZone()), task.ID))
+		}
+		sb.WriteString("\n")
+	}
+
 	if len(ctx.Tasks) > 0 && len(ctx.TodaysTasks) == 0 {
+		titles := make(map[string]string, len(ctx.Tasks))
+		for _, task := range ctx.Tasks {
+			titles[task.ID] = task.Title
+		}
 		sb.WriteString("**Pending Tasks**:\n")
 		count := 0
 		for _, task := range ctx.Tasks {
 			if task.Status == "pending" || task.Status == "in_progress" {
-				sb.WriteString(fmt.Sprintf("- %s\n", task.Title))
+				sb.WriteString(fmt.Sprintf("- %s (id: %s)", task.Title, task.ID))
+				if len(task.BlockedBy) > 0 {
+					waitingOn := make([]string, len(task.BlockedBy))
+					for i, id := range task.BlockedBy {
+						waitingOn[i] = titles[id]
+					}
+					sb.WriteString(fmt.Sprintf(" [blocked, waiting on: %s]", strings.Join(waitingOn, "; ")))
+				}
+				sb.WriteString("\n")
 				count++
 				if count >= 5 {
 					sb.WriteString(fmt.Sprintf("... and %d more\n", len(ctx.Tasks)-5))
@@ -94,7 +191,7 @@ func (a *BaseAgent) BuildContextPrompt(ctx *models.AgentContext) string {
 	// Stats
 	sb.WriteString("**Stats**:\n")
 	sb.WriteString(fmt.Sprintf("- Tasks completed: %d\n", ctx.TasksCompleted))
-	sb.WriteString(fmt.Sprintf("- Current streak: %d days\n", ctx.StreakDays))
+	sb.WriteString(fmt.Sprintf("- Current streak: %d days (longest: %d)\n", ctx.StreakDays, ctx.LongestStreak))
 	if ctx.RecentStruggles > 0 {
 		sb.WriteString(fmt.Sprintf("- Recent struggles: %d\n", ctx.RecentStruggles))
 	}
@@ -116,36 +213,88 @@ func (a *BaseAgent) ExecuteWithLLM(ctx context.Context, systemPrompt string, inp
 		Content: input.Message,
 	})
 
-	var finalResponse *llm.CompletionResponse
+	stateChanged := false
+	var trace []*models.TurnStep
+	addStep := func(step *models.TurnStep) {
+		step.AgentType = a.agentType
+		step.Seq = len(trace)
+		step.CreatedAt = time.Now()
+		trace = append(trace, step)
+	}
+	out := &transcript{emit: func(content string) {
+		input.emit(Event{Type: EventChunk, AgentType: a.agentType, Content: content})
+	}}
+
+	tools, err := a.toolExecutor.Definitions(a.tools...)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := a.loopPolicy()
+	loopCtx := ctx
+	if policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		loopCtx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		defer cancel()
+	}
 
-	for iteration := 0; iteration < a.maxIterations; iteration++ {
-		resp, err := a.llmRouter.Complete(ctx, &llm.CompletionRequest{
+	var limit string
+	totalTokens := 0
+	answered := false
+	for iteration := 0; ; iteration++ {
+		if policy.MaxIterations > 0 && iteration >= policy.MaxIterations {
+			limit = fmt.Sprintf("%d iterations", policy.MaxIterations)
+			break
+		}
+		if policy.MaxTotalTokens > 0 && totalTokens >= policy.MaxTotalTokens {
+			limit = fmt.Sprintf("%d tokens", policy.MaxTotalTokens)
+			break
+		}
+
+		out.beginTurn()
+		resp, err := a.complete(loopCtx, &llm.CompletionRequest{
 			SystemPrompt: systemPrompt,
 			Messages:     messages,
-			Tools:        a.tools,
-		})
+			Tools:        tools,
+			AgentType:    a.agentType,
+			GoalID:       input.GoalID,
+			Intent:       string(input.Intent),
+		}, input, out)
 		if err != nil {
+			if errors.Is(loopCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+				limit = fmt.Sprintf("%s timeout", policy.Timeout)
+				break
+			}
 			return nil, fmt.Errorf("LLM completion failed: %w", err)
 		}
-
-		finalResponse = resp
+		totalTokens += resp.Usage.TotalTokens
+		addStep(&models.TurnStep{Role: models.RoleAssistant, Content: resp.Content, ToolCalls: resp.ToolCalls})
 
 		if len(resp.ToolCalls) == 0 {
+			answered = true
 			break
 		}
 
-		assistantContent := resp.Content
 		messages = append(messages, llm.Message{
-			Role:    llm.RoleAssistant,
-			Content: assistantContent,
+			Role:      llm.RoleAssistant,
+			Content:   resp.Content,
+			ToolCalls: resp.ToolCalls,
 		})
 
 		for _, tc := range resp.ToolCalls {
-			result, err := a.toolExecutor.ExecuteTool(ctx, tc.Function.Name, tc.Function.Arguments, input.Context)
+			input.emit(Event{Type: EventToolCallStart, AgentType: a.agentType, ToolCall: &tc})
+
+			result, err := a.runTool(ctx, tc, input.Context)
 			var resultContent string
+			finished := Event{Type: EventToolCallFinish, AgentType: a.agentType, ToolCall: &tc}
 			if err != nil {
-				resultContent = fmt.Sprintf(`{"error": "%s"}`, err.Error())
+				errorJSON, _ := json.Marshal(tool.ErrorResult(err))
+				resultContent = string(errorJSON)
+				finished.Error = err.Error()
 			} else {
+				if a.toolExecutor.Mutates(tc.Function.Name) {
+					stateChanged = true
+				}
 				resultJSON, jsonErr := json.Marshal(result)
 				if jsonErr == nil {
 					resultContent = string(resultJSON)
@@ -153,6 +302,15 @@ func (a *BaseAgent) ExecuteWithLLM(ctx context.Context, systemPrompt string, inp
 					resultContent = fmt.Sprintf(`{"status": "success", "result": %v}`, result)
 				}
 			}
+			finished.Result = resultContent
+			input.emit(finished)
+			addStep(&models.TurnStep{
+				Role:       models.RoleTool,
+				Content:    resultContent,
+				ToolCallID: tc.ID,
+				ToolName:   tc.Function.Name,
+				Error:      finished.Error,
+			})
 
 			messages = append(messages, llm.Message{
 				Role:       llm.RoleTool,
@@ -162,10 +320,128 @@ func (a *BaseAgent) ExecuteWithLLM(ctx context.Context, systemPrompt string, inp
 		}
 	}
 
+	if !answered {
+		log.Printf("[%s] Loop limit reached (%s), summarizing", a.agentType, limit)
+		out.beginTurn()
+		resp, err := a.complete(ctx, &llm.CompletionRequest{
+			SystemPrompt: systemPrompt,
+			Messages:     append(messages, llm.Message{Role: llm.RoleUser, Content: summarizePrompt}),
+			AgentType:    a.agentType,
+			GoalID:       input.GoalID,
+			Intent:       string(input.Intent),
+		}, input, out)
+		if err == nil {
+			addStep(&models.TurnStep{Role: models.RoleAssistant, Content: resp.Content})
+		} else {
+			log.Printf("[%s] Summary turn failed: %v", a.agentType, err)
+		}
+		if strings.TrimSpace(out.String()) == "" {
+			out.write(fallbackSummary(trace))
+		}
+	}
+
 	output := &AgentOutput{
-		Response:  finalResponse.Content,
-		AgentType: a.agentType,
+		Response:     out.String(),
+		AgentType:    a.agentType,
+		StateChanged: stateChanged,
+		Trace:        trace,
 	}
 
 	return output, nil
 }
+
+// runTool executes a tool call, refusing tools that were not offered to this
+// agent even if they exist in the registry.
+func (a *BaseAgent) runTool(ctx context.Context, tc models.ToolCall, agentCtx *models.AgentContext) (map[string]any, error) {
+	if !slices.Contains(a.tools, tc.Function.Name) {
+		return nil, fmt.Errorf("%w: %s is not available to the %s agent", tool.ErrUnknownTool, tc.Function.Name, a.agentType)
+	}
+	if tc.Function.ArgumentsError != "" {
+		return nil, &tool.ValidationError{
+			Tool:   tc.Function.Name,
+			Errors: []tool.FieldError{{Field: "arguments", Message: tc.Function.ArgumentsError}},
+		}
+	}
+	return a.toolExecutor.ExecuteTool(ctx, tc.Function.Name, tc.Function.Arguments, agentCtx)
+}
+
+// fallbackSummary describes the tool calls of a turn when the model could not
+// summarize them itself.
+func fallbackSummary(trace []*models.TurnStep) string {
+	var done, failed []string
+	for _, step := range trace {
+		if step.Role != models.RoleTool {
+			continue
+		}
+		if step.Error != "" {
+			failed = append(failed, step.ToolName)
+		} else {
+			done = append(done, step.ToolName)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("I had to stop before finishing my reply.")
+	if len(done) > 0 {
+		sb.WriteString(fmt.Sprintf(" Completed actions: %s.", strings.Join(done, ", ")))
+	}
+	if len(failed) > 0 {
+		sb.WriteString(fmt.Sprintf(" Failed actions: %s.", strings.Join(failed, ", ")))
+	}
+	sb.WriteString(" Send another message to continue.")
+	return sb.String()
+}
+
+// complete runs a single LLM round-trip, streaming it when the caller listens
+// for events. Either way the assistant text ends up in out.
+func (a *BaseAgent) complete(ctx context.Context, req *llm.CompletionRequest, input *AgentInput, out *transcript) (*llm.CompletionResponse, error) {
+	if input.OnEvent == nil {
+		resp, err := a.llmRouter.Complete(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		out.write(resp.Content)
+		return resp, nil
+	}
+
+	return a.llmRouter.Stream(ctx, req, func(chunk llm.StreamChunk) {
+		if chunk.ToolCall != nil {
+			input.emit(Event{Type: EventChunk, AgentType: a.agentType, Delta: chunk.ToolCall})
+			return
+		}
+		out.write(chunk.Content)
+	})
+}
+
+// transcript accumulates the assistant text produced across loop iterations,
+// forwarding every piece to emit, so the saved response is exactly what was
+// streamed to the UI.
+type transcript struct {
+	sb      strings.Builder
+	emit    func(content string)
+	midTurn bool
+}
+
+func (t *transcript) beginTurn() {
+	t.midTurn = false
+}
+
+func (t *transcript) write(content string) {
+	if content == "" {
+		return
+	}
+	if !t.midTurn && t.sb.Len() > 0 {
+		t.put("\n\n")
+	}
+	t.midTurn = true
+	t.put(content)
+}
+
+func (t *transcript) put(content string) {
+	t.sb.WriteString(content)
+	t.emit(content)
+}
+
+func (t *transcript) String() string {
+	return t.sb.String()
+}
@@ -3,7 +3,9 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"strings"
 
 	"agent-coach/internal/llm"
@@ -24,24 +26,59 @@ func (c *IntentClassifier) Classify(ctx context.Context, message string, agentCt
 	systemPrompt := c.buildSystemPrompt(agentCtx)
 	userMessage := c.buildUserMessage(message, agentCtx)
 
+	var goalID string
+	if agentCtx.Goal != nil {
+		goalID = agentCtx.Goal.ID
+	}
+
 	resp, err := c.llmRouter.Complete(ctx, &llm.CompletionRequest{
 		SystemPrompt: systemPrompt,
 		Messages: []llm.Message{
 			{Role: llm.RoleUser, Content: userMessage},
 		},
+		AgentType: models.AgentTypeClassifier,
+		GoalID:    goalID,
 	})
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if !modelUnavailable(err) {
+			return nil, err
+		}
+		log.Printf("[Classifier] LLM classification failed (%s), using heuristics: %v", llm.ClassifyError(err), err)
 		return c.fallbackClassification(agentCtx), nil
 	}
 
 	result, err := c.parseResponse(resp.Content)
 	if err != nil {
+		log.Printf("[Classifier] Unparseable classification %q, using heuristics: %v", resp.Content, err)
 		return c.fallbackClassification(agentCtx), nil
 	}
 
 	return result, nil
 }
 
+// modelUnavailable reports whether err only means that no model could be
+// reached right now: no provider is configured, or every provider was
+// skipped or failed with an error worth retrying later. The heuristics stand
+// in for the model then; other errors, such as an exhausted budget or a
+// rejected API key, are for the user to see.
+func modelUnavailable(err error) bool {
+	if errors.Is(err, llm.ErrNoProvider) {
+		return true
+	}
+	if !errors.Is(err, llm.ErrAllProvidersFailed) {
+		return false
+	}
+	var providerErr *llm.ProviderError
+	if !errors.As(err, &providerErr) {
+		// Every provider's circuit breaker is open.
+		return true
+	}
+	return providerErr.Kind.Retryable()
+}
+
 func (c *IntentClassifier) buildSystemPrompt(agentCtx *models.AgentContext) string {
 	return `You are an intent classifier for an AI coaching application. Your job is to determine what the user wants to do based on their message and the conversation context.
 
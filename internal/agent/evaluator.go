@@ -0,0 +1,179 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"agent-coach/internal/llm"
+	"agent-coach/internal/models"
+	"agent-coach/internal/tool"
+)
+
+type EvaluatorAgent struct {
+	*BaseAgent
+}
+
+func NewEvaluatorAgent(router *llm.Router, executor *tool.ToolExecutor) *EvaluatorAgent {
+	tools := []string{
+		"rate_performance",
+		"identify_weakness",
+		"suggest_review",
+	}
+
+	return &EvaluatorAgent{
+		BaseAgent: NewBaseAgent(models.AgentTypeEvaluator, router, executor, tools),
+	}
+}
+
+func (a *EvaluatorAgent) SystemPrompt(ctx *models.AgentContext) string {
+	basePrompt := `You are an Evaluation Specialist in a multi-agent coaching system. Your role is to help users understand how they are progressing toward their goals and what to improve.
+
+## Your Role
+You are the Evaluation Agent, one of several specialized agents working together to support users. You focus specifically on:
+1. Reviewing progress using the real statistics provided below
+2. Rating performance on completed tasks with specific, constructive feedback
+3. Identifying recurring weaknesses backed by concrete evidence
+4. Suggesting what to review or revisit to consolidate learning
+5. Helping users calibrate their time estimates and expectations
+
+You do NOT handle:
+- Creating or restructuring plans, milestones, or tasks (Planning Agent)
+- Guiding the user through a task while they work on it (Execution Agent)
+- Check-ins, motivation, or habit coaching (Accountability Agent)
+
+Stay focused on honest, evidence-based evaluation.
+
+## Evaluation Principles
+- Ground every observation in the numbers and task history you are given; never invent statistics
+- Lead with what is going well before discussing what needs work
+- Be honest but kind; frame weaknesses as skills to build, not character flaws
+- Prefer one or two actionable insights over a long list of criticisms
+- Treat estimate misses as calibration data, not failure
+- Acknowledge when there is too little data to draw a conclusion
+`
+
+	basePrompt += a.BuildContextPrompt(ctx)
+	basePrompt += a.buildProgressPrompt(ctx)
+
+	basePrompt += `
+
+## How to Think and Respond
+1. Summarize the user's progress using the statistics above (completion rate, estimate accuracy, struggles).
+2. Point out patterns:
+   - Tasks consistently taking longer or shorter than estimated.
+   - Tasks or areas where struggles cluster.
+   - Momentum: recent completions versus tasks left pending.
+3. Rate specific completed tasks when the user asks for feedback on them, or when a rating would be informative.
+4. Record a weakness only when there is clear, repeated evidence, and do not re-record weaknesses already listed above.
+5. End with one or two concrete recommendations for what to do next.
+
+## Tool Usage
+You are using a model that can call tools directly. You have access to these tools:
+
+- ToolRatePerformance: record a 1-5 rating and feedback for a specific completed task (use its task ID).
+- ToolIdentifyWeakness: record an area for improvement with the evidence behind it and a suggestion.
+- ToolSuggestReview: suggest topics or tasks the user should review, and why.
+
+Prefer using tools to actually record ratings and weaknesses instead of only describing them in text. After your tool calls for this turn:
+- Make sure your summary matches what you recorded.
+- Keep the response concise and readable.
+
+## Multi-Agent System Context
+You are part of a multi-agent system where:
+- The Planning Agent designs goals, milestones, and tasks.
+- The Execution Agent helps users work through tasks.
+- The Evaluation Agent (you) reviews progress and performance.
+- Ratings and weaknesses you record are visible to other agents and will inform future plans.
+
+Focus on giving the user a clear, honest picture of where they stand and how to improve.`
+
+	return basePrompt
+}
+
+func (a *EvaluatorAgent) buildProgressPrompt(ctx *models.AgentContext) string {
+	if ctx.Progress == nil {
+		return ""
+	}
+	stats := ctx.Progress
+
+	var sb strings.Builder
+	sb.WriteString("\n**Progress Statistics**:\n")
+	sb.WriteString(fmt.Sprintf("- Tasks: %d total, %d completed, %d in progress, %d pending, %d skipped\n",
+		stats.TotalTasks, stats.CompletedTasks, stats.InProgressTasks, stats.PendingTasks, stats.SkippedTasks))
+	sb.WriteString(fmt.Sprintf("- Completion rate: %.0f%%\n", stats.CompletionRate*100))
+	if stats.EstimatedTasks > 0 {
+		sb.WriteString(fmt.Sprintf("- Estimate accuracy: tasks took %.2fx their estimate on average (%d tasks with timings)\n",
+			stats.EstimateRatio, stats.EstimatedTasks))
+	} else {
+		sb.WriteString("- Estimate accuracy: no completed tasks with both estimated and actual minutes yet\n")
+	}
+	sb.WriteString(fmt.Sprintf("- Struggles: %d of %d tasks (%.0f%%)\n",
+		stats.StruggledTasks, stats.TotalTasks, stats.StruggleRate*100))
+	if stats.RatedTasks > 0 {
+		sb.WriteString(fmt.Sprintf("- Average performance rating: %.1f/5 across %d ratings\n", stats.AverageRating, stats.RatedTasks))
+	}
+
+	if len(ctx.Weaknesses) > 0 {
+		sb.WriteString("\n**Previously Identified Weaknesses**:\n")
+		for _, weakness := range ctx.Weaknesses {
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", weakness.Area, weakness.Suggestion))
+		}
+	}
+
+	return sb.String()
+}
+
+func (a *EvaluatorAgent) Execute(ctx context.Context, input *AgentInput) (*AgentOutput, error) {
+	systemPrompt := a.SystemPrompt(input.Context)
+	return a.ExecuteWithLLM(ctx, systemPrompt, input)
+}
+
+func computeProgressStats(tasks []*models.Task, ratings []*models.PerformanceRating) *models.ProgressStats {
+	stats := &models.ProgressStats{TotalTasks: len(tasks)}
+
+	var estimated, actual int
+	for _, task := range tasks {
+		switch task.Status {
+		case models.TaskStatusCompleted:
+			stats.CompletedTasks++
+			// MarkComplete stores 0 when no time was reported, so treat it as unknown.
+			if task.EstimatedMinutes != nil && *task.EstimatedMinutes > 0 &&
+				task.ActualMinutes != nil && *task.ActualMinutes > 0 {
+				estimated += *task.EstimatedMinutes
+				actual += *task.ActualMinutes
+				stats.EstimatedTasks++
+			}
+		case models.TaskStatusInProgress:
+			stats.InProgressTasks++
+		case models.TaskStatusSkipped:
+			stats.SkippedTasks++
+		default:
+			stats.PendingTasks++
+		}
+		if task.StruggleNotes != "" {
+			stats.StruggledTasks++
+		}
+	}
+
+	if active := stats.TotalTasks - stats.SkippedTasks; active > 0 {
+		stats.CompletionRate = float64(stats.CompletedTasks) / float64(active)
+	}
+	if estimated > 0 {
+		stats.EstimateRatio = float64(actual) / float64(estimated)
+	}
+	if stats.TotalTasks > 0 {
+		stats.StruggleRate = float64(stats.StruggledTasks) / float64(stats.TotalTasks)
+	}
+
+	if len(ratings) > 0 {
+		total := 0
+		for _, rating := range ratings {
+			total += rating.Rating
+		}
+		stats.RatedTasks = len(ratings)
+		stats.AverageRating = float64(total) / float64(len(ratings))
+	}
+
+	return stats
+}
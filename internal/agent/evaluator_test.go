@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"testing"
+
+	"agent-coach/internal/models"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestComputeProgressStats(t *testing.T) {
+	tasks := []*models.Task{
+		{Status: models.TaskStatusCompleted, EstimatedMinutes: intPtr(30), ActualMinutes: intPtr(45)},
+		{Status: models.TaskStatusCompleted, EstimatedMinutes: intPtr(30), ActualMinutes: intPtr(15), StruggleNotes: "recursion"},
+		// Completed without a reported time: excluded from estimate accuracy.
+		{Status: models.TaskStatusCompleted, EstimatedMinutes: intPtr(20), ActualMinutes: intPtr(0)},
+		{Status: models.TaskStatusInProgress, StruggleNotes: "stuck on generics"},
+		{Status: models.TaskStatusPending},
+		{Status: models.TaskStatusSkipped},
+	}
+	ratings := []*models.PerformanceRating{{Rating: 4}, {Rating: 3}}
+
+	stats := computeProgressStats(tasks, ratings)
+
+	if stats.TotalTasks != 6 || stats.CompletedTasks != 3 || stats.InProgressTasks != 1 ||
+		stats.PendingTasks != 1 || stats.SkippedTasks != 1 {
+		t.Errorf("counts = %+v", stats)
+	}
+	if stats.CompletionRate != 0.6 {
+		t.Errorf("completion rate = %v, want 0.6", stats.CompletionRate)
+	}
+	if stats.EstimatedTasks != 2 || stats.EstimateRatio != 1 {
+		t.Errorf("estimate ratio = %v over %d tasks, want 1 over 2", stats.EstimateRatio, stats.EstimatedTasks)
+	}
+	if stats.StruggledTasks != 2 {
+		t.Errorf("struggled tasks = %d, want 2", stats.StruggledTasks)
+	}
+	if stats.AverageRating != 3.5 || stats.RatedTasks != 2 {
+		t.Errorf("average rating = %v over %d, want 3.5 over 2", stats.AverageRating, stats.RatedTasks)
+	}
+}
+
+func TestComputeProgressStatsWithoutTasks(t *testing.T) {
+	stats := computeProgressStats(nil, nil)
+	if stats.CompletionRate != 0 || stats.EstimateRatio != 0 || stats.StruggleRate != 0 {
+		t.Errorf("empty stats = %+v, want zero rates", stats)
+	}
+}
@@ -2,7 +2,10 @@ package agent
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	"agent-coach/internal/llm"
 	"agent-coach/internal/models"
 	"agent-coach/internal/tool"
 )
@@ -11,20 +14,16 @@ type ExecutorAgent struct {
 	*BaseAgent
 }
 
-func NewExecutorAgent(executor *tool.ToolExecutor) *ExecutorAgent {
-	tools := []models.Tool{
-		tool.ToolPresentTask,
-		tool.ToolProvideHint,
-		tool.ToolMarkComplete,
-		tool.ToolLogStruggle,
+func NewExecutorAgent(router *llm.Router, executor *tool.ToolExecutor) *ExecutorAgent {
+	tools := []string{
+		"present_task",
+		"provide_hint",
+		"mark_complete",
+		"log_struggle",
 	}
 
 	return &ExecutorAgent{
-		BaseAgent: &BaseAgent{
-			agentType:    models.AgentTypeExecutor,
-			toolExecutor: executor,
-			tools:        tools,
-		},
+		BaseAgent: NewBaseAgent(models.AgentTypeExecutor, router, executor, tools),
 	}
 }
 
@@ -63,6 +62,7 @@ Stay focused on helping the user execute the tasks that already exist in their p
 `
 
 	basePrompt += a.BuildContextPrompt(ctx)
+	basePrompt += a.buildResourcesPrompt(ctx)
 
 	basePrompt += `
 
@@ -88,6 +88,8 @@ You are using a model that can call tools directly. You have access to these too
 
 - ToolPresentTask:
   - Use to present or surface tasks for the user (e.g., "what should I do today?", "what's next?").
+  - Returns the task's details and the learning resources attached to it or its milestone; point the user to those resources when they help.
+  - Tasks marked blocked are waiting on unfinished prerequisites; suggest a prerequisite as the next task instead.
 - ToolProvideHint:
   - Use to generate or store targeted hints for a specific task or step the user is working on.
 - ToolMarkComplete:
@@ -118,6 +120,46 @@ Focus on execution support: helping the user take the next step, make progress t
 	return basePrompt
 }
 
+// buildResourcesPrompt lists the unfinished learning resources and what they
+// are attached to.
+func (a *ExecutorAgent) buildResourcesPrompt(ctx *models.AgentContext) string {
+	taskTitles := make(map[string]string, len(ctx.Tasks))
+	for _, task := range ctx.Tasks {
+		taskTitles[task.ID] = task.Title
+	}
+	milestoneTitles := make(map[string]string, len(ctx.Milestones))
+	for _, milestone := range ctx.Milestones {
+		milestoneTitles[milestone.ID] = milestone.Title
+	}
+
+	var sb strings.Builder
+	for _, resource := range ctx.Resources {
+		if resource.Status == models.ResourceStatusDone {
+			continue
+		}
+		if sb.Len() == 0 {
+			sb.WriteString("\n**Learning Resources**:\n")
+		}
+		sb.WriteString(fmt.Sprintf("- %s", resource.Title))
+		if resource.URL != "" {
+			sb.WriteString(fmt.Sprintf(" <%s>", resource.URL))
+		}
+		sb.WriteString(fmt.Sprintf(" [%s]", resource.Status))
+		switch {
+		case resource.TaskID != nil:
+			sb.WriteString(fmt.Sprintf(" for task: %s", taskTitles[*resource.TaskID]))
+		case resource.MilestoneID != nil:
+			sb.WriteString(fmt.Sprintf(" for milestone: %s", milestoneTitles[*resource.MilestoneID]))
+		}
+		if resource.Notes != "" {
+			sb.WriteString(fmt.Sprintf(" (notes: %s)", resource.Notes))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
 func (a *ExecutorAgent) Execute(ctx context.Context, input *AgentInput) (*AgentOutput, error) {
 	systemPrompt := a.SystemPrompt(input.Context)
 	return a.ExecuteWithLLM(ctx, systemPrompt, input)
@@ -0,0 +1,42 @@
+package agent
+
+import (
+	"context"
+
+	"agent-coach/internal/llm"
+	"agent-coach/internal/models"
+	"agent-coach/internal/tool"
+)
+
+// GeneralAgent handles greetings, small talk and off-topic messages. It has
+// no tools, so a general turn never changes stored state.
+type GeneralAgent struct {
+	*BaseAgent
+}
+
+func NewGeneralAgent(router *llm.Router, executor *tool.ToolExecutor) *GeneralAgent {
+	return &GeneralAgent{
+		BaseAgent: NewBaseAgent(models.AgentTypeGeneral, router, executor, nil),
+	}
+}
+
+func (a *GeneralAgent) SystemPrompt(ctx *models.AgentContext) string {
+	basePrompt := `You are a friendly coach in a multi-agent coaching system. You handle greetings, small talk, and messages that are not about planning, doing, or reviewing work.
+
+## How to Respond
+- Reply naturally and briefly (one to three sentences).
+- Be warm and human; match the user's tone.
+- Do not present tasks, create plans, or evaluate progress. Other agents handle that when the user asks.
+- When it fits, gently steer back to the user's goal, for example by mentioning today's tasks or asking if they want to continue. Never push.
+- If the user has no goal yet, you may invite them to set one.
+`
+
+	basePrompt += a.BuildContextPrompt(ctx)
+
+	return basePrompt
+}
+
+func (a *GeneralAgent) Execute(ctx context.Context, input *AgentInput) (*AgentOutput, error) {
+	systemPrompt := a.SystemPrompt(input.Context)
+	return a.ExecuteWithLLM(ctx, systemPrompt, input)
+}
@@ -5,10 +5,12 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"agent-coach/internal/llm"
 	"agent-coach/internal/models"
 	"agent-coach/internal/storage"
+	"agent-coach/internal/streak"
 )
 
 type Orchestrator struct {
@@ -17,37 +19,61 @@ type Orchestrator struct {
 	classifier   *IntentClassifier
 	toolExecutor *tool.ToolExecutor
 
-	plannerAgent  *PlannerAgent
-	executorAgent *ExecutorAgent
+	plannerAgent        *PlannerAgent
+	executorAgent       *ExecutorAgent
+	evaluatorAgent      *EvaluatorAgent
+	accountabilityAgent *AccountabilityAgent
+	generalAgent        *GeneralAgent
 
-	goalRepo *storage.GoalRepository
-	taskRepo *storage.TaskRepository
-	convRepo *storage.ConversationRepository
+	goalRepo      *storage.GoalRepository
+	taskRepo      *storage.TaskRepository
+	convRepo      *storage.ConversationRepository
+	evalRepo      *storage.EvaluationRepository
+	checkInRepo   *storage.CheckInRepository
+	milestoneRepo *storage.MilestoneRepository
+	resourceRepo  *storage.ResourceRepository
+	traceRepo     *storage.TraceRepository
+	activityRepo  *storage.ActivityRepository
+	settingsRepo  *storage.SettingsRepository
+	depRepo       *storage.DependencyRepository
 }
 
 func NewOrchestrator(db *storage.DB, router *llm.Router) *Orchestrator {
 	toolExecutor := tool.NewToolExecutor(db)
 
-	plannerAgent := NewPlannerAgent(toolExecutor)
-	executorAgent := NewExecutorAgent(toolExecutor)
-
-	plannerAgent.BaseAgent.llmRouter = router
-	executorAgent.BaseAgent.llmRouter = router
+	plannerAgent := NewPlannerAgent(router, toolExecutor)
+	executorAgent := NewExecutorAgent(router, toolExecutor)
+	evaluatorAgent := NewEvaluatorAgent(router, toolExecutor)
+	accountabilityAgent := NewAccountabilityAgent(router, toolExecutor)
+	generalAgent := NewGeneralAgent(router, toolExecutor)
 
 	return &Orchestrator{
-		db:            db,
-		llmRouter:     router,
-		classifier:    NewIntentClassifier(router),
-		toolExecutor:  toolExecutor,
-		plannerAgent:  plannerAgent,
-		executorAgent: executorAgent,
-		goalRepo:      storage.NewGoalRepository(db),
-		taskRepo:      storage.NewTaskRepository(db),
-		convRepo:      storage.NewConversationRepository(db),
+		db:                  db,
+		llmRouter:           router,
+		classifier:          NewIntentClassifier(router),
+		toolExecutor:        toolExecutor,
+		plannerAgent:        plannerAgent,
+		executorAgent:       executorAgent,
+		evaluatorAgent:      evaluatorAgent,
+		accountabilityAgent: accountabilityAgent,
+		generalAgent:        generalAgent,
+		goalRepo:            storage.NewGoalRepository(db),
+		taskRepo:            storage.NewTaskRepository(db),
+		convRepo:            storage.NewConversationRepository(db),
+		evalRepo:            storage.NewEvaluationRepository(db),
+		checkInRepo:         storage.NewCheckInRepository(db),
+		milestoneRepo:       storage.NewMilestoneRepository(db),
+		resourceRepo:        storage.NewResourceRepository(db),
+		traceRepo:           storage.NewTraceRepository(db),
+		activityRepo:        storage.NewActivityRepository(db),
+		settingsRepo:        storage.NewSettingsRepository(db),
+		depRepo:             storage.NewDependencyRepository(db),
 	}
 }
 
-func (o *Orchestrator) ProcessMessage(ctx context.Context, message string, goalID string) (*AgentOutput, error) {
+// ProcessMessage handles a user message end to end. When onEvent is non-nil the
+// agent response is streamed and routing/tool activity is reported through it.
+func (o *Orchestrator) ProcessMessage(ctx context.Context, message string, goalID string, onEvent EventHandler) (*AgentOutput, error) {
 	log.Printf("[Orchestrator] Processing message: %q (goalID=%s)", message, goalID)
 
 	// 1. Build context
@@ -60,15 +86,21 @@ func (o *Orchestrator) ProcessMessage(ctx context.Context, message string, goalI
 		agentCtx.Goal != nil,
 		len(agentCtx.Tasks))
 
-	// 2. Classify intent
-	classified, err := o.classifier.Classify(ctx, message, agentCtx)
-	if err != nil {
-		log.Printf("[Orchestrator] Classification error: %v, defaulting to executor", err)
+	// 2. Classify intent. Check-ins are scheduled deterministically; the
+	// classifier is never asked to choose ACCOUNTABILITY.
+	var classified *ClassifiedIntent
+	if reason := checkInReason(agentCtx, agentCtx.Now()); reason != "" {
+		agentCtx.CurrentState = models.StateCheckIn
 		classified = &ClassifiedIntent{
-			Intent:     IntentExecution,
-			Confidence: 0.5,
-			Reason:     "Classification failed, defaulting to executor",
+			Intent:     IntentAccountability,
+			Confidence: 1,
+			Reason:     reason,
 		}
+	} else {
+		classified, err = o.classifier.Classify(ctx, message, agentCtx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("intent classification failed: %w", err)
 	}
 	log.Printf("[Orchestrator] Classified intent: %s (confidence=%.2f, reason=%s)",
 		classified.Intent, classified.Confidence, classified.Reason)
@@ -83,12 +115,16 @@ func (o *Orchestrator) ProcessMessage(ctx context.Context, message string, goalI
 		Context:   agentCtx,
 		GoalID:    goalID,
 		SessionID: fmt.Sprintf("%s", goalID),
+		Intent:    classified.Intent,
+		OnEvent:   onEvent,
 	}
+	input.emit(Event{Type: EventAgentRouted, AgentType: agent.Type(), Intent: classified.Intent})
 
 	output, err := agent.Execute(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("agent execution failed: %w", err)
 	}
+	log.Printf("[Orchestrator] Agent %s finished (stateChanged=%v)", output.AgentType, output.StateChanged)
 
 	// 5. Save conversation
 	o.saveConversation(ctx, goalID, message, output)
@@ -99,6 +135,13 @@ func (o *Orchestrator) ProcessMessage(ctx context.Context, message string, goalI
 func (o *Orchestrator) buildContext(ctx context.Context, goalID string) (*models.AgentContext, error) {
 	agentCtx := &models.AgentContext{}
 
+	settings, err := o.settingsRepo.Get(ctx)
+	if err != nil {
+		log.Printf("[Orchestrator] Failed to load settings: %v", err)
+		settings = models.DefaultUserSettings()
+	}
+	agentCtx.Location = settings.Location()
+
 	goal, err := o.goalRepo.GetByID(ctx, goalID)
 	if err != nil {
 		return nil, err
@@ -117,8 +160,13 @@ func (o *Orchestrator) buildContext(ctx context.Context, goalID string) (*models
 					agentCtx.RecentStruggles++
 				}
 			}
+			dependencies, err := o.depRepo.GetByGoalID(ctx, agentCtx.Goal.ID)
+			if err != nil {
+				log.Printf("[Orchestrator] Failed to load task dependencies: %v", err)
+			}
+			models.MarkBlocked(tasks, dependencies)
 		}
-		todaysTasks, err := o.taskRepo.GetDueToday(ctx)
+		todaysTasks, err := o.taskRepo.GetDueToday(ctx, agentCtx.Location)
 		if err == nil {
 			for _, task := range todaysTasks {
 				if task.GoalID == agentCtx.Goal.ID {
@@ -126,6 +174,38 @@ func (o *Orchestrator) buildContext(ctx context.Context, goalID string) (*models
 				}
 			}
 		}
+		overdue, err := o.taskRepo.GetOverdue(ctx, agentCtx.Goal.ID, agentCtx.Now())
+		if err == nil {
+			agentCtx.OverdueTasks = overdue
+		}
+		ratings, err := o.evalRepo.GetRatingsByGoalID(ctx, agentCtx.Goal.ID)
+		if err != nil {
+			log.Printf("[Orchestrator] Failed to load ratings: %v", err)
+		}
+		agentCtx.Progress = computeProgressStats(agentCtx.Tasks, ratings)
+		weaknesses, err := o.evalRepo.GetWeaknessesByGoalID(ctx, agentCtx.Goal.ID)
+		if err == nil {
+			agentCtx.Weaknesses = weaknesses
+		}
+		milestones, err := o.milestoneRepo.GetByGoalID(ctx, agentCtx.Goal.ID)
+		if err == nil {
+			agentCtx.Milestones = milestones
+		}
+		resources, err := o.resourceRepo.GetByGoalID(ctx, agentCtx.Goal.ID)
+		if err == nil {
+			agentCtx.Resources = resources
+		}
+		lastCheckIn, err := o.checkInRepo.GetLatestByGoalID(ctx, agentCtx.Goal.ID)
+		if err == nil {
+			agentCtx.LastCheckIn = lastCheckIn
+		}
+		goalStreak, err := o.loadStreak(ctx, agentCtx.Goal.ID, settings)
+		if err != nil {
+			log.Printf("[Orchestrator] Failed to compute streak: %v", err)
+		} else {
+			agentCtx.StreakDays = goalStreak.Current
+			agentCtx.LongestStreak = goalStreak.Longest
+		}
 		if len(agentCtx.Tasks) == 0 {
 			agentCtx.CurrentState = models.StatePlanning
 		}
@@ -141,17 +221,35 @@ func (o *Orchestrator) buildContext(ctx context.Context, goalID string) (*models
 	return agentCtx, nil
 }
 
+func (o *Orchestrator) loadStreak(ctx context.Context, goalID string, settings *models.UserSettings) (*models.Streak, error) {
+	days, err := o.activityRepo.GetDays(ctx, goalID, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return streak.Compute(days, settings, time.Now().In(settings.Location())), nil
+}
+
 func (o *Orchestrator) routeToAgent(intent Intent) Agent {
 	switch intent {
 	case IntentPlanning:
 		return o.plannerAgent
 	case IntentExecution:
 		return o.executorAgent
+	case IntentEvaluation:
+		return o.evaluatorAgent
+	case IntentAccountability:
+		return o.accountabilityAgent
+	case IntentGeneral:
+		return o.generalAgent
 	default:
 		return o.executorAgent
 	}
 }
 
+// saveConversation stores the user's message and the reply, and the reply's
+// trace under its ID. The reply has already reached the user, so failures
+// are logged rather than returned; without a stored reply there is no turn
+// to hang the trace on and output.TurnID stays empty.
 func (o *Orchestrator) saveConversation(ctx context.Context, goalID string, userMessage string, output *AgentOutput) {
 	userConv := &models.Conversation{
 		GoalID:    &goalID,
@@ -159,7 +257,9 @@ func (o *Orchestrator) saveConversation(ctx context.Context, goalID string, user
 		Role:      models.RoleUser,
 		Content:   userMessage,
 	}
-	o.convRepo.Create(ctx, userConv)
+	if err := o.convRepo.Create(ctx, userConv); err != nil {
+		log.Printf("[Orchestrator] Failed to save user message: %v", err)
+	}
 
 	agentConv := &models.Conversation{
 		GoalID:    &goalID,
@@ -167,8 +267,23 @@ func (o *Orchestrator) saveConversation(ctx context.Context, goalID string, user
 		Role:      models.RoleAssistant,
 		Content:   output.Response,
 		AgentType: output.AgentType,
+		// Kept with the turn so that the UI can tell, also for past turns,
+		// which replies changed the plan.
+		Metadata: models.JSONMap{"stateChanged": output.StateChanged},
+	}
+	if err := o.convRepo.Create(ctx, agentConv); err != nil {
+		log.Printf("[Orchestrator] Failed to save assistant message, dropping its trace: %v", err)
+		return
+	}
+
+	output.TurnID = agentConv.ID
+	for _, step := range output.Trace {
+		step.TurnID = agentConv.ID
+		step.GoalID = goalID
+	}
+	if err := o.traceRepo.CreateSteps(ctx, output.Trace); err != nil {
+		log.Printf("[Orchestrator] Failed to save turn trace: %v", err)
 	}
-	o.convRepo.Create(ctx, agentConv)
 }
 
 func (o *Orchestrator) GetAgentForIntent(intent Intent) models.AgentType {
@@ -181,6 +296,8 @@ func (o *Orchestrator) GetAgentForIntent(intent Intent) models.AgentType {
 		return models.AgentTypeEvaluator
 	case IntentAccountability:
 		return models.AgentTypeAccountability
+	case IntentGeneral:
+		return models.AgentTypeGeneral
 	default:
 		return models.AgentTypeExecutor
 	}
@@ -188,5 +305,5 @@ func (o *Orchestrator) GetAgentForIntent(intent Intent) models.AgentType {
 
 func (o *Orchestrator) StartOnboarding(ctx context.Context, goalID string, goalTitle string) (*AgentOutput, error) {
 	message := fmt.Sprintf("I want to set a new goal: %s. Help me get started.", goalTitle)
-	return o.ProcessMessage(ctx, message, goalID)
+	return o.ProcessMessage(ctx, message, goalID, nil)
 }
@@ -0,0 +1,457 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"agent-coach/internal/llm"
+	"agent-coach/internal/models"
+	"agent-coach/internal/storage"
+)
+
+// newTestDB returns an in-memory database with every migration applied.
+func newTestDB(t *testing.T) *storage.DB {
+	t.Helper()
+
+	db, err := storage.Open(":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Migrate(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// newTestOrchestrator wires an orchestrator to a router serving the given
+// provider configs, the first of which becomes the default.
+func newTestOrchestrator(t *testing.T, db *storage.DB, configs ...models.LLMProviderConfig) *Orchestrator {
+	t.Helper()
+
+	router, err := llm.NewRouter(db)
+	if err != nil {
+		t.Fatalf("new router: %v", err)
+	}
+	for i := range configs {
+		if err := router.SaveProviderConfig(context.Background(), &configs[i]); err != nil {
+			t.Fatalf("save provider %s: %v", configs[i].Name, err)
+		}
+	}
+	return NewOrchestrator(db, router)
+}
+
+func replayConfig(name, fixture string) models.LLMProviderConfig {
+	return models.LLMProviderConfig{
+		Name:         name,
+		Provider:     string(llm.ProviderTypeMock),
+		DefaultModel: "replay-model",
+		FixturePath:  fixture,
+		IsDefault:    true,
+		IsActive:     true,
+	}
+}
+
+func TestProcessMessageStreamsPlanningTurn(t *testing.T) {
+	db := newTestDB(t)
+	o := newTestOrchestrator(t, db, replayConfig("replay", filepath.Join("testdata", "planning_clarify.json")))
+
+	var events []Event
+	output, err := o.ProcessMessage(context.Background(), "I want to learn Go", "", func(event Event) {
+		events = append(events, event)
+	})
+	if err != nil {
+		t.Fatalf("ProcessMessage: %v", err)
+	}
+
+	want := "Great goal! Before I build your roadmap: how many hours per week can you spend on Go?"
+	if output.Response != want {
+		t.Errorf("response = %q, want %q", output.Response, want)
+	}
+	if output.AgentType != models.AgentTypePlanner {
+		t.Errorf("agent type = %s, want %s", output.AgentType, models.AgentTypePlanner)
+	}
+
+	if len(events) == 0 || events[0].Type != EventAgentRouted {
+		t.Fatalf("first event = %+v, want %s", events, EventAgentRouted)
+	}
+	if events[0].Intent != IntentPlanning || events[0].AgentType != models.AgentTypePlanner {
+		t.Errorf("routed event = %+v, want planning/planner", events[0])
+	}
+
+	var streamed strings.Builder
+	var toolEvents []EventType
+	for _, event := range events {
+		switch event.Type {
+		case EventChunk:
+			streamed.WriteString(event.Content)
+		case EventToolCallStart, EventToolCallFinish:
+			if event.ToolCall.Function.Name != "ask_clarifying_question" {
+				t.Errorf("tool event for %s, want ask_clarifying_question", event.ToolCall.Function.Name)
+			}
+			toolEvents = append(toolEvents, event.Type)
+		}
+	}
+	if streamed.String() != output.Response {
+		t.Errorf("streamed %q, but response is %q", streamed.String(), output.Response)
+	}
+	if len(toolEvents) != 2 || toolEvents[0] != EventToolCallStart || toolEvents[1] != EventToolCallFinish {
+		t.Errorf("tool events = %v, want start then finish", toolEvents)
+	}
+}
+
+func TestProcessMessageWithoutStreaming(t *testing.T) {
+	db := newTestDB(t)
+	o := newTestOrchestrator(t, db, replayConfig("replay", filepath.Join("testdata", "planning_clarify.json")))
+
+	output, err := o.ProcessMessage(context.Background(), "I want to learn Go", "", nil)
+	if err != nil {
+		t.Fatalf("ProcessMessage: %v", err)
+	}
+	if !strings.HasPrefix(output.Response, "Great goal!") {
+		t.Errorf("response = %q", output.Response)
+	}
+
+	var agents []string
+	if err := db.Select(&agents, `SELECT agent_type FROM llm_usage ORDER BY id`); err != nil {
+		t.Fatalf("select usage: %v", err)
+	}
+	if strings.Join(agents, ",") != "classifier,planner,planner" {
+		t.Errorf("usage recorded for %v, want classifier,planner,planner", agents)
+	}
+}
+
+func TestProcessMessageFallsBackWhenClassifierFails(t *testing.T) {
+	db := newTestDB(t)
+	// The classifier's reply is not JSON, so the heuristics route a
+	// goal-less user to the planner.
+	o := newTestOrchestrator(t, db, replayConfig("replay", filepath.Join("testdata", "classifier_unparseable.json")))
+
+	output, err := o.ProcessMessage(context.Background(), "hello", "", nil)
+	if err != nil {
+		t.Fatalf("ProcessMessage: %v", err)
+	}
+	if output.AgentType != models.AgentTypePlanner {
+		t.Errorf("agent type = %s, want planner", output.AgentType)
+	}
+}
+
+func TestProcessMessageReturnsClassifierErrors(t *testing.T) {
+	db := newTestDB(t)
+	// The fixture holds no classifier response, which the replay provider
+	// reports as an invalid request: a model error the heuristics must not hide.
+	o := newTestOrchestrator(t, db, replayConfig("replay", filepath.Join("testdata", "planner_only.json")))
+
+	_, err := o.ProcessMessage(context.Background(), "hello", "", nil)
+	if kind := llm.ClassifyError(err); kind != llm.ErrorKindInvalidRequest {
+		t.Errorf("error = %v (%s), want the classifier's invalid request", err, kind)
+	}
+}
+
+func TestClassifierFallsBackOnlyWhenNoModelIsReachable(t *testing.T) {
+	allFailed := func(kind llm.ErrorKind) error {
+		return fmt.Errorf("%w: %w", llm.ErrAllProvidersFailed, &llm.ProviderError{Kind: kind, Err: errors.New(string(kind))})
+	}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"no provider", llm.ErrNoProvider, true},
+		{"every breaker open", fmt.Errorf("%w: every provider is temporarily disabled", llm.ErrAllProvidersFailed), true},
+		{"transient", allFailed(llm.ErrorKindTransient), true},
+		{"rate limited", allFailed(llm.ErrorKindRateLimit), true},
+		{"auth", allFailed(llm.ErrorKindAuth), false},
+		{"budget", fmt.Errorf("%w: spent $5.00 of $5.00", llm.ErrBudgetExceeded), false},
+		{"invalid request", &llm.ProviderError{Kind: llm.ErrorKindInvalidRequest, Err: errors.New("bad")}, false},
+		{"canceled", context.Canceled, false},
+	}
+	for _, tt := range tests {
+		if got := modelUnavailable(tt.err); got != tt.want {
+			t.Errorf("%s: modelUnavailable = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// A cancelled turn is reported as such, not classified by heuristics.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	o := newTestOrchestrator(t, newTestDB(t), replayConfig("replay", filepath.Join("testdata", "general_chat.json")))
+	if _, err := o.classifier.Classify(ctx, "hi", &models.AgentContext{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled Classify error = %v, want context.Canceled", err)
+	}
+}
+
+func TestRecordedSessionReplays(t *testing.T) {
+	recorded := filepath.Join(t.TempDir(), "recorded.json")
+
+	source := replayConfig("source", filepath.Join("testdata", "planning_clarify.json"))
+	source.IsDefault = false
+	recorder := replayConfig("recorder", recorded)
+	recorder.RecordFrom = "source"
+
+	db := newTestDB(t)
+	o := newTestOrchestrator(t, db, source, recorder)
+	first, err := o.ProcessMessage(context.Background(), "I want to learn Go", "", nil)
+	if err != nil {
+		t.Fatalf("recording run: %v", err)
+	}
+
+	fixture, err := llm.LoadFixture(recorded)
+	if err != nil {
+		t.Fatalf("load recorded fixture: %v", err)
+	}
+	if len(fixture.Interactions) != 3 {
+		t.Fatalf("recorded %d interactions, want 3", len(fixture.Interactions))
+	}
+
+	replayDB := newTestDB(t)
+	replay := newTestOrchestrator(t, replayDB, replayConfig("replay", recorded))
+	second, err := replay.ProcessMessage(context.Background(), "I want to learn Go", "", nil)
+	if err != nil {
+		t.Fatalf("replay run: %v", err)
+	}
+	if second.Response != first.Response {
+		t.Errorf("replayed response %q differs from recorded %q", second.Response, first.Response)
+	}
+}
+
+func TestProcessMessageRoutesSmallTalkToGeneralAgent(t *testing.T) {
+	db := newTestDB(t)
+	o := newTestOrchestrator(t, db, replayConfig("replay", filepath.Join("testdata", "general_chat.json")))
+
+	output, err := o.ProcessMessage(context.Background(), "hi", "", nil)
+	if err != nil {
+		t.Fatalf("ProcessMessage: %v", err)
+	}
+	if output.AgentType != models.AgentTypeGeneral {
+		t.Errorf("agent type = %s, want %s", output.AgentType, models.AgentTypeGeneral)
+	}
+	if output.StateChanged {
+		t.Error("small talk reported a state change")
+	}
+}
+
+func TestProcessMessagePersistsTurnTrace(t *testing.T) {
+	db := newTestDB(t)
+	o := newTestOrchestrator(t, db, replayConfig("replay", filepath.Join("testdata", "planning_clarify.json")))
+
+	output, err := o.ProcessMessage(context.Background(), "I want to learn Go", "", nil)
+	if err != nil {
+		t.Fatalf("ProcessMessage: %v", err)
+	}
+	if output.TurnID == "" {
+		t.Fatal("output has no turn ID")
+	}
+
+	steps, err := storage.NewTraceRepository(db).GetByTurnID(context.Background(), output.TurnID)
+	if err != nil {
+		t.Fatalf("GetByTurnID: %v", err)
+	}
+	if len(steps) != 3 {
+		t.Fatalf("trace has %d steps, want 3", len(steps))
+	}
+
+	call := steps[0]
+	if call.Role != models.RoleAssistant || len(call.ToolCalls) != 1 {
+		t.Fatalf("step 0 = %+v, want assistant message with one tool call", call)
+	}
+	if call.ToolCalls[0].Function.Arguments["question"] != "How many hours per week can you spend on Go?" {
+		t.Errorf("tool call arguments = %v", call.ToolCalls[0].Function.Arguments)
+	}
+	result := steps[1]
+	if result.Role != models.RoleTool || result.ToolCallID != "call_clarify_1" || result.ToolName != "ask_clarifying_question" {
+		t.Errorf("step 1 = %+v, want result of call_clarify_1", result)
+	}
+	if steps[2].Role != models.RoleAssistant || steps[2].Content != output.Response {
+		t.Errorf("step 2 = %+v, want final response", steps[2])
+	}
+}
+
+func TestLoopLimitEndsWithSummaryTurn(t *testing.T) {
+	db := newTestDB(t)
+	o := newTestOrchestrator(t, db, replayConfig("replay", filepath.Join("testdata", "planning_limit.json")))
+
+	maxIterations := 1
+	if err := o.llmRouter.SaveAgentModelConfig(context.Background(), &models.AgentModelConfig{
+		AgentType:     models.AgentTypePlanner,
+		MaxIterations: &maxIterations,
+	}); err != nil {
+		t.Fatalf("save agent config: %v", err)
+	}
+
+	output, err := o.ProcessMessage(context.Background(), "Plan my Go learning", "", nil)
+	if err != nil {
+		t.Fatalf("ProcessMessage: %v", err)
+	}
+	if output.Response != "I still need your deadline before I can build the plan." {
+		t.Errorf("response = %q, want the summary turn", output.Response)
+	}
+	if len(output.Trace) != 3 || output.Trace[2].Role != models.RoleAssistant {
+		t.Errorf("trace = %+v, want tool call, tool result and summary", output.Trace)
+	}
+}
+
+func TestFallbackSummaryListsToolCalls(t *testing.T) {
+	summary := fallbackSummary([]*models.TurnStep{
+		{Role: models.RoleAssistant},
+		{Role: models.RoleTool, ToolName: "create_task"},
+		{Role: models.RoleTool, ToolName: "create_milestone", Error: "invalid week range"},
+	})
+	want := "I had to stop before finishing my reply. Completed actions: create_task. Failed actions: create_milestone. Send another message to continue."
+	if summary != want {
+		t.Errorf("fallbackSummary = %q, want %q", summary, want)
+	}
+}
+
+func TestContextPromptIncludesStoredOnboardingAnswers(t *testing.T) {
+	db := newTestDB(t)
+	o := newTestOrchestrator(t, db, replayConfig("replay", filepath.Join("testdata", "general_chat.json")))
+
+	goal := &models.Goal{
+		Title:   "Learn Go",
+		Status:  models.GoalStatusActive,
+		Context: models.JSONMap{"level": "beginner", "hoursPerWeek": 6},
+	}
+	if err := storage.NewGoalRepository(db).Create(context.Background(), goal); err != nil {
+		t.Fatalf("create goal: %v", err)
+	}
+
+	agentCtx, err := o.buildContext(context.Background(), goal.ID)
+	if err != nil {
+		t.Fatalf("buildContext: %v", err)
+	}
+	prompt := (&BaseAgent{}).BuildContextPrompt(agentCtx)
+	want := "User's onboarding answers:\n- hoursPerWeek: 6\n- level: beginner\n"
+	if !strings.Contains(prompt, want) {
+		t.Errorf("prompt does not contain %q:\n%s", want, prompt)
+	}
+}
+
+func TestContextPromptShowsMilestoneProgress(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	o := newTestOrchestrator(t, db, replayConfig("replay", filepath.Join("testdata", "general_chat.json")))
+
+	goal := &models.Goal{Title: "Learn Go", Status: models.GoalStatusActive}
+	if err := storage.NewGoalRepository(db).Create(ctx, goal); err != nil {
+		t.Fatalf("create goal: %v", err)
+	}
+	milestones := storage.NewMilestoneRepository(db)
+	basics := &models.Milestone{GoalID: goal.ID, Title: "Go basics", WeekStart: 1, WeekEnd: 2, Status: models.MilestoneStatusInProgress}
+	concurrency := &models.Milestone{GoalID: goal.ID, Title: "Concurrency", WeekStart: 3, WeekEnd: 4}
+	for _, milestone := range []*models.Milestone{basics, concurrency} {
+		if err := milestones.Create(ctx, milestone); err != nil {
+			t.Fatalf("create milestone: %v", err)
+		}
+	}
+	for _, status := range []models.TaskStatus{models.TaskStatusCompleted, models.TaskStatusCompleted, models.TaskStatusPending} {
+		task := &models.Task{GoalID: goal.ID, Title: "Exercise", Status: status, MilestoneID: &basics.ID}
+		if err := storage.NewTaskRepository(db).Create(ctx, task); err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+	}
+
+	agentCtx, err := o.buildContext(ctx, goal.ID)
+	if err != nil {
+		t.Fatalf("buildContext: %v", err)
+	}
+	prompt := (&BaseAgent{}).BuildContextPrompt(agentCtx)
+	for _, want := range []string{
+		"**Milestones**:\n",
+		fmt.Sprintf("- [in_progress] Weeks 1-2: Go basics (2/3 tasks done, id: %s)\n", basics.ID),
+		fmt.Sprintf("- [pending] Weeks 3-4: Concurrency (0/0 tasks done, id: %s)\n", concurrency.ID),
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt does not contain %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestProcessMessagePersistsStateChanged(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	o := newTestOrchestrator(t, db, replayConfig("replay", filepath.Join("testdata", "planning_milestone.json")))
+
+	goal := &models.Goal{Title: "Learn Go", Status: models.GoalStatusActive}
+	if err := storage.NewGoalRepository(db).Create(ctx, goal); err != nil {
+		t.Fatalf("create goal: %v", err)
+	}
+
+	output, err := o.ProcessMessage(ctx, "Add a milestone for the basics", goal.ID, nil)
+	if err != nil {
+		t.Fatalf("ProcessMessage: %v", err)
+	}
+	if !output.StateChanged {
+		t.Fatal("creating a milestone did not report a state change")
+	}
+
+	turn, err := storage.NewConversationRepository(db).GetByID(ctx, output.TurnID)
+	if err != nil || turn == nil {
+		t.Fatalf("GetByID = %v, %v", turn, err)
+	}
+	if turn.Metadata["stateChanged"] != true {
+		t.Errorf("turn metadata = %v, want stateChanged true", turn.Metadata)
+	}
+}
+
+func TestMalformedToolArgumentsAreReportedToTheModel(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	o := newTestOrchestrator(t, db, replayConfig("replay", filepath.Join("testdata", "planning_malformed_args.json")))
+
+	goal := &models.Goal{Title: "Learn Go", Status: models.GoalStatusActive}
+	if err := storage.NewGoalRepository(db).Create(ctx, goal); err != nil {
+		t.Fatalf("create goal: %v", err)
+	}
+
+	output, err := o.ProcessMessage(ctx, "Add a milestone for the basics", goal.ID, nil)
+	if err != nil {
+		t.Fatalf("ProcessMessage: %v", err)
+	}
+	if len(output.Trace) != 5 {
+		t.Fatalf("trace has %d steps, want the bad call, its error, the retry, its result and the reply", len(output.Trace))
+	}
+
+	failed := output.Trace[1]
+	if failed.ToolCallID != "call_milestone_1" || failed.Error == "" {
+		t.Errorf("step 1 = %+v, want an error for call_milestone_1", failed)
+	}
+	for _, want := range []string{`"error":"invalid arguments"`, `"field":"arguments"`, "not valid JSON"} {
+		if !strings.Contains(failed.Content, want) {
+			t.Errorf("tool result %s does not contain %s", failed.Content, want)
+		}
+	}
+	if retried := output.Trace[3]; retried.ToolCallID != "call_milestone_2" || retried.Error != "" {
+		t.Errorf("step 3 = %+v, want the corrected call to succeed", retried)
+	}
+}
+
+func TestProcessMessageSkipsTraceWhenReplyIsNotSaved(t *testing.T) {
+	db := newTestDB(t)
+	o := newTestOrchestrator(t, db, replayConfig("replay", filepath.Join("testdata", "planning_clarify.json")))
+	_, err := db.Exec(`
+		CREATE TRIGGER fail_assistant_messages BEFORE INSERT ON conversations
+		WHEN NEW.role = 'assistant'
+		BEGIN SELECT RAISE(ABORT, 'disk full'); END
+	`)
+	if err != nil {
+		t.Fatalf("create trigger: %v", err)
+	}
+
+	output, err := o.ProcessMessage(context.Background(), "I want to learn Go", "", nil)
+	if err != nil {
+		t.Fatalf("ProcessMessage: %v", err)
+	}
+	if output.TurnID != "" {
+		t.Errorf("turn ID = %q for a reply that was not saved", output.TurnID)
+	}
+	var steps int
+	if err := db.Get(&steps, `SELECT COUNT(*) FROM turn_steps`); err != nil || steps != 0 {
+		t.Errorf("saved %d trace steps (%v), want none", steps, err)
+	}
+}
@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"agent-coach/internal/llm"
 	"agent-coach/internal/models"
 	"agent-coach/internal/tool"
 	"context"
@@ -10,20 +11,16 @@ type PlannerAgent struct {
 	*BaseAgent
 }
 
-func NewPlannerAgent(executor *tool.ToolExecutor) *PlannerAgent {
-	tools := []models.Tool{
-		tool.ToolCreateMilestone,
-		tool.ToolCreateTask,
-		tool.ToolSuggestResources,
-		tool.ToolAskClarifyingQuestion,
+func NewPlannerAgent(router *llm.Router, executor *tool.ToolExecutor) *PlannerAgent {
+	tools := []string{
+		"create_milestone",
+		"create_task",
+		"suggest_resources",
+		"ask_clarifying_question",
 	}
 
 	return &PlannerAgent{
-		BaseAgent: &BaseAgent{
-			agentType:    models.AgentTypePlanner,
-			toolExecutor: executor,
-			tools:        tools,
-		},
+		BaseAgent: NewBaseAgent(models.AgentTypePlanner, router, executor, tools),
 	}
 }
 
@@ -82,7 +79,7 @@ Stay focused on planning and structure.
 You are using a model that can call tools directly. You have access to these tools:
 
 - ToolCreateMilestone: create new milestones for the user's goal.
-- ToolCreateTask: create new tasks associated with a goal or milestone.
+- ToolCreateTask: create new tasks associated with a goal; pass the milestone_id returned by ToolCreateMilestone to link a task to its milestone. Due dates are days in the user's time zone (see Today above); add due_time only when the task is due at a specific time. Each day has limited time for tasks: if create_task reports a day as overbooked, use its suggested_date unless the user insists on that day (then set allow_overbooking). Leave due_date out to schedule a task on the first day with room. When a task needs others done first, pass their task IDs in depends_on; prerequisites must be due no later than the task.
 - ToolSuggestResources: suggest relevant learning resources connected to tasks or milestones.
 - ToolAskClarifyingQuestion: ask focused clarifying questions when the information you have is insufficient or ambiguous.
 
@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 
+	"agent-coach/internal/llm"
 	"agent-coach/internal/models"
 )
 
@@ -22,12 +23,29 @@ type AgentInput struct {
 	Context   *models.AgentContext
 	GoalID    string
 	SessionID string
+	Intent    Intent
+	OnEvent   EventHandler
+}
+
+func (in *AgentInput) emit(event Event) {
+	if in.OnEvent == nil {
+		return
+	}
+	event.GoalID = in.GoalID
+	in.OnEvent(event)
 }
 
 type AgentOutput struct {
 	Response  string
 	NextState *models.State
 	AgentType models.AgentType
+	// StateChanged is true when a tool call during the turn modified stored
+	// data, separating work from conversation in analytics.
+	StateChanged bool
+	// TurnID identifies the saved turn; Trace holds every assistant message
+	// and tool result exchanged while producing Response.
+	TurnID string
+	Trace  []*models.TurnStep
 }
 
 type Agent interface {
@@ -42,3 +60,27 @@ type ClassifiedIntent struct {
 	Confidence float64 `json:"confidence"`
 	Reason     string  `json:"reason"`
 }
+
+// EventType identifies an incremental update emitted while a message is processed
+type EventType string
+
+const (
+	EventAgentRouted    EventType = "agent_routed"
+	EventChunk          EventType = "chunk"
+	EventToolCallStart  EventType = "tool_call_start"
+	EventToolCallFinish EventType = "tool_call_finish"
+)
+
+type Event struct {
+	Type      EventType          `json:"type"`
+	GoalID    string             `json:"goalId"`
+	AgentType models.AgentType   `json:"agentType,omitempty"`
+	Intent    Intent             `json:"intent,omitempty"`
+	Content   string             `json:"content,omitempty"`
+	ToolCall  *models.ToolCall   `json:"toolCall,omitempty"`
+	Delta     *llm.ToolCallDelta `json:"delta,omitempty"`
+	Result    string             `json:"result,omitempty"`
+	Error     string             `json:"error,omitempty"`
+}
+
+type EventHandler func(event Event)
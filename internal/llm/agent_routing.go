@@ -0,0 +1,144 @@
+package llm
+
+import (
+	"context"
+
+	"agent-coach/internal/models"
+)
+
+func (r *Router) GetAgentModelConfigs(ctx context.Context) ([]*models.AgentModelConfig, error) {
+	query := `SELECT * FROM agent_model_configs ORDER BY agent_type`
+	var configs []*models.AgentModelConfig
+	if err := r.db.SelectContext(ctx, &configs, query); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+func (r *Router) SaveAgentModelConfig(ctx context.Context, config *models.AgentModelConfig) error {
+	query := `
+		INSERT INTO agent_model_configs (agent_type, provider_name, model, temperature, max_tokens,
+			max_iterations, max_total_tokens, timeout_seconds)
+		VALUES (:agent_type, :provider_name, :model, :temperature, :max_tokens,
+			:max_iterations, :max_total_tokens, :timeout_seconds)
+	`
+	result, err := r.db.NamedExecContext(ctx, query, config)
+	if err != nil {
+		return err
+	}
+	if id, err := result.LastInsertId(); err == nil {
+		config.ID = int(id)
+	}
+
+	return r.refreshAgentModelConfigs(ctx)
+}
+
+func (r *Router) UpdateAgentModelConfig(ctx context.Context, config *models.AgentModelConfig) error {
+	query := `
+		UPDATE agent_model_configs
+		SET agent_type = :agent_type, provider_name = :provider_name, model = :model,
+		    temperature = :temperature, max_tokens = :max_tokens, max_iterations = :max_iterations,
+		    max_total_tokens = :max_total_tokens, timeout_seconds = :timeout_seconds,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = :id
+	`
+	_, err := r.db.NamedExecContext(ctx, query, config)
+	if err != nil {
+		return err
+	}
+
+	return r.refreshAgentModelConfigs(ctx)
+}
+
+func (r *Router) DeleteAgentModelConfig(ctx context.Context, id int) error {
+	query := `DELETE FROM agent_model_configs WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	return r.refreshAgentModelConfigs(ctx)
+}
+
+// AgentModelConfig returns a copy of the configuration for agentType, or nil
+// when the agent has none.
+func (r *Router) AgentModelConfig(agentType models.AgentType) *models.AgentModelConfig {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	config, ok := r.agentConfigs[agentType]
+	if !ok {
+		return nil
+	}
+	copied := *config
+	return &copied
+}
+
+func (r *Router) refreshAgentModelConfigs(ctx context.Context) error {
+	configs, err := r.GetAgentModelConfigs(ctx)
+	if err != nil {
+		return err
+	}
+
+	byAgent := make(map[models.AgentType]*models.AgentModelConfig, len(configs))
+	for _, config := range configs {
+		byAgent[config.AgentType] = config
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.agentConfigs = byAgent
+	return nil
+}
+
+// agentRoute is a request with the caller's agent configuration applied.
+type agentRoute struct {
+	req *CompletionRequest
+	// provider is the provider the agent is pinned to, if any.
+	provider string
+	// pinnedModel is set when the model came from the agent configuration and
+	// therefore only makes sense for the pinned provider.
+	pinnedModel bool
+}
+
+// routeForAgent fills in the model settings configured for req.AgentType.
+// Values already set on the request take precedence.
+func (r *Router) routeForAgent(req *CompletionRequest) agentRoute {
+	if req.AgentType == "" {
+		return agentRoute{req: req}
+	}
+
+	r.mu.RLock()
+	config := r.agentConfigs[req.AgentType]
+	r.mu.RUnlock()
+	if config == nil {
+		return agentRoute{req: req}
+	}
+
+	routed := *req
+	if routed.Temperature == nil {
+		routed.Temperature = config.Temperature
+	}
+	if routed.MaxTokens == nil {
+		routed.MaxTokens = config.MaxTokens
+	}
+	pinnedModel := false
+	if routed.Model == "" && config.Model != "" {
+		routed.Model = config.Model
+		pinnedModel = config.ProviderName != ""
+	}
+
+	return agentRoute{req: &routed, provider: config.ProviderName, pinnedModel: pinnedModel}
+}
+
+// requestFor returns the request to send to the named provider. When falling
+// back away from the pinned provider, its model is dropped in favour of the
+// fallback provider's default.
+func (route agentRoute) requestFor(name string) *CompletionRequest {
+	if !route.pinnedModel || name == route.provider {
+		return route.req
+	}
+	fallback := *route.req
+	fallback.Model = ""
+	return &fallback
+}
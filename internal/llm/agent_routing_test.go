@@ -0,0 +1,167 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"agent-coach/internal/models"
+)
+
+func floatPtr(v float64) *float64 { return &v }
+
+func intPtr(v int) *int { return &v }
+
+func TestAgentModelConfigCRUD(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRouter(t)
+
+	config := &models.AgentModelConfig{AgentType: models.AgentTypePlanner, ProviderName: "openrouter", Model: "big", MaxIterations: intPtr(4)}
+	if err := r.SaveAgentModelConfig(ctx, config); err != nil {
+		t.Fatalf("SaveAgentModelConfig: %v", err)
+	}
+	if config.ID == 0 {
+		t.Error("saved config has no ID")
+	}
+	got := r.AgentModelConfig(models.AgentTypePlanner)
+	if got == nil || got.Model != "big" || *got.MaxIterations != 4 {
+		t.Fatalf("AgentModelConfig = %+v, want the saved config", got)
+	}
+	got.Model = "changed"
+	if r.AgentModelConfig(models.AgentTypePlanner).Model != "big" {
+		t.Error("AgentModelConfig returned the cached config, not a copy")
+	}
+
+	config.Model = "bigger"
+	if err := r.UpdateAgentModelConfig(ctx, config); err != nil {
+		t.Fatalf("UpdateAgentModelConfig: %v", err)
+	}
+	if got := r.AgentModelConfig(models.AgentTypePlanner); got.Model != "bigger" {
+		t.Errorf("model after update = %q, want bigger", got.Model)
+	}
+
+	if err := r.DeleteAgentModelConfig(ctx, config.ID); err != nil {
+		t.Fatalf("DeleteAgentModelConfig: %v", err)
+	}
+	if got := r.AgentModelConfig(models.AgentTypePlanner); got != nil {
+		t.Errorf("config after delete = %+v, want none", got)
+	}
+	if configs, err := r.GetAgentModelConfigs(ctx); err != nil || len(configs) != 0 {
+		t.Errorf("GetAgentModelConfigs = %v, %v; want none", configs, err)
+	}
+}
+
+func TestRouterAppliesAgentModelConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        models.AgentModelConfig
+		req           CompletionRequest
+		secondaryErrs []error
+		// wantProvider receives the request with wantModel; the other
+		// provider must not be called unless failing over.
+		wantProvider    string
+		wantModel       string
+		wantTemperature *float64
+		wantMaxTokens   *int
+	}{
+		{
+			name:         "pinned provider and model are tried first",
+			config:       models.AgentModelConfig{AgentType: models.AgentTypePlanner, ProviderName: "secondary", Model: "big"},
+			req:          CompletionRequest{AgentType: models.AgentTypePlanner},
+			wantProvider: "secondary",
+			wantModel:    "big",
+		},
+		{
+			name:          "pinned model is dropped when failing over",
+			config:        models.AgentModelConfig{AgentType: models.AgentTypePlanner, ProviderName: "secondary", Model: "big"},
+			req:           CompletionRequest{AgentType: models.AgentTypePlanner},
+			secondaryErrs: []error{providerErr(ErrorKindAuth)},
+			wantProvider:  "primary",
+			wantModel:     "",
+		},
+		{
+			name:            "temperature and max tokens fill in unset values",
+			config:          models.AgentModelConfig{AgentType: models.AgentTypeExecutor, Model: "mid", Temperature: floatPtr(0.2), MaxTokens: intPtr(512)},
+			req:             CompletionRequest{AgentType: models.AgentTypeExecutor},
+			wantProvider:    "primary",
+			wantModel:       "mid",
+			wantTemperature: floatPtr(0.2),
+			wantMaxTokens:   intPtr(512),
+		},
+		{
+			name:            "request values win over the config",
+			config:          models.AgentModelConfig{AgentType: models.AgentTypeExecutor, Model: "mid", Temperature: floatPtr(0.2), MaxTokens: intPtr(512)},
+			req:             CompletionRequest{AgentType: models.AgentTypeExecutor, Model: "own", Temperature: floatPtr(0.9)},
+			wantProvider:    "primary",
+			wantModel:       "own",
+			wantTemperature: floatPtr(0.9),
+			wantMaxTokens:   intPtr(512),
+		},
+		{
+			name:            "classifier config is honoured",
+			config:          models.AgentModelConfig{AgentType: models.AgentTypeClassifier, ProviderName: "secondary", Model: "small", Temperature: floatPtr(0)},
+			req:             CompletionRequest{AgentType: models.AgentTypeClassifier},
+			wantProvider:    "secondary",
+			wantModel:       "small",
+			wantTemperature: floatPtr(0),
+		},
+		{
+			name:         "other agents are unaffected",
+			config:       models.AgentModelConfig{AgentType: models.AgentTypeClassifier, ProviderName: "secondary", Model: "small"},
+			req:          CompletionRequest{AgentType: models.AgentTypePlanner},
+			wantProvider: "primary",
+			wantModel:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			primary := &fakeProvider{name: "primary"}
+			secondary := &fakeProvider{name: "secondary", errs: tt.secondaryErrs}
+			r := newTestRouter(t, primary, secondary)
+			if err := r.SaveAgentModelConfig(ctx, &tt.config); err != nil {
+				t.Fatalf("SaveAgentModelConfig: %v", err)
+			}
+
+			req := tt.req
+			resp, err := r.Complete(ctx, &req)
+			if err != nil || resp.Content != tt.wantProvider {
+				t.Fatalf("Complete = %+v, %v; want an answer from %s", resp, err, tt.wantProvider)
+			}
+			if req.Model != tt.req.Model || req.Temperature != tt.req.Temperature {
+				t.Error("routing modified the caller's request")
+			}
+
+			provider := primary
+			if tt.wantProvider == "secondary" {
+				provider = secondary
+			} else if len(tt.secondaryErrs) == 0 && secondary.calls > 0 {
+				t.Errorf("secondary called %d times, want none", secondary.calls)
+			}
+			if tt.wantProvider == "secondary" && primary.calls > 0 {
+				t.Errorf("primary called %d times before the pinned provider", primary.calls)
+			}
+
+			sent := provider.requests[len(provider.requests)-1]
+			if sent.Model != tt.wantModel {
+				t.Errorf("model = %q, want %q", sent.Model, tt.wantModel)
+			}
+			if !equalPtr(sent.Temperature, tt.wantTemperature) || !equalPtr(sent.MaxTokens, tt.wantMaxTokens) {
+				t.Errorf("temperature = %v, max tokens = %v; want %v and %v", deref(sent.Temperature), deref(sent.MaxTokens), deref(tt.wantTemperature), deref(tt.wantMaxTokens))
+			}
+		})
+	}
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func deref[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
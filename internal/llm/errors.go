@@ -0,0 +1,161 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+)
+
+// ErrorKind groups provider failures by how the router should react to them.
+type ErrorKind string
+
+const (
+	ErrorKindAuth           ErrorKind = "auth"
+	ErrorKindRateLimit      ErrorKind = "rate_limit"
+	ErrorKindContextLength  ErrorKind = "context_length"
+	ErrorKindTransient      ErrorKind = "transient"
+	ErrorKindInvalidRequest ErrorKind = "invalid_request"
+	ErrorKindCanceled       ErrorKind = "canceled"
+	ErrorKindUnknown        ErrorKind = "unknown"
+)
+
+// Retryable reports whether the same provider may succeed if asked again.
+func (k ErrorKind) Retryable() bool {
+	return k == ErrorKindRateLimit || k == ErrorKindTransient
+}
+
+// ShouldFailover reports whether another provider may succeed where this one
+// failed. Problems with the request itself would fail everywhere.
+func (k ErrorKind) ShouldFailover() bool {
+	switch k {
+	case ErrorKindAuth, ErrorKindRateLimit, ErrorKindTransient, ErrorKindUnknown:
+		return true
+	default:
+		return false
+	}
+}
+
+// ProviderError is a classified failure returned by a provider.
+type ProviderError struct {
+	Provider   string
+	Kind       ErrorKind
+	StatusCode int
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s: %s error: %v", e.Provider, e.Kind, e.Err)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// ErrAllProvidersFailed is returned when every provider in the chain was
+// skipped or failed.
+var ErrAllProvidersFailed = errors.New("all LLM providers failed")
+
+// ClassifyError maps an error returned by a provider onto an ErrorKind.
+func ClassifyError(err error) ErrorKind {
+	if err == nil {
+		return ""
+	}
+
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return providerErr.Kind
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return ErrorKindCanceled
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorKindTransient
+	}
+
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		return classifyStatus(apiErr.StatusCode, apiErr.Message)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ErrorKindTransient
+	}
+
+	return ErrorKindUnknown
+}
+
+// classifyStatus maps an HTTP status and error message onto an ErrorKind.
+func classifyStatus(status int, message string) ErrorKind {
+	if isContextLengthMessage(message) {
+		return ErrorKindContextLength
+	}
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusPaymentRequired:
+		return ErrorKindAuth
+	case status == http.StatusTooManyRequests:
+		return ErrorKindRateLimit
+	case status == http.StatusRequestTimeout || status >= 500:
+		return ErrorKindTransient
+	case status == http.StatusRequestEntityTooLarge:
+		return ErrorKindContextLength
+	case status >= 400:
+		return ErrorKindInvalidRequest
+	default:
+		return ErrorKindUnknown
+	}
+}
+
+func isContextLengthMessage(message string) bool {
+	message = strings.ToLower(message)
+	for _, marker := range []string{"context length", "context_length", "maximum context", "context window", "too many tokens"} {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// wrapProviderError classifies err and attaches the provider name and any
+// Retry-After hint sent by the server.
+func wrapProviderError(provider string, err error) *ProviderError {
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		if providerErr.Provider == "" {
+			providerErr.Provider = provider
+		}
+		return providerErr
+	}
+
+	wrapped := &ProviderError{Provider: provider, Kind: ClassifyError(err), Err: err}
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		wrapped.StatusCode = apiErr.StatusCode
+		if apiErr.Response != nil {
+			wrapped.RetryAfter = parseRetryAfter(apiErr.Response.Header.Get("Retry-After"))
+		}
+	}
+	return wrapped
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
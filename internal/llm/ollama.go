@@ -0,0 +1,323 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"agent-coach/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const defaultOllamaBaseURL = "http://localhost:11434"
+
+type OllamaProvider struct {
+	config  *models.LLMProviderConfig
+	client  *http.Client
+	baseURL string
+}
+
+func NewOllamaProvider(config *models.LLMProviderConfig) Provider {
+	baseURL := strings.TrimRight(config.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultOllamaBaseURL
+	}
+	return &OllamaProvider{config: config, client: http.DefaultClient, baseURL: baseURL}
+}
+
+func (p *OllamaProvider) Name() string {
+	return "ollama"
+}
+
+func (p *OllamaProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	chatReq := p.buildRequest(req)
+	httpResp, err := p.post(ctx, chatReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	var chatResp ollamaChatResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&chatResp); err != nil {
+		return nil, fmt.Errorf("failed to decode ollama response: %w", err)
+	}
+	if chatResp.Error != "" {
+		return nil, ollamaResponseError(chatResp.Error)
+	}
+	return chatResp.toCompletionResponse(), nil
+}
+
+// Stream reads Ollama's newline-delimited JSON stream. Content arrives as
+// deltas while tool calls arrive whole, so each call is reported as a single
+// complete ToolCallDelta.
+func (p *OllamaProvider) Stream(ctx context.Context, req *CompletionRequest, onChunk StreamHandler) (*CompletionResponse, error) {
+	chatReq := p.buildRequest(req)
+	chatReq.Stream = true
+	httpResp, err := p.post(ctx, chatReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	var content strings.Builder
+	var toolCalls []ollamaToolCall
+	var final ollamaChatResponse
+
+	decoder := json.NewDecoder(httpResp.Body)
+	for {
+		var chunk ollamaChatResponse
+		if err := decoder.Decode(&chunk); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to decode ollama stream: %w", err)
+		}
+		if chunk.Error != "" {
+			return nil, ollamaResponseError(chunk.Error)
+		}
+
+		if chunk.Message.Content != "" {
+			content.WriteString(chunk.Message.Content)
+			onChunk(StreamChunk{Content: chunk.Message.Content})
+		}
+		for _, toolCall := range chunk.Message.ToolCalls {
+			arguments, _ := json.Marshal(toolCall.Function.Arguments)
+			onChunk(StreamChunk{ToolCall: &ToolCallDelta{
+				Index:     len(toolCalls),
+				Name:      toolCall.Function.Name,
+				Arguments: string(arguments),
+			}})
+			toolCalls = append(toolCalls, toolCall)
+		}
+		if chunk.Done {
+			final = chunk
+			break
+		}
+	}
+
+	final.Message = ollamaMessage{
+		Role:      string(RoleAssistant),
+		Content:   content.String(),
+		ToolCalls: toolCalls,
+	}
+	return final.toCompletionResponse(), nil
+}
+
+func (p *OllamaProvider) post(ctx context.Context, chatReq *ollamaChatRequest) (*http.Response, error) {
+	body, err := json.Marshal(chatReq)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/api/chat", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.config.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	}
+
+	httpResp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		defer httpResp.Body.Close()
+		return nil, parseOllamaError(httpResp)
+	}
+	return httpResp, nil
+}
+
+func (p *OllamaProvider) IsAvailable() bool {
+	return p.config.IsActive
+}
+
+func (p *OllamaProvider) Type() ProviderType {
+	return ProviderTypeOllama
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+type ollamaToolCall struct {
+	Function ollamaToolFunction `json:"function"`
+}
+
+type ollamaToolFunction struct {
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+type ollamaTool struct {
+	Type     string                `json:"type"`
+	Function ollamaToolFunctionDef `json:"function"`
+}
+
+type ollamaToolFunctionDef struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Parameters  map[string]any `json:"parameters"`
+}
+
+type ollamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Tools    []ollamaTool    `json:"tools,omitempty"`
+	Stream   bool            `json:"stream"`
+	Options  *ollamaOptions  `json:"options,omitempty"`
+}
+
+type ollamaOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	NumPredict  *int     `json:"num_predict,omitempty"`
+}
+
+type ollamaChatResponse struct {
+	Model           string        `json:"model"`
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+	// Error is set instead of the other fields when generation fails after
+	// the response has started.
+	Error string `json:"error,omitempty"`
+}
+
+func (p *OllamaProvider) buildRequest(req *CompletionRequest) *ollamaChatRequest {
+	messages := make([]ollamaMessage, 0, len(req.Messages)+1)
+	if req.SystemPrompt != "" {
+		messages = append(messages, ollamaMessage{Role: string(RoleSystem), Content: req.SystemPrompt})
+	}
+	// Ollama matches tool results to calls by tool name rather than by ID.
+	toolNames := make(map[string]string)
+	for _, message := range req.Messages {
+		switch message.Role {
+		case RoleUser:
+			messages = append(messages, ollamaMessage{Role: string(message.Role), Content: message.Content})
+		case RoleTool:
+			messages = append(messages, ollamaMessage{
+				Role:     string(message.Role),
+				Content:  message.Content,
+				ToolName: toolNames[message.ToolCallID],
+			})
+		case RoleAssistant:
+			assistant := ollamaMessage{Role: string(message.Role), Content: message.Content}
+			for _, toolCall := range message.ToolCalls {
+				toolNames[toolCall.ID] = toolCall.Function.Name
+				assistant.ToolCalls = append(assistant.ToolCalls, ollamaToolCall{Function: ollamaToolFunction{
+					Name:      toolCall.Function.Name,
+					Arguments: toolCall.Function.Arguments,
+				}})
+			}
+			messages = append(messages, assistant)
+		default:
+			continue
+		}
+	}
+
+	tools := make([]ollamaTool, 0, len(req.Tools))
+	for _, tool := range req.Tools {
+		tools = append(tools, ollamaTool{
+			Type: "function",
+			Function: ollamaToolFunctionDef{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.JSONSchema(),
+			},
+		})
+	}
+
+	model := req.Model
+	if model == "" {
+		model = p.config.DefaultModel
+	}
+
+	chatReq := &ollamaChatRequest{
+		Model:    model,
+		Messages: messages,
+		Tools:    tools,
+		Stream:   false,
+	}
+	if req.Temperature != nil || req.MaxTokens != nil {
+		chatReq.Options = &ollamaOptions{Temperature: req.Temperature, NumPredict: req.MaxTokens}
+	}
+	return chatReq
+}
+
+// Ollama does not assign IDs to tool calls, so one is generated per call to
+// let tool results be correlated on the way back.
+func (r *ollamaChatResponse) toCompletionResponse() *CompletionResponse {
+	toolCalls := make([]models.ToolCall, 0, len(r.Message.ToolCalls))
+	for _, toolCall := range r.Message.ToolCalls {
+		toolCalls = append(toolCalls, models.ToolCall{
+			ID:   "call_" + uuid.New().String(),
+			Type: "function",
+			Function: models.ToolFunction{
+				Name:      toolCall.Function.Name,
+				Arguments: toolCall.Function.Arguments,
+			},
+		})
+	}
+
+	finishReason := r.DoneReason
+	if len(toolCalls) > 0 {
+		finishReason = "tool_calls"
+	}
+
+	return &CompletionResponse{
+		Content:   r.Message.Content,
+		ToolCalls: toolCalls,
+		Model:     r.Model,
+		Usage: Usage{
+			PromptTokens:     r.PromptEvalCount,
+			CompletionTokens: r.EvalCount,
+			TotalTokens:      r.PromptEvalCount + r.EvalCount,
+		},
+		FinishReason: finishReason,
+	}
+}
+
+func parseOllamaError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	message := strings.TrimSpace(string(body))
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.Error != "" {
+		message = apiErr.Error
+	}
+	return &ProviderError{
+		Provider:   "ollama",
+		Kind:       classifyStatus(resp.StatusCode, message),
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		Err:        fmt.Errorf("status %d: %s", resp.StatusCode, message),
+	}
+}
+
+// ollamaResponseError reports an error Ollama sent in place of a response
+// body or stream chunk, after answering 200. The request itself was accepted,
+// so the failure is treated as transient unless the message says the context
+// was too long.
+func ollamaResponseError(message string) error {
+	kind := ErrorKindTransient
+	if isContextLengthMessage(message) {
+		kind = ErrorKindContextLength
+	}
+	return &ProviderError{
+		Provider: "ollama",
+		Kind:     kind,
+		Err:      errors.New(message),
+	}
+}
@@ -0,0 +1,226 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"agent-coach/internal/models"
+)
+
+func newTestOllama(t *testing.T, handler http.HandlerFunc) Provider {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewOllamaProvider(&models.LLMProviderConfig{BaseURL: server.URL, DefaultModel: "llama3"})
+}
+
+func TestOllamaCompleteParsesToolCalls(t *testing.T) {
+	provider := newTestOllama(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("path = %s, want /api/chat", r.URL.Path)
+		}
+		io.WriteString(w, `{
+			"model": "llama3",
+			"message": {"role": "assistant", "content": "", "tool_calls": [
+				{"function": {"name": "create_task", "arguments": {"title": "Read chapter 1", "estimated_minutes": 30}}}
+			]},
+			"done": true,
+			"done_reason": "stop",
+			"prompt_eval_count": 120,
+			"eval_count": 15
+		}`)
+	})
+
+	resp, err := provider.Complete(context.Background(), &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "Plan my week"}}})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if len(resp.ToolCalls) != 1 {
+		t.Fatalf("tool calls = %+v, want one", resp.ToolCalls)
+	}
+	call := resp.ToolCalls[0]
+	if !strings.HasPrefix(call.ID, "call_") || call.Type != "function" || call.Function.Name != "create_task" {
+		t.Errorf("tool call = %+v", call)
+	}
+	wantArgs := map[string]any{"title": "Read chapter 1", "estimated_minutes": 30.0}
+	if !reflect.DeepEqual(call.Function.Arguments, wantArgs) {
+		t.Errorf("arguments = %v, want %v", call.Function.Arguments, wantArgs)
+	}
+	wantUsage := Usage{PromptTokens: 120, CompletionTokens: 15, TotalTokens: 135}
+	if resp.FinishReason != "tool_calls" || resp.Model != "llama3" || resp.Usage != wantUsage {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestOllamaStreamAssemblesChunks(t *testing.T) {
+	var body any
+	provider := newTestOllama(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = decodeJSON(t, string(data))
+		io.WriteString(w, strings.Join([]string{
+			`{"model": "llama3", "message": {"role": "assistant", "content": "Let me "}, "done": false}`,
+			`{"model": "llama3", "message": {"role": "assistant", "content": "check."}, "done": false}`,
+			`{"model": "llama3", "message": {"role": "assistant", "content": "", "tool_calls": [{"function": {"name": "get_progress", "arguments": {"days": 7}}}]}, "done": false}`,
+			`{"model": "llama3", "message": {"role": "assistant", "content": ""}, "done": true, "done_reason": "stop", "prompt_eval_count": 200, "eval_count": 12}`,
+			`{"model": "llama3", "message": {"role": "assistant", "content": "ignored"}, "done": false}`,
+		}, "\n"))
+	})
+
+	var chunks []StreamChunk
+	resp, err := provider.Stream(context.Background(), &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "How am I doing?"}}}, func(chunk StreamChunk) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if stream, _ := body.(map[string]any)["stream"].(bool); !stream {
+		t.Errorf("request body = %v, want stream true", body)
+	}
+
+	wantChunks := []StreamChunk{
+		{Content: "Let me "},
+		{Content: "check."},
+		{ToolCall: &ToolCallDelta{Index: 0, Name: "get_progress", Arguments: `{"days":7}`}},
+	}
+	if !reflect.DeepEqual(chunks, wantChunks) {
+		t.Errorf("chunks = %+v, want %+v", chunks, wantChunks)
+	}
+
+	if resp.Content != "Let me check." || len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Function.Name != "get_progress" {
+		t.Errorf("response = %+v", resp)
+	}
+	wantUsage := Usage{PromptTokens: 200, CompletionTokens: 12, TotalTokens: 212}
+	if resp.FinishReason != "tool_calls" || resp.Usage != wantUsage {
+		t.Errorf("finish reason = %q, usage = %+v; want tool_calls and %+v from the done chunk", resp.FinishReason, resp.Usage, wantUsage)
+	}
+}
+
+func TestOllamaSendsToolCallsWithAssistantMessage(t *testing.T) {
+	var body any
+	server := captureServer(t, &body, `{
+		"model": "llama3",
+		"message": {"role": "assistant", "content": "Done"},
+		"done": true,
+		"done_reason": "stop"
+	}`)
+
+	provider := NewOllamaProvider(&models.LLMProviderConfig{BaseURL: server.URL, DefaultModel: "llama3"})
+	if _, err := provider.Complete(context.Background(), toolTurnRequest()); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	want := decodeJSON(t, `{
+		"model": "llama3",
+		"stream": false,
+		"messages": [
+			{"role": "system", "content": "You are a coach."},
+			{"role": "user", "content": "Plan my week"},
+			{"role": "assistant", "content": "", "tool_calls": [
+				{"function": {"name": "create_task", "arguments": {"title": "Read chapter 1"}}}
+			]},
+			{"role": "tool", "content": "{\"task_id\":\"t1\"}", "tool_name": "create_task"}
+		],
+		"tools": [
+			{"type": "function", "function": {
+				"name": "create_task",
+				"description": "Create a task",
+				"parameters": {
+					"type": "object",
+					"properties": {"title": {"type": "string", "description": "Task title"}},
+					"required": ["title"]
+				}
+			}}
+		]
+	}`)
+	if !reflect.DeepEqual(body, want) {
+		got, _ := json.MarshalIndent(body, "", "  ")
+		t.Errorf("request payload:\n%s", got)
+	}
+}
+
+func TestOllamaStreamReturnsMidStreamErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		error   string
+		kind    ErrorKind
+		message string
+	}{
+		{"runner crash", `{"error": "llama runner process has terminated"}`, ErrorKindTransient, "runner process has terminated"},
+		{"context too long", `{"error": "input exceeds context length"}`, ErrorKindContextLength, "exceeds context length"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newTestOllama(t, func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, strings.Join([]string{
+					`{"model": "llama3", "message": {"role": "assistant", "content": "Let me "}, "done": false}`,
+					tt.error,
+				}, "\n"))
+			})
+
+			var chunks []StreamChunk
+			resp, err := provider.Stream(context.Background(), &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "hi"}}}, func(chunk StreamChunk) {
+				chunks = append(chunks, chunk)
+			})
+			var providerErr *ProviderError
+			if !errors.As(err, &providerErr) {
+				t.Fatalf("Stream = %+v, %v; want ProviderError", resp, err)
+			}
+			if providerErr.Provider != "ollama" || providerErr.Kind != tt.kind || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error = %+v, want kind %s containing %q", providerErr, tt.kind, tt.message)
+			}
+			if len(chunks) != 1 || chunks[0].Content != "Let me " {
+				t.Errorf("chunks = %+v, want the content before the error", chunks)
+			}
+		})
+	}
+}
+
+func TestParseOllamaError(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		body       string
+		kind       ErrorKind
+		wait       time.Duration
+		message    string
+	}{
+		{"rate limited", http.StatusTooManyRequests, "7", `{"error": "slow down"}`, ErrorKindRateLimit, 7 * time.Second, "slow down"},
+		{"missing model", http.StatusNotFound, "", `{"error": "model \"llama9\" not found"}`, ErrorKindInvalidRequest, 0, `model "llama9" not found`},
+		{"context too long", http.StatusBadRequest, "", `{"error": "input exceeds context length"}`, ErrorKindContextLength, 0, "exceeds context length"},
+		{"plain text", http.StatusBadGateway, "", "upstream down\n", ErrorKindTransient, 0, "status 502: upstream down"},
+		{"unauthorized", http.StatusUnauthorized, "", `{"error": "bad key"}`, ErrorKindAuth, 0, "bad key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newTestOllama(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			})
+
+			_, err := provider.Complete(context.Background(), &CompletionRequest{})
+			var providerErr *ProviderError
+			if !errors.As(err, &providerErr) {
+				t.Fatalf("error = %v, want ProviderError", err)
+			}
+			if providerErr.Provider != "ollama" || providerErr.Kind != tt.kind || providerErr.StatusCode != tt.status || providerErr.RetryAfter != tt.wait {
+				t.Errorf("error = %+v, want kind %s, status %d, retry after %v", providerErr, tt.kind, tt.status, tt.wait)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("message = %q, want it to contain %q", err.Error(), tt.message)
+			}
+		})
+	}
+}
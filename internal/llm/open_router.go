@@ -3,6 +3,7 @@ package llm
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
 	"agent-coach/internal/models"
 
@@ -20,6 +21,8 @@ func NewOpenRouterProvider(config *models.LLMProviderConfig) Provider {
 	client := openai.NewClient(
 		option.WithAPIKey(config.APIKey),
 		option.WithBaseURL(config.BaseURL),
+		// Retries are handled by the Router so they can fail over.
+		option.WithMaxRetries(0),
 	)
 	return &OpenRouterProvider{config: config, client: client}
 }
@@ -41,6 +44,42 @@ func (p *OpenRouterProvider) Complete(ctx context.Context, req *CompletionReques
 	return response, nil
 }
 
+func (p *OpenRouterProvider) Stream(ctx context.Context, req *CompletionRequest, onChunk StreamHandler) (*CompletionResponse, error) {
+	params := p.buildParams(req)
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+
+	stream := p.client.Chat.Completions.NewStreaming(ctx, *params)
+	defer stream.Close()
+
+	acc := openai.ChatCompletionAccumulator{}
+	for stream.Next() {
+		chunk := stream.Current()
+		acc.AddChunk(chunk)
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		delta := chunk.Choices[0].Delta
+		if delta.Content != "" {
+			onChunk(StreamChunk{Content: delta.Content})
+		}
+		for _, toolCall := range delta.ToolCalls {
+			onChunk(StreamChunk{ToolCall: &ToolCallDelta{
+				Index:     int(toolCall.Index),
+				ID:        toolCall.ID,
+				Name:      toolCall.Function.Name,
+				Arguments: toolCall.Function.Arguments,
+			}})
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+	if len(acc.Choices) == 0 {
+		return nil, fmt.Errorf("stream ended without any choices")
+	}
+	return parseResponse(&acc.ChatCompletion)
+}
+
 func (p *OpenRouterProvider) IsAvailable() bool {
 	return p.config.IsActive
 }
@@ -50,14 +89,16 @@ func (p *OpenRouterProvider) Type() ProviderType {
 }
 
 func (p *OpenRouterProvider) buildParams(req *CompletionRequest) *openai.ChatCompletionNewParams {
-	messages := make([]openai.ChatCompletionMessageParamUnion, len(req.Messages)+1)
-	messages[0] = openai.SystemMessage(req.SystemPrompt)
+	messages := make([]openai.ChatCompletionMessageParamUnion, 0, len(req.Messages)+1)
+	if req.SystemPrompt != "" {
+		messages = append(messages, openai.SystemMessage(req.SystemPrompt))
+	}
 	for _, message := range req.Messages {
 		switch message.Role {
 		case RoleUser:
 			messages = append(messages, openai.UserMessage(message.Content))
 		case RoleAssistant:
-			messages = append(messages, openai.AssistantMessage(message.Content))
+			messages = append(messages, buildAssistantMessage(message))
 		case RoleTool:
 			messages = append(messages, openai.ToolMessage(message.Content, message.ToolCallID))
 		default:
@@ -65,10 +106,10 @@ func (p *OpenRouterProvider) buildParams(req *CompletionRequest) *openai.ChatCom
 		}
 	}
 
-	tools := make([]openai.ChatCompletionToolUnionParam, len(req.Tools))
+	tools := make([]openai.ChatCompletionToolUnionParam, 0, len(req.Tools))
 	for _, tool := range req.Tools {
 		switch tool.Type {
-		case "function":
+		case "", "function":
 			tools = append(tools, buildFunctionTool(tool))
 		default:
 			continue
@@ -81,62 +122,87 @@ func (p *OpenRouterProvider) buildParams(req *CompletionRequest) *openai.ChatCom
 	}
 	params := openai.ChatCompletionNewParams{
 		Messages: messages,
-		Tools:    tools,
 		Model:    openai.ChatModel(model),
 	}
+	// An empty tools array is rejected by some providers.
+	if len(tools) > 0 {
+		params.Tools = tools
+	}
+	if req.Temperature != nil {
+		params.Temperature = openai.Float(*req.Temperature)
+	}
+	if req.MaxTokens != nil {
+		params.MaxTokens = openai.Int(int64(*req.MaxTokens))
+	}
 
 	return &params
 
 }
 
-func buildFunctionTool(tool models.Tool) openai.ChatCompletionToolUnionParam {
-	required := make([]string, 0)
-	properties := make(map[string]any)
-	for name, param := range tool.Parameters {
-		properties[name] = map[string]any{
-			"type":        param.Type,
-			"description": param.Description,
-			"enum":        param.Enum,
-		}
-		if param.Required {
-			required = append(required, name)
-		}
+// buildAssistantMessage keeps the tool calls of an assistant message, which
+// the API requires before the tool messages that answer them.
+func buildAssistantMessage(message Message) openai.ChatCompletionMessageParamUnion {
+	if len(message.ToolCalls) == 0 {
+		return openai.AssistantMessage(message.Content)
 	}
-	parameters := openai.FunctionParameters{
-		"type":       "object",
-		"properties": properties,
-		"required":   required,
+
+	assistant := openai.ChatCompletionAssistantMessageParam{}
+	if message.Content != "" {
+		assistant.Content.OfString = openai.String(message.Content)
+	}
+	for _, toolCall := range message.ToolCalls {
+		arguments, err := json.Marshal(toolCall.Function.Arguments)
+		if err != nil || toolCall.Function.Arguments == nil {
+			arguments = []byte("{}")
+		}
+		assistant.ToolCalls = append(assistant.ToolCalls, openai.ChatCompletionMessageToolCallUnionParam{
+			OfFunction: &openai.ChatCompletionMessageFunctionToolCallParam{
+				ID: toolCall.ID,
+				Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{
+					Name:      toolCall.Function.Name,
+					Arguments: string(arguments),
+				},
+			},
+		})
 	}
+	return openai.ChatCompletionMessageParamUnion{OfAssistant: &assistant}
+}
+
+func buildFunctionTool(tool models.Tool) openai.ChatCompletionToolUnionParam {
 	return openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
 		Name:        tool.Name,
 		Description: openai.String(tool.Description),
-		Parameters:  parameters,
+		Parameters:  openai.FunctionParameters(tool.JSONSchema()),
 	})
 }
 
 func parseResponse(completion *openai.ChatCompletion) (*CompletionResponse, error) {
-	toolCalls := make([]models.ToolCall, len(completion.Choices[0].Message.ToolCalls))
-	for i, toolCall := range completion.Choices[0].Message.ToolCalls {
-		var arguments map[string]any
-		err := json.Unmarshal([]byte(toolCall.Function.Arguments), &arguments)
-		if err != nil {
-			log.Errorf("failed to unmarshal tool call arguments: %v", err)
-			continue
-		}
-		toolCalls[i] = models.ToolCall{
-			ID:   toolCall.ID,
-			Type: toolCall.Type,
-			Function: models.ToolFunction{
-				Name:      toolCall.Function.Name,
-				Arguments: arguments,
-			},
+	if len(completion.Choices) == 0 {
+		return nil, fmt.Errorf("completion has no choices")
+	}
+	toolCalls := make([]models.ToolCall, 0, len(completion.Choices[0].Message.ToolCalls))
+	for _, toolCall := range completion.Choices[0].Message.ToolCalls {
+		function := models.ToolFunction{Name: toolCall.Function.Name}
+		if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &function.Arguments); err != nil {
+			log.Errorf("failed to unmarshal arguments of tool call %s: %v", toolCall.ID, err)
+			function.Arguments = nil
+			function.ArgumentsError = fmt.Sprintf("not valid JSON: %v", err)
 		}
+		toolCalls = append(toolCalls, models.ToolCall{
+			ID:       toolCall.ID,
+			Type:     toolCall.Type,
+			Function: function,
+		})
 	}
 	return &CompletionResponse{
-		Content:      completion.Choices[0].Message.Content,
-		ToolCalls:    toolCalls,
-		Model:        completion.Model,
-		Usage:        int(completion.Usage.TotalTokens),
+		Content:   completion.Choices[0].Message.Content,
+		ToolCalls: toolCalls,
+		Model:     completion.Model,
+		Usage: Usage{
+			PromptTokens:     int(completion.Usage.PromptTokens),
+			CompletionTokens: int(completion.Usage.CompletionTokens),
+			TotalTokens:      int(completion.Usage.TotalTokens),
+		},
 		FinishReason: completion.Choices[0].FinishReason,
 	}, nil
 }
@@ -0,0 +1,153 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"agent-coach/internal/models"
+)
+
+// captureServer answers every request with response and stores the decoded
+// request body in *body.
+func captureServer(t *testing.T, body *any, response string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read request body: %v", err)
+		}
+		if err := json.Unmarshal(data, body); err != nil {
+			t.Errorf("request body is not JSON: %v\n%s", err, data)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func decodeJSON(t *testing.T, data string) any {
+	t.Helper()
+
+	var v any
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatalf("invalid JSON literal: %v", err)
+	}
+	return v
+}
+
+// toolTurnRequest is a request continuing a turn in which the assistant
+// called a tool, used by the payload tests of every provider.
+func toolTurnRequest() *CompletionRequest {
+	return &CompletionRequest{
+		SystemPrompt: "You are a coach.",
+		Messages: []Message{
+			{Role: RoleUser, Content: "Plan my week"},
+			{Role: RoleAssistant, ToolCalls: []models.ToolCall{{
+				ID:   "call_1",
+				Type: "function",
+				Function: models.ToolFunction{
+					Name:      "create_task",
+					Arguments: map[string]any{"title": "Read chapter 1"},
+				},
+			}}},
+			{Role: RoleTool, Content: `{"task_id":"t1"}`, ToolCallID: "call_1"},
+		},
+		Tools: []models.Tool{{
+			Name:        "create_task",
+			Description: "Create a task",
+			Parameters: map[string]models.ToolParam{
+				"title": {Type: "string", Description: "Task title", Required: true},
+			},
+		}},
+	}
+}
+
+func TestOpenRouterSendsToolCallsWithAssistantMessage(t *testing.T) {
+	var body any
+	server := captureServer(t, &body, `{
+		"id": "gen-1",
+		"object": "chat.completion",
+		"model": "test-model",
+		"choices": [{"index": 0, "finish_reason": "stop", "message": {"role": "assistant", "content": "Done"}}],
+		"usage": {"prompt_tokens": 10, "completion_tokens": 2, "total_tokens": 12}
+	}`)
+
+	provider := NewOpenRouterProvider(&models.LLMProviderConfig{BaseURL: server.URL, APIKey: "key", DefaultModel: "test-model"})
+	resp, err := provider.Complete(context.Background(), toolTurnRequest())
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if resp.Content != "Done" {
+		t.Errorf("content = %q, want Done", resp.Content)
+	}
+
+	want := decodeJSON(t, `{
+		"model": "test-model",
+		"messages": [
+			{"role": "system", "content": "You are a coach."},
+			{"role": "user", "content": "Plan my week"},
+			{"role": "assistant", "tool_calls": [
+				{"id": "call_1", "type": "function", "function": {"name": "create_task", "arguments": "{\"title\":\"Read chapter 1\"}"}}
+			]},
+			{"role": "tool", "content": "{\"task_id\":\"t1\"}", "tool_call_id": "call_1"}
+		],
+		"tools": [
+			{"type": "function", "function": {
+				"name": "create_task",
+				"description": "Create a task",
+				"parameters": {
+					"type": "object",
+					"properties": {"title": {"type": "string", "description": "Task title"}},
+					"required": ["title"]
+				}
+			}}
+		]
+	}`)
+	if !reflect.DeepEqual(body, want) {
+		got, _ := json.MarshalIndent(body, "", "  ")
+		t.Errorf("request payload:\n%s", got)
+	}
+}
+
+func TestOpenRouterKeepsMalformedToolCalls(t *testing.T) {
+	var body any
+	server := captureServer(t, &body, `{
+		"id": "gen-2",
+		"object": "chat.completion",
+		"model": "test-model",
+		"choices": [{"index": 0, "finish_reason": "tool_calls", "message": {"role": "assistant", "content": "", "tool_calls": [
+			{"id": "call_bad", "type": "function", "function": {"name": "create_task", "arguments": "{not json"}},
+			{"id": "call_ok", "type": "function", "function": {"name": "create_task", "arguments": "{\"title\":\"Practice\"}"}}
+		]}}]
+	}`)
+
+	provider := NewOpenRouterProvider(&models.LLMProviderConfig{BaseURL: server.URL, APIKey: "key", DefaultModel: "test-model"})
+	resp, err := provider.Complete(context.Background(), &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if len(resp.ToolCalls) != 2 {
+		t.Fatalf("tool calls = %+v, want both calls", resp.ToolCalls)
+	}
+	bad, ok := resp.ToolCalls[0], resp.ToolCalls[1]
+	if bad.ID != "call_bad" || bad.Function.Name != "create_task" || bad.Function.Arguments != nil || !strings.Contains(bad.Function.ArgumentsError, "not valid JSON") {
+		t.Errorf("malformed call = %+v, want it kept with the parse error", bad)
+	}
+	if ok.ID != "call_ok" || ok.Function.Arguments["title"] != "Practice" || ok.Function.ArgumentsError != "" {
+		t.Errorf("valid call = %+v", ok)
+	}
+
+	want := decodeJSON(t, `{"model": "test-model", "messages": [{"role": "user", "content": "hi"}]}`)
+	if !reflect.DeepEqual(body, want) {
+		got, _ := json.MarshalIndent(body, "", "  ")
+		t.Errorf("request payload:\n%s", got)
+	}
+}
@@ -14,10 +14,14 @@ const (
 	RoleTool      Role = "tool"
 )
 
+// Message is one entry of the conversation sent to a provider. An assistant
+// message that requested tools carries ToolCalls, and each following tool
+// message answers one of them through ToolCallID.
 type Message struct {
-	Role       Role   `json:"role"`
-	Content    string `json:"content"`
-	ToolCallID string `json:"tool_call_id"`
+	Role       Role              `json:"role"`
+	Content    string            `json:"content"`
+	ToolCalls  []models.ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string            `json:"tool_call_id"`
 }
 
 type CompletionRequest struct {
@@ -25,31 +29,70 @@ type CompletionRequest struct {
 	Messages     []Message     `json:"messages"`
 	SystemPrompt string        `json:"system_prompt"`
 	Tools        []models.Tool `json:"tools"`
+	Temperature  *float64      `json:"temperature,omitempty"`
+	MaxTokens    *int          `json:"max_tokens,omitempty"`
+	// AgentType identifies the caller so the Router can apply its configured
+	// provider and model. It is recorded in the usage ledger along with
+	// GoalID and Intent.
+	AgentType models.AgentType `json:"agent_type,omitempty"`
+	GoalID    string           `json:"goal_id,omitempty"`
+	Intent    string           `json:"intent,omitempty"`
 }
 
 type CompletionResponse struct {
 	Content      string            `json:"content"`
 	ToolCalls    []models.ToolCall `json:"tool_calls"`
 	Model        string            `json:"model"`
-	Usage        int               `json:"usage"`
+	Usage        Usage             `json:"usage"`
 	FinishReason string            `json:"finish_reason"`
 }
 
+// StreamChunk is an incremental piece of a streamed completion. Exactly one of
+// Content or ToolCall is set.
+type StreamChunk struct {
+	Content  string         `json:"content,omitempty"`
+	ToolCall *ToolCallDelta `json:"tool_call,omitempty"`
+}
+
+// ToolCallDelta is a fragment of a tool call. Fragments sharing an Index belong
+// to the same call; Arguments are partial JSON to be concatenated in order.
+type ToolCallDelta struct {
+	Index     int    `json:"index"`
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+}
+
+type StreamHandler func(chunk StreamChunk)
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
 type ProviderType string
 
 const (
 	ProviderTypeOpenRouter ProviderType = "openrouter"
 	ProviderTypeOllama     ProviderType = "ollama"
+	// ProviderTypeMock replays scripted fixtures, or records them from
+	// another provider when LLMProviderConfig.RecordFrom is set.
+	ProviderTypeMock ProviderType = "mock"
 )
 
 var availableProviderTypes = []ProviderType{
 	ProviderTypeOpenRouter,
 	ProviderTypeOllama,
+	ProviderTypeMock,
 }
 
 type Provider interface {
 	Name() string
 	Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error)
+	// Stream behaves like Complete but reports deltas to onChunk as they arrive.
+	// The returned response is the fully accumulated completion.
+	Stream(ctx context.Context, req *CompletionRequest, onChunk StreamHandler) (*CompletionResponse, error)
 	IsAvailable() bool
 	Type() ProviderType
 }
@@ -0,0 +1,211 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"agent-coach/internal/models"
+
+	"github.com/google/martian/log"
+)
+
+// Fixture is a scripted LLM session: the responses a ReplayProvider serves, or
+// the calls a RecordingProvider captured.
+type Fixture struct {
+	Interactions []FixtureInteraction `json:"interactions"`
+}
+
+type FixtureInteraction struct {
+	// AgentType restricts the interaction to requests made by that agent.
+	// Empty matches any caller.
+	AgentType models.AgentType `json:"agent_type,omitempty"`
+	// Request is written when recording to make fixtures readable; it is
+	// ignored when replaying.
+	Request  *FixtureRequest    `json:"request,omitempty"`
+	Response CompletionResponse `json:"response"`
+}
+
+type FixtureRequest struct {
+	Model       string   `json:"model,omitempty"`
+	LastMessage string   `json:"last_message,omitempty"`
+	Tools       []string `json:"tools,omitempty"`
+}
+
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+	return &fixture, nil
+}
+
+func (f *Fixture) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// ReplayProvider serves scripted responses in order, making agent behaviour
+// deterministic for tests. Each request consumes the first unused interaction
+// whose AgentType matches the caller.
+type ReplayProvider struct {
+	config  *models.LLMProviderConfig
+	fixture *Fixture
+	used    []bool
+	mu      sync.Mutex
+}
+
+func NewReplayProvider(config *models.LLMProviderConfig) (Provider, error) {
+	fixture, err := LoadFixture(config.FixturePath)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayProviderFromFixture(config, fixture), nil
+}
+
+func NewReplayProviderFromFixture(config *models.LLMProviderConfig, fixture *Fixture) Provider {
+	return &ReplayProvider{
+		config:  config,
+		fixture: fixture,
+		used:    make([]bool, len(fixture.Interactions)),
+	}
+}
+
+func (p *ReplayProvider) Name() string {
+	return "mock"
+}
+
+func (p *ReplayProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, interaction := range p.fixture.Interactions {
+		if p.used[i] {
+			continue
+		}
+		if interaction.AgentType != "" && interaction.AgentType != req.AgentType {
+			continue
+		}
+		p.used[i] = true
+		resp := interaction.Response
+		if resp.Model == "" {
+			resp.Model = p.config.DefaultModel
+		}
+		return &resp, nil
+	}
+
+	return nil, &ProviderError{
+		Provider: p.config.Name,
+		Kind:     ErrorKindInvalidRequest,
+		Err:      fmt.Errorf("fixture has no remaining response for agent %q", req.AgentType),
+	}
+}
+
+// Stream replays the scripted response word by word, followed by one delta per
+// tool call.
+func (p *ReplayProvider) Stream(ctx context.Context, req *CompletionRequest, onChunk StreamHandler) (*CompletionResponse, error) {
+	resp, err := p.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, word := range strings.SplitAfter(resp.Content, " ") {
+		if word != "" {
+			onChunk(StreamChunk{Content: word})
+		}
+	}
+	for i, toolCall := range resp.ToolCalls {
+		arguments, _ := json.Marshal(toolCall.Function.Arguments)
+		onChunk(StreamChunk{ToolCall: &ToolCallDelta{
+			Index:     i,
+			ID:        toolCall.ID,
+			Name:      toolCall.Function.Name,
+			Arguments: string(arguments),
+		}})
+	}
+	return resp, nil
+}
+
+func (p *ReplayProvider) IsAvailable() bool {
+	return p.config.IsActive
+}
+
+func (p *ReplayProvider) Type() ProviderType {
+	return ProviderTypeMock
+}
+
+// RecordingProvider forwards calls to a real provider and appends each
+// exchange to a fixture file, producing fixtures a ReplayProvider can serve.
+type RecordingProvider struct {
+	config  *models.LLMProviderConfig
+	inner   Provider
+	fixture Fixture
+	mu      sync.Mutex
+}
+
+func NewRecordingProvider(config *models.LLMProviderConfig, inner Provider) Provider {
+	return &RecordingProvider{config: config, inner: inner}
+}
+
+func (p *RecordingProvider) Name() string {
+	return "mock"
+}
+
+func (p *RecordingProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	resp, err := p.inner.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	p.record(req, resp)
+	return resp, nil
+}
+
+func (p *RecordingProvider) Stream(ctx context.Context, req *CompletionRequest, onChunk StreamHandler) (*CompletionResponse, error) {
+	resp, err := p.inner.Stream(ctx, req, onChunk)
+	if err != nil {
+		return nil, err
+	}
+	p.record(req, resp)
+	return resp, nil
+}
+
+func (p *RecordingProvider) IsAvailable() bool {
+	return p.config.IsActive && p.inner.IsAvailable()
+}
+
+func (p *RecordingProvider) Type() ProviderType {
+	return ProviderTypeMock
+}
+
+// record appends the exchange and rewrites the whole fixture so a session that
+// is interrupted still leaves a usable file behind.
+func (p *RecordingProvider) record(req *CompletionRequest, resp *CompletionResponse) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	recorded := &FixtureRequest{Model: req.Model}
+	if len(req.Messages) > 0 {
+		recorded.LastMessage = req.Messages[len(req.Messages)-1].Content
+	}
+	for _, tool := range req.Tools {
+		recorded.Tools = append(recorded.Tools, tool.Name)
+	}
+
+	p.fixture.Interactions = append(p.fixture.Interactions, FixtureInteraction{
+		AgentType: req.AgentType,
+		Request:   recorded,
+		Response:  *resp,
+	})
+	if err := p.fixture.Save(p.config.FixturePath); err != nil {
+		log.Errorf("failed to write fixture %s: %v", p.config.FixturePath, err)
+	}
+}
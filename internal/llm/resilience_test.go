@@ -0,0 +1,333 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"agent-coach/internal/storage"
+
+	"github.com/openai/openai-go/v3"
+)
+
+// fakeProvider fails with its scripted errors in order and then succeeds,
+// answering with resp or, without one, its own name. It keeps the requests
+// it was sent.
+type fakeProvider struct {
+	name     string
+	errs     []error
+	calls    int
+	stream   bool // emit a chunk before failing a streamed call
+	resp     *CompletionResponse
+	delay    time.Duration
+	requests []*CompletionRequest
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	p.calls++
+	p.requests = append(p.requests, req)
+	time.Sleep(p.delay)
+	if len(p.errs) > 0 {
+		err := p.errs[0]
+		p.errs = p.errs[1:]
+		return nil, err
+	}
+	if p.resp != nil {
+		resp := *p.resp
+		return &resp, nil
+	}
+	return &CompletionResponse{Content: p.name}, nil
+}
+
+func (p *fakeProvider) Stream(ctx context.Context, req *CompletionRequest, onChunk StreamHandler) (*CompletionResponse, error) {
+	if p.stream {
+		onChunk(StreamChunk{Content: "partial"})
+	}
+	return p.Complete(ctx, req)
+}
+
+func (p *fakeProvider) IsAvailable() bool { return true }
+
+func (p *fakeProvider) Type() ProviderType { return ProviderTypeMock }
+
+// newTestRouter returns a router over an in-memory database whose fallback
+// chain is providers in order, retrying without noticeable delays.
+func newTestRouter(t *testing.T, providers ...*fakeProvider) *Router {
+	t.Helper()
+
+	db, err := storage.Open(":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Migrate(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	r, err := NewRouter(db)
+	if err != nil {
+		t.Fatalf("new router: %v", err)
+	}
+	r.retryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	for _, provider := range providers {
+		r.providers[provider.name] = provider
+		r.order = append(r.order, provider.name)
+	}
+	return r
+}
+
+// fakeClock is a settable time source for the circuit breaker.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func providerErr(kind ErrorKind) error {
+	return &ProviderError{Kind: kind, Err: errors.New(string(kind))}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorKind
+	}{
+		{"nil", nil, ""},
+		{"provider error", fmt.Errorf("call: %w", providerErr(ErrorKindAuth)), ErrorKindAuth},
+		{"canceled", fmt.Errorf("call: %w", context.Canceled), ErrorKindCanceled},
+		{"deadline", context.DeadlineExceeded, ErrorKindTransient},
+		{"unauthorized", &openai.Error{StatusCode: http.StatusUnauthorized}, ErrorKindAuth},
+		{"payment required", &openai.Error{StatusCode: http.StatusPaymentRequired}, ErrorKindAuth},
+		{"rate limited", &openai.Error{StatusCode: http.StatusTooManyRequests}, ErrorKindRateLimit},
+		{"server error", &openai.Error{StatusCode: http.StatusServiceUnavailable}, ErrorKindTransient},
+		{"request timeout", &openai.Error{StatusCode: http.StatusRequestTimeout}, ErrorKindTransient},
+		{"context length message", &openai.Error{StatusCode: http.StatusBadRequest, Message: "This model's maximum context length is 8192 tokens"}, ErrorKindContextLength},
+		{"payload too large", &openai.Error{StatusCode: http.StatusRequestEntityTooLarge}, ErrorKindContextLength},
+		{"bad request", &openai.Error{StatusCode: http.StatusBadRequest, Message: "unknown field"}, ErrorKindInvalidRequest},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, ErrorKindTransient},
+		{"other", errors.New("something odd"), ErrorKindUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Errorf("ClassifyError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		min   time.Duration
+		max   time.Duration
+	}{
+		{"", 0, 0},
+		{"12", 12 * time.Second, 12 * time.Second},
+		{"soon", 0, 0},
+		{time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), 55 * time.Second, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value); got < tt.min || got > tt.max {
+			t.Errorf("parseRetryAfter(%q) = %v, want between %v and %v", tt.value, got, tt.min, tt.max)
+		}
+	}
+
+	header := http.Header{}
+	header.Set("Retry-After", "4")
+	wrapped := wrapProviderError("openrouter", &openai.Error{StatusCode: http.StatusTooManyRequests, Response: &http.Response{Header: header}})
+	if wrapped.Kind != ErrorKindRateLimit || wrapped.StatusCode != http.StatusTooManyRequests || wrapped.RetryAfter != 4*time.Second {
+		t.Errorf("wrapped = %+v, want a rate limit retrying after 4s", wrapped)
+	}
+
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: 4 * time.Second}
+	backoffs := []struct {
+		attempt    int
+		retryAfter time.Duration
+		min        time.Duration
+		max        time.Duration
+	}{
+		{1, 0, time.Second, 1200 * time.Millisecond},
+		{2, 0, 2 * time.Second, 2400 * time.Millisecond},
+		{5, 0, 4 * time.Second, 4800 * time.Millisecond},
+		{1, 10 * time.Second, 10 * time.Second, 10 * time.Second},
+	}
+	for _, tt := range backoffs {
+		if got := policy.backoff(tt.attempt, tt.retryAfter); got < tt.min || got > tt.max {
+			t.Errorf("backoff(%d, %v) = %v, want between %v and %v", tt.attempt, tt.retryAfter, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)}
+	breaker := newCircuitBreaker()
+	breaker.now = clock.now
+
+	for i := 0; i < defaultBreakerThreshold-1; i++ {
+		breaker.RecordFailure()
+	}
+	if !breaker.Allow() {
+		t.Fatal("breaker opened before the threshold")
+	}
+	breaker.RecordFailure()
+	if breaker.Allow() {
+		t.Fatal("breaker still closed after the threshold")
+	}
+
+	clock.advance(defaultBreakerCooldown - time.Second)
+	if breaker.Allow() {
+		t.Error("breaker allowed a call during the cooldown")
+	}
+
+	// Half-open: one trial call goes through, concurrent ones do not.
+	clock.advance(time.Second)
+	if !breaker.Allow() {
+		t.Fatal("breaker refused the trial call after the cooldown")
+	}
+	if breaker.Allow() {
+		t.Error("breaker allowed a second call while the trial is in flight")
+	}
+
+	// A failed trial opens the breaker for another cooldown.
+	breaker.RecordFailure()
+	clock.advance(defaultBreakerCooldown / 2)
+	if breaker.Allow() {
+		t.Error("breaker allowed a call after a failed trial")
+	}
+
+	clock.advance(defaultBreakerCooldown)
+	if !breaker.Allow() {
+		t.Fatal("breaker refused the second trial call")
+	}
+	breaker.RecordSuccess()
+	for i := 0; i < 2; i++ {
+		if !breaker.Allow() {
+			t.Errorf("call %d refused after a successful trial", i)
+		}
+	}
+}
+
+func TestRouterRetriesAndFailsOver(t *testing.T) {
+	rateLimited := &ProviderError{Kind: ErrorKindRateLimit, RetryAfter: time.Millisecond, Err: errors.New("slow down")}
+	tests := []struct {
+		name           string
+		primaryErrs    []error
+		secondaryErrs  []error
+		wantContent    string
+		wantErr        error
+		wantKind       ErrorKind
+		primaryCalls   int
+		secondaryCalls int
+	}{
+		{
+			name:         "retries a rate limit on the same provider",
+			primaryErrs:  []error{rateLimited},
+			wantContent:  "primary",
+			primaryCalls: 2,
+		},
+		{
+			name:           "fails over after exhausting retries",
+			primaryErrs:    []error{providerErr(ErrorKindTransient), providerErr(ErrorKindTransient), providerErr(ErrorKindTransient)},
+			wantContent:    "secondary",
+			primaryCalls:   3,
+			secondaryCalls: 1,
+		},
+		{
+			name:           "fails over on auth errors without retrying",
+			primaryErrs:    []error{providerErr(ErrorKindAuth)},
+			wantContent:    "secondary",
+			primaryCalls:   1,
+			secondaryCalls: 1,
+		},
+		{
+			name:         "stops on invalid requests",
+			primaryErrs:  []error{providerErr(ErrorKindInvalidRequest)},
+			wantKind:     ErrorKindInvalidRequest,
+			primaryCalls: 1,
+		},
+		{
+			name:           "reports when every provider fails",
+			primaryErrs:    []error{providerErr(ErrorKindAuth)},
+			secondaryErrs:  []error{providerErr(ErrorKindAuth)},
+			wantErr:        ErrAllProvidersFailed,
+			wantKind:       ErrorKindAuth,
+			primaryCalls:   1,
+			secondaryCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &fakeProvider{name: "primary", errs: tt.primaryErrs}
+			secondary := &fakeProvider{name: "secondary", errs: tt.secondaryErrs}
+			r := newTestRouter(t, primary, secondary)
+
+			resp, err := r.Complete(context.Background(), &CompletionRequest{})
+			if tt.wantKind == "" {
+				if err != nil || resp.Content != tt.wantContent {
+					t.Fatalf("Complete = %+v, %v; want a response from %s", resp, err, tt.wantContent)
+				}
+			} else {
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				if kind := ClassifyError(err); kind != tt.wantKind {
+					t.Errorf("error kind = %q, want %q", kind, tt.wantKind)
+				}
+			}
+			if primary.calls != tt.primaryCalls || secondary.calls != tt.secondaryCalls {
+				t.Errorf("calls = %d primary, %d secondary; want %d and %d", primary.calls, secondary.calls, tt.primaryCalls, tt.secondaryCalls)
+			}
+		})
+	}
+}
+
+func TestRouterSkipsOpenBreakerUntilCooldown(t *testing.T) {
+	primary := &fakeProvider{name: "primary"}
+	secondary := &fakeProvider{name: "secondary"}
+	r := newTestRouter(t, primary, secondary)
+	clock := &fakeClock{t: time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)}
+	r.breaker("primary").now = clock.now
+
+	for i := 0; i < defaultBreakerThreshold; i++ {
+		primary.errs = append(primary.errs, providerErr(ErrorKindAuth))
+	}
+	for i := 0; i < defaultBreakerThreshold; i++ {
+		if _, err := r.Complete(context.Background(), &CompletionRequest{}); err != nil {
+			t.Fatalf("Complete %d: %v", i, err)
+		}
+	}
+
+	resp, err := r.Complete(context.Background(), &CompletionRequest{})
+	if err != nil || resp.Content != "secondary" || primary.calls != defaultBreakerThreshold {
+		t.Fatalf("with the breaker open: %+v, %v after %d primary calls; want secondary only", resp, err, primary.calls)
+	}
+
+	clock.advance(defaultBreakerCooldown)
+	resp, err = r.Complete(context.Background(), &CompletionRequest{})
+	if err != nil || resp.Content != "primary" {
+		t.Errorf("after the cooldown: %+v, %v; want the trial call to reach primary", resp, err)
+	}
+}
+
+func TestRouterDoesNotRetryInterruptedStreams(t *testing.T) {
+	primary := &fakeProvider{name: "primary", errs: []error{providerErr(ErrorKindTransient)}, stream: true}
+	secondary := &fakeProvider{name: "secondary"}
+	r := newTestRouter(t, primary, secondary)
+
+	var chunks int
+	_, err := r.Stream(context.Background(), &CompletionRequest{}, func(StreamChunk) { chunks++ })
+	if kind := ClassifyError(err); kind != ErrorKindTransient {
+		t.Errorf("error = %v, want the transient provider error", err)
+	}
+	if primary.calls != 1 || secondary.calls != 0 || chunks != 1 {
+		t.Errorf("calls = %d primary, %d secondary, %d chunks; want one primary call only", primary.calls, secondary.calls, chunks)
+	}
+}
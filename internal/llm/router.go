@@ -3,8 +3,10 @@ package llm
 import (
 	"agent-coach/internal/storage"
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"agent-coach/internal/models"
 
@@ -15,7 +17,14 @@ type Router struct {
 	db              *storage.DB
 	providers       map[string]Provider
 	defaultProvider Provider
-	mu              sync.RWMutex
+	// order lists provider names in fallback order: the default provider
+	// first, then by descending priority.
+	order        []string
+	agentConfigs map[models.AgentType]*models.AgentModelConfig
+	breakers     map[string]*circuitBreaker
+	retryPolicy  RetryPolicy
+	usageRepo    *storage.UsageRepository
+	mu           sync.RWMutex
 }
 
 func NewRouter(db *storage.DB) (*Router, error) {
@@ -23,18 +32,27 @@ func NewRouter(db *storage.DB) (*Router, error) {
 		db:              db,
 		providers:       make(map[string]Provider),
 		defaultProvider: nil,
+		agentConfigs:    make(map[models.AgentType]*models.AgentModelConfig),
+		breakers:        make(map[string]*circuitBreaker),
+		retryPolicy:     DefaultRetryPolicy,
+		usageRepo:       storage.NewUsageRepository(db),
 	}
 	err := r.loadProvidersFromDB(context.Background())
 	if err != nil {
 		return nil, err
 	}
+	if err := r.refreshAgentModelConfigs(context.Background()); err != nil {
+		return nil, err
+	}
 	return r, nil
 }
 
 func (r *Router) SaveProviderConfig(ctx context.Context, config *models.LLMProviderConfig) error {
 	query := `
-		INSERT INTO llm_providers (name, provider, base_url, api_key, default_model, is_default, is_active)
-		VALUES (:name, :provider, :base_url, :api_key, :default_model, :is_default, :is_active)
+		INSERT INTO llm_providers (name, provider, base_url, api_key, default_model, is_default, is_active, priority,
+			fixture_path, record_from)
+		VALUES (:name, :provider, :base_url, :api_key, :default_model, :is_default, :is_active, :priority,
+			:fixture_path, :record_from)
 	`
 	_, err := r.db.NamedExecContext(ctx, query, config)
 	if err != nil {
@@ -49,7 +67,7 @@ func (r *Router) UpdateProviderConfig(ctx context.Context, config *models.LLMPro
 		UPDATE llm_providers 
 		SET name = :name, provider = :provider, base_url = :base_url, api_key = :api_key, 
 		    default_model = :default_model, is_default = :is_default, is_active = :is_active,
-		    updated_at = CURRENT_TIMESTAMP
+		    priority = :priority, fixture_path = :fixture_path, record_from = :record_from, updated_at = CURRENT_TIMESTAMP
 		WHERE id = :id
 	`
 	_, err := r.db.NamedExecContext(ctx, query, config)
@@ -70,12 +88,107 @@ func (r *Router) DeleteProviderConfig(ctx context.Context, id int) error {
 	return r.refreshProviders(ctx)
 }
 
+// Complete sends req to the first healthy provider in the fallback chain.
+// Providers named explicitly are tried first, followed by the default and the
+// remaining providers by priority.
 func (r *Router) Complete(ctx context.Context, req *CompletionRequest, providers ...string) (*CompletionResponse, error) {
-	provider := r.resolveProvider(providers...)
-	if provider == nil {
-		return nil, fmt.Errorf("no provider found")
+	return r.execute(ctx, req, providers, func(ctx context.Context, provider Provider, req *CompletionRequest) (*CompletionResponse, error) {
+		return provider.Complete(ctx, req)
+	})
+}
+
+// Stream is the streaming counterpart of Complete. Once a provider has emitted
+// a chunk its failure is final, since retrying would duplicate output.
+func (r *Router) Stream(ctx context.Context, req *CompletionRequest, onChunk StreamHandler, providers ...string) (*CompletionResponse, error) {
+	return r.execute(ctx, req, providers, func(ctx context.Context, provider Provider, req *CompletionRequest) (*CompletionResponse, error) {
+		emitted := false
+		resp, err := provider.Stream(ctx, req, func(chunk StreamChunk) {
+			emitted = true
+			onChunk(chunk)
+		})
+		if err != nil && emitted {
+			return nil, fmt.Errorf("%w: %w", errStreamInterrupted, err)
+		}
+		return resp, err
+	})
+}
+
+var errStreamInterrupted = errors.New("stream interrupted")
+
+// ErrNoProvider is returned when no active provider is configured.
+var ErrNoProvider = errors.New("no provider found")
+
+type attemptFunc func(ctx context.Context, provider Provider, req *CompletionRequest) (*CompletionResponse, error)
+
+func (r *Router) execute(ctx context.Context, req *CompletionRequest, names []string, attempt attemptFunc) (*CompletionResponse, error) {
+	route, err := r.applyBudget(ctx, r.routeForAgent(req))
+	if err != nil {
+		return nil, err
+	}
+	if route.provider != "" {
+		names = append([]string{route.provider}, names...)
+	}
+
+	chain := r.resolveChain(names...)
+	if len(chain) == 0 {
+		return nil, ErrNoProvider
 	}
-	return provider.Complete(ctx, req)
+
+	var lastErr error
+	for _, entry := range chain {
+		breaker := r.breaker(entry.name)
+		if !breaker.Allow() {
+			log.Infof("skipping provider %s: circuit open", entry.name)
+			continue
+		}
+
+		resp, err := r.attemptWithRetry(ctx, entry, route.requestFor(entry.name), attempt)
+		if err == nil {
+			breaker.RecordSuccess()
+			return resp, nil
+		}
+
+		providerErr := wrapProviderError(entry.name, err)
+		lastErr = providerErr
+		if !providerErr.Kind.ShouldFailover() {
+			return nil, providerErr
+		}
+		breaker.RecordFailure()
+		if errors.Is(err, errStreamInterrupted) {
+			return nil, providerErr
+		}
+		log.Infof("provider %s failed (%s), failing over: %v", entry.name, providerErr.Kind, err)
+	}
+
+	if lastErr == nil {
+		return nil, fmt.Errorf("%w: every provider is temporarily disabled", ErrAllProvidersFailed)
+	}
+	return nil, fmt.Errorf("%w: %w", ErrAllProvidersFailed, lastErr)
+}
+
+func (r *Router) attemptWithRetry(ctx context.Context, entry chainEntry, req *CompletionRequest, attempt attemptFunc) (*CompletionResponse, error) {
+	var err error
+	for i := 1; i <= r.retryPolicy.MaxAttempts; i++ {
+		var resp *CompletionResponse
+		started := time.Now()
+		resp, err = attempt(ctx, entry.provider, req)
+		r.recordUsage(ctx, entry.name, req, resp, time.Since(started), err)
+		if err == nil {
+			return resp, nil
+		}
+		if errors.Is(err, errStreamInterrupted) || i == r.retryPolicy.MaxAttempts {
+			break
+		}
+
+		providerErr := wrapProviderError(entry.name, err)
+		if !providerErr.Kind.Retryable() {
+			break
+		}
+		if sleepErr := sleep(ctx, r.retryPolicy.backoff(i, providerErr.RetryAfter)); sleepErr != nil {
+			return nil, sleepErr
+		}
+	}
+	return nil, err
 }
 
 func (r *Router) GetAvailableProviders(ctx context.Context) ([]ProviderType, error) {
@@ -87,27 +200,48 @@ func (r *Router) loadProvidersFromDB(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+
+	// Recording providers wrap another provider, so they are built after
+	// every other provider exists.
+	var recorders []*models.LLMProviderConfig
 	for _, config := range configs {
 		if !config.IsActive {
 			continue
 		}
-		provider, err := r.createProviderFromConfig(config)
-		if err != nil {
-			log.Errorf("failed to create provider from config: %v", err)
+		if config.RecordFrom != "" {
+			recorders = append(recorders, config)
 			continue
 		}
-		r.providers[config.Name] = provider
-		if config.IsDefault {
-			r.defaultProvider = provider
+		r.addProvider(config)
+	}
+	for _, config := range recorders {
+		r.addProvider(config)
+	}
+
+	for _, config := range configs {
+		if _, ok := r.providers[config.Name]; ok {
+			r.order = append(r.order, config.Name)
 		}
 	}
 
 	return nil
 }
 
+func (r *Router) addProvider(config *models.LLMProviderConfig) {
+	provider, err := r.createProviderFromConfig(config)
+	if err != nil {
+		log.Errorf("failed to create provider from config: %v", err)
+		return
+	}
+	r.providers[config.Name] = provider
+	if config.IsDefault {
+		r.defaultProvider = provider
+	}
+}
+
 func (r *Router) getProviderConfigs(ctx context.Context) ([]*models.LLMProviderConfig, error) {
 	query := `
-		SELECT * FROM llm_providers ORDER BY is_default DESC
+		SELECT * FROM llm_providers ORDER BY is_default DESC, priority DESC, id ASC
 	`
 	var configs []*models.LLMProviderConfig
 	if err := r.db.SelectContext(ctx, &configs, query); err != nil {
@@ -124,6 +258,17 @@ func (r *Router) createProviderFromConfig(config *models.LLMProviderConfig) (Pro
 	switch config.Provider {
 	case "openrouter":
 		return NewOpenRouterProvider(config), nil
+	case "ollama":
+		return NewOllamaProvider(config), nil
+	case "mock":
+		if config.RecordFrom == "" {
+			return NewReplayProvider(config)
+		}
+		inner, ok := r.providers[config.RecordFrom]
+		if !ok {
+			return nil, fmt.Errorf("provider %s records from unknown provider %s", config.Name, config.RecordFrom)
+		}
+		return NewRecordingProvider(config, inner), nil
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", config.Provider)
 	}
@@ -133,20 +278,47 @@ func (r *Router) refreshProviders(ctx context.Context) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.providers = make(map[string]Provider)
+	r.order = nil
 	r.defaultProvider = nil
 	return r.loadProvidersFromDB(ctx)
 }
 
-func (r *Router) resolveProvider(providers ...string) Provider {
+type chainEntry struct {
+	name     string
+	provider Provider
+}
+
+func (r *Router) resolveChain(providers ...string) []chainEntry {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	for _, name := range providers {
-		if provider, ok := r.providers[name]; ok {
-			if !provider.IsAvailable() {
-				continue
-			}
-			return provider
+
+	seen := make(map[string]bool)
+	var chain []chainEntry
+	add := func(name string) {
+		provider, ok := r.providers[name]
+		if !ok || seen[name] || !provider.IsAvailable() {
+			return
 		}
+		seen[name] = true
+		chain = append(chain, chainEntry{name: name, provider: provider})
+	}
+
+	for _, name := range providers {
+		add(name)
+	}
+	for _, name := range r.order {
+		add(name)
+	}
+	return chain
+}
+
+func (r *Router) breaker(name string) *circuitBreaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	breaker, ok := r.breakers[name]
+	if !ok {
+		breaker = newCircuitBreaker()
+		r.breakers[name] = breaker
 	}
-	return r.defaultProvider
+	return breaker
 }
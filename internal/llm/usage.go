@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"agent-coach/internal/models"
+
+	"github.com/google/martian/log"
+)
+
+// ErrBudgetExceeded is returned when the monthly budget is spent and
+// configured to refuse further calls.
+var ErrBudgetExceeded = errors.New("monthly LLM budget exceeded")
+
+// recordUsage writes one call to the usage ledger. Ledger failures are logged
+// rather than surfaced so they never break a conversation.
+func (r *Router) recordUsage(ctx context.Context, provider string, req *CompletionRequest, resp *CompletionResponse, latency time.Duration, callErr error) {
+	usage := &models.LLMUsage{
+		Provider:  provider,
+		Model:     req.Model,
+		AgentType: req.AgentType,
+		GoalID:    req.GoalID,
+		Intent:    req.Intent,
+		LatencyMS: latency.Milliseconds(),
+	}
+	if callErr != nil {
+		usage.Error = callErr.Error()
+	}
+	if resp != nil {
+		if resp.Model != "" {
+			usage.Model = resp.Model
+		}
+		usage.PromptTokens = resp.Usage.PromptTokens
+		usage.CompletionTokens = resp.Usage.CompletionTokens
+		usage.TotalTokens = resp.Usage.TotalTokens
+		usage.FinishReason = resp.FinishReason
+		usage.CostUSD = r.cost(ctx, usage.Model, req.Model, resp.Usage)
+	}
+
+	// The caller's context may already be cancelled, which must not lose the record.
+	if err := r.usageRepo.Create(context.WithoutCancel(ctx), usage); err != nil {
+		log.Errorf("failed to record LLM usage: %v", err)
+	}
+}
+
+// cost prices a call by the model the provider reported, falling back to the
+// requested model name since providers often report a dated variant.
+func (r *Router) cost(ctx context.Context, reportedModel, requestedModel string, usage Usage) float64 {
+	for _, model := range []string{reportedModel, requestedModel} {
+		if model == "" {
+			continue
+		}
+		price, err := r.usageRepo.GetPrice(ctx, model)
+		if err != nil {
+			log.Errorf("failed to load price for %s: %v", model, err)
+			return 0
+		}
+		if price != nil {
+			return price.Cost(usage.PromptTokens, usage.CompletionTokens)
+		}
+	}
+	return 0
+}
+
+// applyBudget enforces the monthly budget on route, refusing the call or
+// switching it to the downgrade model once the month-to-date spend reaches the
+// limit.
+func (r *Router) applyBudget(ctx context.Context, route agentRoute) (agentRoute, error) {
+	budget, err := r.usageRepo.GetBudget(ctx)
+	if err != nil {
+		return route, fmt.Errorf("failed to load usage budget: %w", err)
+	}
+	if budget == nil || !budget.IsActive || budget.MonthlyLimitUSD <= 0 {
+		return route, nil
+	}
+
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	spent, err := r.usageRepo.CostSince(ctx, monthStart)
+	if err != nil {
+		return route, fmt.Errorf("failed to compute monthly spend: %w", err)
+	}
+	if spent < budget.MonthlyLimitUSD {
+		return route, nil
+	}
+
+	if budget.Action != models.BudgetActionDowngrade || budget.DowngradeModel == "" {
+		return route, fmt.Errorf("%w: spent $%.2f of $%.2f", ErrBudgetExceeded, spent, budget.MonthlyLimitUSD)
+	}
+
+	log.Infof("monthly budget exceeded ($%.2f of $%.2f), downgrading to %s", spent, budget.MonthlyLimitUSD, budget.DowngradeModel)
+	downgraded := *route.req
+	downgraded.Model = budget.DowngradeModel
+	return agentRoute{
+		req:         &downgraded,
+		provider:    budget.DowngradeProvider,
+		pinnedModel: budget.DowngradeProvider != "",
+	}, nil
+}
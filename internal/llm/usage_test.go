@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"agent-coach/internal/models"
+)
+
+func TestRouterRecordsUsage(t *testing.T) {
+	ctx := context.Background()
+	provider := &fakeProvider{
+		name:  "primary",
+		delay: 5 * time.Millisecond,
+		resp: &CompletionResponse{
+			Content:      "Here is your plan",
+			Model:        "gpt-test-2026-01-01",
+			Usage:        Usage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500},
+			FinishReason: "stop",
+		},
+	}
+	r := newTestRouter(t, provider)
+	// The provider reports a dated variant, so the requested model is priced.
+	if err := r.usageRepo.SavePrice(ctx, &models.ModelPrice{Model: "gpt-test", InputPerMillion: 2, OutputPerMillion: 10}); err != nil {
+		t.Fatalf("save price: %v", err)
+	}
+
+	req := &CompletionRequest{Model: "gpt-test", AgentType: models.AgentTypePlanner, GoalID: "goal-1", Intent: "PLANNING"}
+	for i := 0; i < 2; i++ {
+		if _, err := r.Complete(ctx, req); err != nil {
+			t.Fatalf("Complete: %v", err)
+		}
+	}
+
+	var usage models.LLMUsage
+	if err := r.db.GetContext(ctx, &usage, `SELECT * FROM llm_usage ORDER BY id LIMIT 1`); err != nil {
+		t.Fatalf("select usage: %v", err)
+	}
+	if usage.Provider != "primary" || usage.Model != "gpt-test-2026-01-01" || usage.AgentType != models.AgentTypePlanner ||
+		usage.GoalID != "goal-1" || usage.Intent != "PLANNING" || usage.FinishReason != "stop" || usage.Error != "" {
+		t.Errorf("usage = %+v", usage)
+	}
+	if usage.PromptTokens != 1000 || usage.CompletionTokens != 500 || usage.TotalTokens != 1500 {
+		t.Errorf("tokens = %d/%d/%d, want 1000/500/1500", usage.PromptTokens, usage.CompletionTokens, usage.TotalTokens)
+	}
+	if usage.LatencyMS < 5 {
+		t.Errorf("latency = %dms, want at least the provider's 5ms", usage.LatencyMS)
+	}
+	// 1000 prompt tokens at $2/M plus 500 completion tokens at $10/M.
+	if math.Abs(usage.CostUSD-0.007) > 1e-9 {
+		t.Errorf("cost = %v, want 0.007", usage.CostUSD)
+	}
+
+	today := time.Now().UTC()
+	for _, tt := range []struct {
+		period models.UsagePeriod
+		want   string
+	}{
+		{models.UsagePeriodDay, today.Format("2006-01-02")},
+		{models.UsagePeriodMonth, today.Format("2006-01")},
+	} {
+		aggregates, err := r.usageRepo.Aggregate(ctx, models.UsageQuery{GroupBy: models.UsageGroupByAgent, Period: tt.period})
+		if err != nil {
+			t.Fatalf("Aggregate %s: %v", tt.period, err)
+		}
+		if len(aggregates) != 1 {
+			t.Fatalf("%s aggregates = %+v, want one", tt.period, aggregates)
+		}
+		got := aggregates[0]
+		if got.Period != tt.want || got.Key != string(models.AgentTypePlanner) || got.Calls != 2 || got.TotalTokens != 3000 || math.Abs(got.CostUSD-0.014) > 1e-9 {
+			t.Errorf("%s aggregate = %+v, want 2 planner calls on %s costing 0.014", tt.period, got, tt.want)
+		}
+	}
+}
+
+func TestRouterRecordsFailedCalls(t *testing.T) {
+	ctx := context.Background()
+	provider := &fakeProvider{name: "primary", errs: []error{providerErr(ErrorKindInvalidRequest)}}
+	r := newTestRouter(t, provider)
+
+	if _, err := r.Complete(ctx, &CompletionRequest{Model: "gpt-test"}); err == nil {
+		t.Fatal("Complete succeeded, want the provider error")
+	}
+	var usage models.LLMUsage
+	if err := r.db.GetContext(ctx, &usage, `SELECT * FROM llm_usage`); err != nil {
+		t.Fatalf("select usage: %v", err)
+	}
+	if usage.Error == "" || usage.Model != "gpt-test" || usage.CostUSD != 0 {
+		t.Errorf("usage = %+v, want the error recorded at no cost", usage)
+	}
+}
+
+func TestRouterEnforcesBudget(t *testing.T) {
+	ctx := context.Background()
+	primary := &fakeProvider{name: "primary"}
+	cheap := &fakeProvider{name: "cheap"}
+	r := newTestRouter(t, primary, cheap)
+
+	spend := func(cost float64) {
+		t.Helper()
+		if err := r.usageRepo.Create(ctx, &models.LLMUsage{Provider: "primary", Model: "gpt-test", CostUSD: cost}); err != nil {
+			t.Fatalf("record usage: %v", err)
+		}
+	}
+	saveBudget := func(budget *models.UsageBudget) {
+		t.Helper()
+		if err := r.usageRepo.SaveBudget(ctx, budget); err != nil {
+			t.Fatalf("save budget: %v", err)
+		}
+	}
+
+	saveBudget(&models.UsageBudget{MonthlyLimitUSD: 5, Action: models.BudgetActionRefuse, IsActive: true})
+	spend(4)
+	if _, err := r.Complete(ctx, &CompletionRequest{Model: "gpt-test"}); err != nil {
+		t.Fatalf("under budget: %v", err)
+	}
+
+	spend(1)
+	calls := primary.calls
+	if _, err := r.Complete(ctx, &CompletionRequest{Model: "gpt-test"}); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("refuse mode: error = %v, want ErrBudgetExceeded", err)
+	}
+	if primary.calls != calls {
+		t.Error("refused call still reached the provider")
+	}
+
+	saveBudget(&models.UsageBudget{MonthlyLimitUSD: 5, Action: models.BudgetActionDowngrade, DowngradeProvider: "cheap", DowngradeModel: "mini", IsActive: true})
+	resp, err := r.Complete(ctx, &CompletionRequest{Model: "gpt-test"})
+	if err != nil || resp.Content != "cheap" {
+		t.Fatalf("downgrade mode = %+v, %v; want the downgrade provider", resp, err)
+	}
+	if got := cheap.requests[len(cheap.requests)-1].Model; got != "mini" {
+		t.Errorf("downgraded model = %q, want mini", got)
+	}
+
+	saveBudget(&models.UsageBudget{MonthlyLimitUSD: 5, Action: models.BudgetActionRefuse, IsActive: false})
+	if _, err := r.Complete(ctx, &CompletionRequest{Model: "gpt-test"}); err != nil {
+		t.Errorf("inactive budget: %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE IF NOT EXISTS llm_usage (
+    id INTEGER PRIMARY KEY,
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL,
+    agent_type TEXT NOT NULL DEFAULT '',
+    goal_id TEXT NOT NULL DEFAULT '',
+    intent TEXT NOT NULL DEFAULT '',
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    total_tokens INTEGER NOT NULL DEFAULT 0,
+    cost_usd REAL NOT NULL DEFAULT 0,
+    latency_ms INTEGER NOT NULL DEFAULT 0,
+    finish_reason TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_llm_usage_created_at ON llm_usage (created_at);
+
+CREATE TABLE IF NOT EXISTS model_prices (
+    id INTEGER PRIMARY KEY,
+    model TEXT NOT NULL UNIQUE,
+    input_per_million REAL NOT NULL DEFAULT 0,
+    output_per_million REAL NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS usage_budget (
+    id INTEGER PRIMARY KEY CHECK(id = 1),
+    monthly_limit_usd REAL NOT NULL DEFAULT 0,
+    action TEXT NOT NULL DEFAULT 'refuse' CHECK(action IN ('refuse', 'downgrade')),
+    downgrade_provider TEXT NOT NULL DEFAULT '',
+    downgrade_model TEXT NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE IF EXISTS usage_budget;
+DROP TABLE IF EXISTS model_prices;
+DROP INDEX IF EXISTS idx_llm_usage_created_at;
+DROP TABLE IF EXISTS llm_usage;
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE llm_providers_new (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    provider TEXT NOT NULL CHECK(provider IN ('ollama', 'openrouter', 'mock')),
+    base_url TEXT,
+    api_key TEXT,
+    default_model TEXT,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    priority INTEGER NOT NULL DEFAULT 0,
+    fixture_path TEXT NOT NULL DEFAULT '',
+    record_from TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO llm_providers_new (id, name, provider, base_url, api_key, default_model, is_default, is_active, priority, created_at, updated_at)
+SELECT id, name, provider, base_url, api_key, default_model, is_default, is_active, priority, created_at, updated_at
+FROM llm_providers;
+DROP TABLE llm_providers;
+ALTER TABLE llm_providers_new RENAME TO llm_providers;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+CREATE TABLE llm_providers_old (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    provider TEXT NOT NULL CHECK(provider IN ('ollama', 'openrouter')),
+    base_url TEXT,
+    api_key TEXT,
+    default_model TEXT,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    priority INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO llm_providers_old (id, name, provider, base_url, api_key, default_model, is_default, is_active, priority, created_at, updated_at)
+SELECT id, name, provider, base_url, api_key, default_model, is_default, is_active, priority, created_at, updated_at
+FROM llm_providers WHERE provider != 'mock';
+DROP TABLE llm_providers;
+ALTER TABLE llm_providers_old RENAME TO llm_providers;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE IF NOT EXISTS performance_ratings (
+    id TEXT PRIMARY KEY,
+    goal_id TEXT NOT NULL,
+    task_id TEXT NOT NULL,
+    rating INTEGER NOT NULL CHECK(rating BETWEEN 1 AND 5),
+    feedback TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_performance_ratings_goal_id ON performance_ratings (goal_id);
+
+CREATE TABLE IF NOT EXISTS weaknesses (
+    id TEXT PRIMARY KEY,
+    goal_id TEXT NOT NULL,
+    area TEXT NOT NULL,
+    evidence TEXT NOT NULL,
+    suggestion TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_weaknesses_goal_id ON weaknesses (goal_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE IF EXISTS weaknesses;
+DROP TABLE IF EXISTS performance_ratings;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE IF NOT EXISTS checkins (
+    id TEXT PRIMARY KEY,
+    goal_id TEXT NOT NULL,
+    summary TEXT NOT NULL,
+    wins TEXT,
+    struggles TEXT,
+    mood_rating INTEGER CHECK(mood_rating BETWEEN 1 AND 5),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_checkins_goal_id ON checkins (goal_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE IF EXISTS checkins;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE IF NOT EXISTS milestones (
+    id TEXT PRIMARY KEY,
+    goal_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT,
+    week_start INTEGER NOT NULL,
+    week_end INTEGER NOT NULL,
+    status TEXT NOT NULL CHECK(status IN ('pending', 'in_progress', 'completed')),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_milestones_goal_id ON milestones (goal_id, week_start);
+ALTER TABLE tasks ADD COLUMN milestone_id TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE tasks DROP COLUMN milestone_id;
+DROP TABLE IF EXISTS milestones;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE IF NOT EXISTS resources (
+    id TEXT PRIMARY KEY,
+    goal_id TEXT NOT NULL,
+    milestone_id TEXT,
+    task_id TEXT,
+    title TEXT NOT NULL,
+    type TEXT NOT NULL DEFAULT '',
+    url TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'to_read' CHECK(status IN ('to_read', 'in_progress', 'done')),
+    notes TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_resources_goal_id ON resources (goal_id);
+CREATE INDEX IF NOT EXISTS idx_resources_task_id ON resources (task_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE IF EXISTS resources;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+ALTER TABLE agent_model_configs ADD COLUMN max_iterations INTEGER;
+ALTER TABLE agent_model_configs ADD COLUMN max_total_tokens INTEGER;
+ALTER TABLE agent_model_configs ADD COLUMN timeout_seconds INTEGER;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE agent_model_configs DROP COLUMN timeout_seconds;
+ALTER TABLE agent_model_configs DROP COLUMN max_total_tokens;
+ALTER TABLE agent_model_configs DROP COLUMN max_iterations;
+-- +goose StatementEnd
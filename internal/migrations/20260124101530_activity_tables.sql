@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE IF NOT EXISTS user_settings (
+    id INTEGER PRIMARY KEY CHECK(id = 1),
+    rest_days TEXT NOT NULL DEFAULT '[]',
+    grace_days INTEGER NOT NULL DEFAULT 1,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS daily_activity (
+    goal_id TEXT NOT NULL,
+    day TEXT NOT NULL,
+    tasks_completed INTEGER NOT NULL DEFAULT 0,
+    minutes_spent INTEGER NOT NULL DEFAULT 0,
+    checkins INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (goal_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_daily_activity_day ON daily_activity(day);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP INDEX IF EXISTS idx_daily_activity_day;
+DROP TABLE IF EXISTS daily_activity;
+DROP TABLE IF EXISTS user_settings;
+-- +goose StatementEnd
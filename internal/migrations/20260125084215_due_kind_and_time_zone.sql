@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+ALTER TABLE tasks ADD COLUMN due_kind TEXT NOT NULL DEFAULT 'date' CHECK(due_kind IN ('date', 'datetime'));
+ALTER TABLE user_settings ADD COLUMN time_zone TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE user_settings DROP COLUMN time_zone;
+ALTER TABLE tasks DROP COLUMN due_kind;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+ALTER TABLE user_settings ADD COLUMN daily_minutes INTEGER NOT NULL DEFAULT 60;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE user_settings DROP COLUMN daily_minutes;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+CREATE TABLE IF NOT EXISTS weekday_availability (
+    weekday INTEGER PRIMARY KEY CHECK(weekday BETWEEN 0 AND 6),
+    minutes INTEGER NOT NULL CHECK(minutes >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS blackout_dates (
+    day TEXT PRIMARY KEY,
+    reason TEXT NOT NULL DEFAULT ''
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE IF EXISTS blackout_dates;
+DROP TABLE IF EXISTS weekday_availability;
+-- +goose StatementEnd
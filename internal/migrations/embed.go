@@ -0,0 +1,8 @@
+// Package migrations holds the goose-format SQL migrations, embedded so the
+// app can bring a fresh database up to date without the goose CLI.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
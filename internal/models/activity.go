@@ -0,0 +1,25 @@
+package models
+
+// DailyActivity aggregates one local calendar day of work on a goal. Day is
+// a YYYY-MM-DD date; GoalID is empty when the days of all goals are summed.
+type DailyActivity struct {
+	GoalID         string `db:"goal_id" json:"goalId,omitempty"`
+	Day            string `db:"day" json:"day"`
+	TasksCompleted int    `db:"tasks_completed" json:"tasksCompleted"`
+	MinutesSpent   int    `db:"minutes_spent" json:"minutesSpent"`
+	CheckIns       int    `db:"checkins" json:"checkIns"`
+}
+
+// Active reports whether the day counts toward a streak.
+func (d *DailyActivity) Active() bool {
+	return d.TasksCompleted > 0 || d.CheckIns > 0
+}
+
+// Streak counts active days in a row, skipping rest days and tolerating
+// short gaps allowed by the grace period.
+type Streak struct {
+	Current       int    `json:"current"`
+	Longest       int    `json:"longest"`
+	ActiveToday   bool   `json:"activeToday"`
+	LastActiveDay string `json:"lastActiveDay,omitempty"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // State represents the current state of the agent workflow
 type State string
 
@@ -18,11 +20,33 @@ const (
 type AgentContext struct {
 	Goal          *Goal
 	Tasks         []*Task
+	Milestones    []*Milestone
+	Resources     []*Resource
 	TodaysTasks   []*Task
+	OverdueTasks  []*Task
 	Conversations []*Conversation
+	Weaknesses    []*Weakness
+	Progress      *ProgressStats
+	LastCheckIn   *CheckIn
+	// Location is the user's time zone; nil means the system zone.
+	Location *time.Location
 
 	CurrentState    State
 	StreakDays      int
+	LongestStreak   int
 	RecentStruggles int
 	TasksCompleted  int
 }
+
+// TimeZone returns the user's time zone.
+func (c *AgentContext) TimeZone() *time.Location {
+	if c.Location == nil {
+		return time.Local
+	}
+	return c.Location
+}
+
+// Now returns the current time in the user's time zone.
+func (c *AgentContext) Now() time.Time {
+	return time.Now().In(c.TimeZone())
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// AgentModelConfig overrides which provider and model an agent (or the intent
+// classifier) uses. Empty fields fall back to the provider defaults. The loop
+// fields bound an agent's tool-calling loop; unset ones use the agent's
+// default loop policy and zero disables the limit.
+type AgentModelConfig struct {
+	ID           int       `db:"id" json:"id"`
+	AgentType    AgentType `db:"agent_type" json:"agent_type"`
+	ProviderName string    `db:"provider_name" json:"provider_name,omitempty"`
+	Model        string    `db:"model" json:"model,omitempty"`
+	Temperature  *float64  `db:"temperature" json:"temperature,omitempty"`
+	MaxTokens    *int      `db:"max_tokens" json:"max_tokens,omitempty"`
+
+	MaxIterations  *int `db:"max_iterations" json:"max_iterations,omitempty"`
+	MaxTotalTokens *int `db:"max_total_tokens" json:"max_total_tokens,omitempty"`
+	TimeoutSeconds *int `db:"timeout_seconds" json:"timeout_seconds,omitempty"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
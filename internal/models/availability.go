@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// BlackoutDate is a YYYY-MM-DD day with no time for tasks.
+type BlackoutDate struct {
+	Day    string `db:"day" json:"day"`
+	Reason string `db:"reason" json:"reason,omitempty"`
+}
+
+// Availability is how many minutes of task work the user has on each day.
+// WeekdayMinutes is indexed by time.Weekday (Sunday first).
+type Availability struct {
+	WeekdayMinutes []int           `json:"weekdayMinutes"`
+	Blackouts      []*BlackoutDate `json:"blackouts"`
+}
+
+// UniformAvailability gives every weekday the same minutes.
+func UniformAvailability(minutes int) *Availability {
+	availability := &Availability{WeekdayMinutes: make([]int, 7), Blackouts: []*BlackoutDate{}}
+	for i := range availability.WeekdayMinutes {
+		availability.WeekdayMinutes[i] = minutes
+	}
+	return availability
+}
+
+// MinutesOn returns the capacity of the calendar day of t, in t's own
+// location: zero on blackout dates, otherwise the weekday's minutes.
+func (a *Availability) MinutesOn(t time.Time) int {
+	key := t.Format("2006-01-02")
+	for _, blackout := range a.Blackouts {
+		if blackout.Day == key {
+			return 0
+		}
+	}
+	if len(a.WeekdayMinutes) != 7 {
+		return 0
+	}
+	return a.WeekdayMinutes[t.Weekday()]
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type CheckIn struct {
+	ID         string    `db:"id" json:"id"`
+	GoalID     string    `db:"goal_id" json:"goalId"`
+	Summary    string    `db:"summary" json:"summary"`
+	Wins       []string  `db:"-" json:"wins,omitempty"`
+	Struggles  []string  `db:"-" json:"struggles,omitempty"`
+	MoodRating *int      `db:"mood_rating" json:"moodRating,omitempty"`
+	CreatedAt  time.Time `db:"created_at" json:"createdAt"`
+}
@@ -10,6 +10,7 @@ const (
 	RoleUser      Role = "user"
 	RoleAssistant Role = "assistant"
 	RoleSystem    Role = "system"
+	RoleTool      Role = "tool"
 )
 
 type AgentType string
@@ -19,16 +20,20 @@ const (
 	AgentTypeExecutor       AgentType = "executor"
 	AgentTypeEvaluator      AgentType = "evaluator"
 	AgentTypeAccountability AgentType = "accountability"
+	AgentTypeGeneral        AgentType = "general"
+	// AgentTypeClassifier is not a conversational agent; it identifies the
+	// intent classifier in model routing and usage accounting.
+	AgentTypeClassifier AgentType = "classifier"
 )
 
 type Conversation struct {
-	ID        string                 `db:"id" json:"id"`
-	GoalID    *string                `db:"goal_id" json:"goalId,omitempty"`
-	SessionID string                 `db:"session_id" json:"sessionId"`
-	Role      Role                   `db:"role" json:"role"`
-	Content   string                 `db:"content" json:"content"`
-	AgentType AgentType              `db:"agent_type" json:"agentType,omitempty"`
-	Metadata  map[string]interface{} `db:"metadata" json:"metadata,omitempty"`
-	CreatedAt time.Time              `db:"created_at" json:"createdAt"`
-	UpdatedAt time.Time              `db:"updated_at" json:"updatedAt"`
+	ID        string    `db:"id" json:"id"`
+	GoalID    *string   `db:"goal_id" json:"goalId,omitempty"`
+	SessionID string    `db:"session_id" json:"sessionId"`
+	Role      Role      `db:"role" json:"role"`
+	Content   string    `db:"content" json:"content"`
+	AgentType AgentType `db:"agent_type" json:"agentType,omitempty"`
+	Metadata  JSONMap   `db:"metadata" json:"metadata,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
 }
@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// TaskDependency says that TaskID cannot start until DependsOnID is
+// completed or skipped.
+type TaskDependency struct {
+	TaskID      string    `db:"task_id" json:"taskId"`
+	DependsOnID string    `db:"depends_on_id" json:"dependsOnId"`
+	CreatedAt   time.Time `db:"created_at" json:"createdAt"`
+}
+
+// DependencyNode is a task in a goal's dependency graph.
+type DependencyNode struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Status      TaskStatus `json:"status"`
+	MilestoneID *string    `json:"milestoneId,omitempty"`
+	DueDate     *time.Time `json:"dueDate,omitempty"`
+	Blocked     bool       `json:"blocked"`
+}
+
+// DependencyGraph holds a goal's tasks and the edges from each task to its
+// prerequisites.
+type DependencyGraph struct {
+	Nodes []*DependencyNode `json:"nodes"`
+	Edges []*TaskDependency `json:"edges"`
+}
+
+// IsOpen reports whether the task still has work left.
+func (t *Task) IsOpen() bool {
+	return t.Status == TaskStatusPending || t.Status == TaskStatusInProgress
+}
+
+// MarkBlocked sets BlockedBy on each task to the IDs of its prerequisites
+// that are still open. Prerequisites missing from tasks are ignored.
+func MarkBlocked(tasks []*Task, dependencies []*TaskDependency) {
+	byID := make(map[string]*Task, len(tasks))
+	for _, task := range tasks {
+		task.BlockedBy = nil
+		byID[task.ID] = task
+	}
+	for _, dependency := range dependencies {
+		task, prerequisite := byID[dependency.TaskID], byID[dependency.DependsOnID]
+		if task != nil && prerequisite != nil && prerequisite.IsOpen() {
+			task.BlockedBy = append(task.BlockedBy, prerequisite.ID)
+		}
+	}
+}
+
+// NewDependencyGraph builds the graph of tasks and the dependencies between
+// them. Edges to tasks outside tasks are dropped.
+func NewDependencyGraph(tasks []*Task, dependencies []*TaskDependency) *DependencyGraph {
+	MarkBlocked(tasks, dependencies)
+
+	graph := &DependencyGraph{Nodes: make([]*DependencyNode, 0, len(tasks)), Edges: []*TaskDependency{}}
+	known := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		known[task.ID] = true
+		graph.Nodes = append(graph.Nodes, &DependencyNode{
+			ID:          task.ID,
+			Title:       task.Title,
+			Status:      task.Status,
+			MilestoneID: task.MilestoneID,
+			DueDate:     task.DueDate,
+			Blocked:     len(task.BlockedBy) > 0,
+		})
+	}
+	for _, dependency := range dependencies {
+		if known[dependency.TaskID] && known[dependency.DependsOnID] {
+			graph.Edges = append(graph.Edges, dependency)
+		}
+	}
+	return graph
+}
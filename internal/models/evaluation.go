@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// PerformanceRating is the evaluator's 1-5 rating of how a task went.
+type PerformanceRating struct {
+	ID        string    `db:"id" json:"id"`
+	GoalID    string    `db:"goal_id" json:"goalId"`
+	TaskID    string    `db:"task_id" json:"taskId"`
+	Rating    int       `db:"rating" json:"rating"`
+	Feedback  string    `db:"feedback" json:"feedback"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
+
+// Weakness is an area the evaluator found the user needs to improve on.
+type Weakness struct {
+	ID         string    `db:"id" json:"id"`
+	GoalID     string    `db:"goal_id" json:"goalId"`
+	Area       string    `db:"area" json:"area"`
+	Evidence   string    `db:"evidence" json:"evidence"`
+	Suggestion string    `db:"suggestion" json:"suggestion"`
+	CreatedAt  time.Time `db:"created_at" json:"createdAt"`
+}
+
+// ProgressStats summarises how a user is doing on a goal's tasks.
+type ProgressStats struct {
+	TotalTasks      int `json:"totalTasks"`
+	CompletedTasks  int `json:"completedTasks"`
+	InProgressTasks int `json:"inProgressTasks"`
+	PendingTasks    int `json:"pendingTasks"`
+	SkippedTasks    int `json:"skippedTasks"`
+	// CompletionRate is completed tasks over all non-skipped tasks, 0-1.
+	CompletionRate float64 `json:"completionRate"`
+	// EstimateRatio is total actual minutes over total estimated minutes for
+	// completed tasks that have both; above 1 means tasks take longer than planned.
+	EstimateRatio  float64 `json:"estimateRatio"`
+	EstimatedTasks int     `json:"estimatedTasks"`
+	StruggledTasks int     `json:"struggledTasks"`
+	StruggleRate   float64 `json:"struggleRate"`
+	AverageRating  float64 `json:"averageRating"`
+	RatedTasks     int     `json:"ratedTasks"`
+}
@@ -14,12 +14,57 @@ const (
 )
 
 type Goal struct {
-	ID          string                 `db:"id" json:"id"`
-	Title       string                 `db:"title" json:"title"`
-	Description string                 `db:"description" json:"description,omitempty"`
-	TargetDate  *time.Time             `db:"target_date" json:"targetDate,omitempty"`
-	Status      GoalStatus             `db:"status" json:"status"`
-	Context     map[string]interface{} `db:"context" json:"context,omitempty"`
-	CreatedAt   time.Time              `db:"created_at" json:"createdAt"`
-	UpdatedAt   time.Time              `db:"updated_at" json:"updatedAt"`
+	ID          string     `db:"id" json:"id"`
+	Title       string     `db:"title" json:"title"`
+	Description string     `db:"description" json:"description,omitempty"`
+	TargetDate  *time.Time `db:"target_date" json:"targetDate,omitempty"`
+	Status      GoalStatus `db:"status" json:"status"`
+	Context     JSONMap    `db:"context" json:"context,omitempty"`
+	CreatedAt   time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updatedAt"`
+}
+
+type GoalSortField string
+
+const (
+	GoalSortCreatedAt  GoalSortField = "createdAt"
+	GoalSortUpdatedAt  GoalSortField = "updatedAt"
+	GoalSortTargetDate GoalSortField = "targetDate"
+	GoalSortTitle      GoalSortField = "title"
+)
+
+// GoalQuery filters and pages the goal list. TargetFrom and TargetTo are
+// inclusive YYYY-MM-DD dates, Search matches the title or description, and
+// an empty SortBy lists the newest goals first.
+type GoalQuery struct {
+	Statuses   []GoalStatus  `json:"statuses,omitempty"`
+	TargetFrom string        `json:"targetFrom,omitempty"`
+	TargetTo   string        `json:"targetTo,omitempty"`
+	Search     string        `json:"search,omitempty"`
+	SortBy     GoalSortField `json:"sortBy,omitempty"`
+	Descending bool          `json:"descending,omitempty"`
+	Limit      int           `json:"limit,omitempty"`
+	Offset     int           `json:"offset,omitempty"`
+}
+
+// GoalSummary is a goal with the task counts and activity shown in the goal
+// picker. LastActivityAt is the latest completed task, message or check-in,
+// falling back to the goal's own last update.
+type GoalSummary struct {
+	Goal
+	TotalTasks      int       `json:"totalTasks"`
+	PendingTasks    int       `json:"pendingTasks"`
+	InProgressTasks int       `json:"inProgressTasks"`
+	CompletedTasks  int       `json:"completedTasks"`
+	SkippedTasks    int       `json:"skippedTasks"`
+	LastActivityAt  time.Time `json:"lastActivityAt"`
+	NextDueTask     *Task     `json:"nextDueTask,omitempty"`
+}
+
+// GoalPage is one page of a goal listing; Total counts every matching goal.
+type GoalPage struct {
+	Goals  []*GoalSummary `json:"goals"`
+	Total  int            `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
 }
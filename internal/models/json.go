@@ -0,0 +1,91 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// JSONMap is a free-form object stored as JSON in a TEXT column. An empty map
+// is stored as NULL, and NULL or an empty string scans back as a nil map.
+type JSONMap map[string]interface{}
+
+func (m JSONMap) Value() (driver.Value, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (m *JSONMap) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot scan %T into JSONMap", src)
+	}
+
+	if len(data) == 0 {
+		*m = nil
+		return nil
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("decode JSON column: %w", err)
+	}
+	*m = decoded
+	return nil
+}
+
+// Weekdays is a list of weekdays stored as a JSON array of numbers, Sunday
+// being 0. A nil list is stored as an empty array, and NULL or an empty
+// string scans back as an empty list.
+type Weekdays []time.Weekday
+
+func (w Weekdays) Value() (driver.Value, error) {
+	if w == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]time.Weekday(w))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (w *Weekdays) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*w = Weekdays{}
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot scan %T into Weekdays", src)
+	}
+
+	decoded := Weekdays{}
+	if len(data) == 0 {
+		*w = decoded
+		return nil
+	}
+	if err := json.Unmarshal(data, (*[]time.Weekday)(&decoded)); err != nil {
+		return fmt.Errorf("decode JSON column: %w", err)
+	}
+	*w = decoded
+	return nil
+}
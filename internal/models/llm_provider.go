@@ -11,6 +11,9 @@ type LLMProviderConfig struct {
 	DefaultModel string    `db:"default_model" json:"default_model,omitempty"`
 	IsDefault    bool      `db:"is_default" json:"is_default"`
 	IsActive     bool      `db:"is_active" json:"is_active"`
+	Priority     int       `db:"priority" json:"priority"`
+	FixturePath  string    `db:"fixture_path" json:"fixture_path,omitempty"`
+	RecordFrom   string    `db:"record_from" json:"record_from,omitempty"`
 	CreatedAt    time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
 }
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+type MilestoneStatus string
+
+const (
+	MilestoneStatusPending    MilestoneStatus = "pending"
+	MilestoneStatusInProgress MilestoneStatus = "in_progress"
+	MilestoneStatusCompleted  MilestoneStatus = "completed"
+)
+
+// Milestone is a checkpoint on the way to a goal, spanning a range of weeks
+// counted from the start of the plan. TotalTasks and CompletedTasks are
+// computed from the tasks linked to it when the milestone is loaded.
+type Milestone struct {
+	ID             string          `db:"id" json:"id"`
+	GoalID         string          `db:"goal_id" json:"goalId"`
+	Title          string          `db:"title" json:"title"`
+	Description    string          `db:"description" json:"description,omitempty"`
+	WeekStart      int             `db:"week_start" json:"weekStart"`
+	WeekEnd        int             `db:"week_end" json:"weekEnd"`
+	Status         MilestoneStatus `db:"status" json:"status"`
+	TotalTasks     int             `db:"total_tasks" json:"totalTasks"`
+	CompletedTasks int             `db:"completed_tasks" json:"completedTasks"`
+	CreatedAt      time.Time       `db:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time       `db:"updated_at" json:"updatedAt"`
+}
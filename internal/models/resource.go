@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+type ResourceStatus string
+
+const (
+	ResourceStatusToRead     ResourceStatus = "to_read"
+	ResourceStatusInProgress ResourceStatus = "in_progress"
+	ResourceStatusDone       ResourceStatus = "done"
+)
+
+// Resource is a learning resource attached to a goal and, optionally, to one
+// of its milestones or tasks.
+type Resource struct {
+	ID          string         `db:"id" json:"id"`
+	GoalID      string         `db:"goal_id" json:"goalId"`
+	MilestoneID *string        `db:"milestone_id" json:"milestoneId,omitempty"`
+	TaskID      *string        `db:"task_id" json:"taskId,omitempty"`
+	Title       string         `db:"title" json:"title"`
+	Type        string         `db:"type" json:"type,omitempty"`
+	URL         string         `db:"url" json:"url,omitempty"`
+	Status      ResourceStatus `db:"status" json:"status"`
+	Notes       string         `db:"notes" json:"notes,omitempty"`
+	CreatedAt   time.Time      `db:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time      `db:"updated_at" json:"updatedAt"`
+}
@@ -0,0 +1,30 @@
+package models
+
+// RescheduleMove proposes a new due day for one task. NewDue is empty when
+// no day within the planning horizon had room for the task.
+type RescheduleMove struct {
+	TaskID   string `json:"taskId"`
+	GoalID   string `json:"goalId"`
+	Title    string `json:"title"`
+	Priority int    `json:"priority"`
+	Minutes  int    `json:"minutes"`
+	OldDue   string `json:"oldDue"`
+	NewDue   string `json:"newDue,omitempty"`
+}
+
+// DayLoad is the planned task time on one day against its capacity.
+type DayLoad struct {
+	Day      string `json:"day"`
+	Minutes  int    `json:"minutes"`
+	Capacity int    `json:"capacity"`
+}
+
+// ReschedulePlan is a proposed redistribution of overdue tasks, shown to
+// the user before it is applied. GoalID is the goal it was made for, empty
+// for all goals. Load covers each day tasks were moved to.
+type ReschedulePlan struct {
+	GoalID      string            `json:"goalId,omitempty"`
+	Moves       []*RescheduleMove `json:"moves"`
+	Unscheduled []*RescheduleMove `json:"unscheduled"`
+	Load        []*DayLoad        `json:"load"`
+}
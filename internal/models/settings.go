@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+	// Bundled so IANA zone names resolve on systems without zoneinfo files.
+	_ "time/tzdata"
+)
+
+// UserSettings holds app-wide preferences. TimeZone is an IANA zone name,
+// empty for the system zone; "today" and due days are computed in it.
+// RestDays are weekdays that neither count toward nor break a streak, and
+// GraceDays is how many missed days in a row a streak survives.
+// DailyMinutes is how much task time fits in one day when work is
+// rescheduled.
+type UserSettings struct {
+	TimeZone     string    `db:"time_zone" json:"timeZone"`
+	RestDays     Weekdays  `db:"rest_days" json:"restDays"`
+	GraceDays    int       `db:"grace_days" json:"graceDays"`
+	DailyMinutes int       `db:"daily_minutes" json:"dailyMinutes"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// Location returns the user's time zone, falling back to the system zone
+// when none is set or the name is unknown.
+func (s *UserSettings) Location() *time.Location {
+	if s.TimeZone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+func DefaultUserSettings() *UserSettings {
+	return &UserSettings{RestDays: Weekdays{}, GraceDays: 1, DailyMinutes: 60}
+}
+
+func (s *UserSettings) IsRestDay(day time.Weekday) bool {
+	for _, rest := range s.RestDays {
+		if rest == day {
+			return true
+		}
+	}
+	return false
+}
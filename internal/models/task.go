@@ -13,6 +13,17 @@ const (
 	TaskStatusSkipped    TaskStatus = "skipped"
 )
 
+// DueKind says how a task's DueDate is read. A date-only due date is a
+// calendar day, stored as midnight UTC of that date, and is due on that day
+// wherever the user is. A datetime due date is an exact instant, stored in
+// UTC, whose day depends on the user's time zone.
+type DueKind string
+
+const (
+	DueKindDate     DueKind = "date"
+	DueKindDateTime DueKind = "datetime"
+)
+
 type Task struct {
 	ID               string     `db:"id" json:"id"`
 	GoalID           string     `db:"goal_id" json:"goalId"`
@@ -20,13 +31,73 @@ type Task struct {
 	Title            string     `db:"title" json:"title"`
 	Description      string     `db:"description" json:"description,omitempty"`
 	DueDate          *time.Time `db:"due_date" json:"dueDate,omitempty"`
+	DueKind          DueKind    `db:"due_kind" json:"dueKind,omitempty"`
 	Status           TaskStatus `db:"status" json:"status"`
 	Priority         int        `db:"priority" json:"priority"`
 	DifficultyRating *int       `db:"difficulty_rating" json:"difficultyRating,omitempty"`
 	EstimatedMinutes *int       `db:"estimated_minutes" json:"estimatedMinutes,omitempty"`
 	ActualMinutes    *int       `db:"actual_minutes" json:"actualMinutes,omitempty"`
 	StruggleNotes    string     `db:"struggle_notes" json:"struggleNotes,omitempty"`
+	MilestoneID      *string    `db:"milestone_id" json:"milestoneId,omitempty"`
 	CompletedAt      *time.Time `db:"completed_at" json:"completedAt,omitempty"`
 	CreatedAt        time.Time  `db:"created_at" json:"createdAt"`
 	UpdatedAt        time.Time  `db:"updated_at" json:"updatedAt"`
+	BlockedBy        []string   `db:"-" json:"blockedBy,omitempty"`
+}
+
+// NormalizeDue puts DueDate in its stored form: the calendar date at
+// midnight UTC for date-only due dates, UTC for datetimes. A date-only due
+// date keeps the year, month and day it has in its own location.
+func (t *Task) NormalizeDue() {
+	if t.DueKind != DueKindDateTime {
+		t.DueKind = DueKindDate
+	}
+	if t.DueDate == nil {
+		return
+	}
+	due := t.DueDate.UTC()
+	if t.DueKind == DueKindDate {
+		due = CalendarDate(*t.DueDate)
+	}
+	t.DueDate = &due
+}
+
+// DueDay returns the calendar day the task is due on in loc, as midnight
+// UTC of that date.
+func (t *Task) DueDay(loc *time.Location) (time.Time, bool) {
+	if t.DueDate == nil {
+		return time.Time{}, false
+	}
+	if t.DueKind == DueKindDateTime {
+		return CalendarDate(t.DueDate.In(loc)), true
+	}
+	return CalendarDate(*t.DueDate), true
+}
+
+// MoveDueDay reschedules the task to day, keeping the local time of day of
+// a datetime due date.
+func (t *Task) MoveDueDay(day time.Time, loc *time.Location) time.Time {
+	due := CalendarDate(day)
+	if t.DueKind == DueKindDateTime && t.DueDate != nil {
+		local := t.DueDate.In(loc)
+		due = time.Date(day.Year(), day.Month(), day.Day(), local.Hour(), local.Minute(), local.Second(), 0, loc).UTC()
+	}
+	return due
+}
+
+// FormatDue renders the due date for prompts and tool results in loc.
+func (t *Task) FormatDue(loc *time.Location) string {
+	if t.DueDate == nil {
+		return ""
+	}
+	if t.DueKind == DueKindDateTime {
+		return t.DueDate.In(loc).Format("2006-01-02 15:04")
+	}
+	return t.DueDate.Format("2006-01-02")
+}
+
+// CalendarDate returns t's calendar date, in t's own location, as midnight
+// UTC.
+func CalendarDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
 }
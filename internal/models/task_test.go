@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskDueDayAcrossDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load zone: %v", err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("load zone: %v", err)
+	}
+
+	// A date-only due date is the same day everywhere.
+	dateOnly := &Task{DueDate: timePtr(time.Date(2026, 3, 8, 0, 0, 0, 0, time.FixedZone("UTC+2", 2*3600)))}
+	dateOnly.NormalizeDue()
+	for _, loc := range []*time.Location{ny, tokyo} {
+		if day, _ := dateOnly.DueDay(loc); day.Format("2006-01-02") != "2026-03-08" {
+			t.Errorf("date-only due day in %s = %s", loc, day.Format("2006-01-02"))
+		}
+	}
+
+	// 23:30 in New York on the spring-forward day is already the 9th in Tokyo.
+	exact := &Task{DueKind: DueKindDateTime, DueDate: timePtr(time.Date(2026, 3, 8, 23, 30, 0, 0, ny))}
+	exact.NormalizeDue()
+	if exact.DueDate.Location() != time.UTC {
+		t.Errorf("datetime stored in %s, want UTC", exact.DueDate.Location())
+	}
+	if day, _ := exact.DueDay(ny); day.Format("2006-01-02") != "2026-03-08" {
+		t.Errorf("due day in New York = %s", day.Format("2006-01-02"))
+	}
+	if day, _ := exact.DueDay(tokyo); day.Format("2006-01-02") != "2026-03-09" {
+		t.Errorf("due day in Tokyo = %s", day.Format("2006-01-02"))
+	}
+
+	// Moving across the change keeps the local time of day.
+	morning := &Task{DueKind: DueKindDateTime, DueDate: timePtr(time.Date(2026, 3, 6, 9, 0, 0, 0, ny).UTC())}
+	moved := morning.MoveDueDay(time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), ny)
+	if got := moved.In(ny).Format("2006-01-02 15:04"); got != "2026-03-10 09:00" {
+		t.Errorf("moved due = %s, want 2026-03-10 09:00", got)
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -1,5 +1,7 @@
 package models
 
+import "sort"
+
 // Passed to the LLM to describe the tool
 type Tool struct {
 	Type        string               `json:"type"`
@@ -8,11 +10,90 @@ type Tool struct {
 	Parameters  map[string]ToolParam `json:"parameters"`
 }
 
+// ToolParam is the JSON schema of a tool argument. Items describes the
+// elements of an array, Properties the fields of an object. Format accepts
+// "date" (YYYY-MM-DD), "date-time" (RFC 3339) and "uri".
 type ToolParam struct {
-	Type        string   `json:"type"`
-	Description string   `json:"description"`
-	Required    bool     `json:"required"`
-	Enum        []string `json:"enum"`
+	Type        string               `json:"type"`
+	Description string               `json:"description"`
+	Required    bool                 `json:"required"`
+	Enum        []string             `json:"enum"`
+	Items       *ToolParam           `json:"items,omitempty"`
+	Properties  map[string]ToolParam `json:"properties,omitempty"`
+	Format      string               `json:"format,omitempty"`
+	Minimum     *float64             `json:"minimum,omitempty"`
+	Maximum     *float64             `json:"maximum,omitempty"`
+	MinLength   *int                 `json:"minLength,omitempty"`
+	MinItems    *int                 `json:"minItems,omitempty"`
+	MaxItems    *int                 `json:"maxItems,omitempty"`
+}
+
+// JSONSchema returns the object schema describing the tool's arguments, as
+// sent to providers.
+func (t Tool) JSONSchema() map[string]any {
+	return objectSchema(t.Parameters)
+}
+
+func (p ToolParam) JSONSchema() map[string]any {
+	schema := map[string]any{"type": p.Type}
+	if p.Type == "object" {
+		schema = objectSchema(p.Properties)
+	}
+	if p.Description != "" {
+		schema["description"] = p.Description
+	}
+	if len(p.Enum) > 0 {
+		schema["enum"] = p.Enum
+	}
+	if p.Items != nil {
+		schema["items"] = p.Items.JSONSchema()
+	}
+	if p.Format != "" {
+		schema["format"] = p.Format
+	}
+	if p.Minimum != nil {
+		schema["minimum"] = *p.Minimum
+	}
+	if p.Maximum != nil {
+		schema["maximum"] = *p.Maximum
+	}
+	if p.MinLength != nil {
+		schema["minLength"] = *p.MinLength
+	}
+	if p.MinItems != nil {
+		schema["minItems"] = *p.MinItems
+	}
+	if p.MaxItems != nil {
+		schema["maxItems"] = *p.MaxItems
+	}
+	return schema
+}
+
+// requiredNames returns the names of the required properties, sorted.
+func requiredNames(properties map[string]ToolParam) []string {
+	required := make([]string, 0)
+	for name, param := range properties {
+		if param.Required {
+			required = append(required, name)
+		}
+	}
+	sort.Strings(required)
+	return required
+}
+
+func objectSchema(properties map[string]ToolParam) map[string]any {
+	props := make(map[string]any, len(properties))
+	for name, param := range properties {
+		props[name] = param.JSONSchema()
+	}
+	schema := map[string]any{
+		"type":       "object",
+		"properties": props,
+	}
+	if required := requiredNames(properties); len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
 }
 
 // Returned by the LLM to call the tool
@@ -25,4 +106,7 @@ type ToolCall struct {
 type ToolFunction struct {
 	Name      string         `json:"name"`
 	Arguments map[string]any `json:"arguments"`
+	// ArgumentsError is set instead of Arguments when the model's arguments
+	// were not valid JSON, so the call can be answered with the parse error.
+	ArgumentsError string `json:"arguments_error,omitempty"`
 }
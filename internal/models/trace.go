@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// TurnStep is one message exchanged inside the agent loop of a turn: an
+// assistant message (with the tool calls it requested) or a tool result.
+// The steps of a turn share its TurnID, the ID of the assistant conversation
+// entry holding the final response.
+type TurnStep struct {
+	ID         int64      `db:"id" json:"id"`
+	TurnID     string     `db:"turn_id" json:"turnId"`
+	GoalID     string     `db:"goal_id" json:"goalId"`
+	AgentType  AgentType  `db:"agent_type" json:"agentType"`
+	Seq        int        `db:"seq" json:"seq"`
+	Role       Role       `db:"role" json:"role"`
+	Content    string     `db:"content" json:"content"`
+	ToolCalls  []ToolCall `db:"-" json:"toolCalls,omitempty"`
+	ToolCallID string     `db:"tool_call_id" json:"toolCallId,omitempty"`
+	ToolName   string     `db:"tool_name" json:"toolName,omitempty"`
+	Error      string     `db:"error" json:"error,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"createdAt"`
+}
@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// LLMUsage is one LLM call recorded in the usage ledger.
+type LLMUsage struct {
+	ID               int64     `db:"id" json:"id"`
+	Provider         string    `db:"provider" json:"provider"`
+	Model            string    `db:"model" json:"model"`
+	AgentType        AgentType `db:"agent_type" json:"agent_type,omitempty"`
+	GoalID           string    `db:"goal_id" json:"goal_id,omitempty"`
+	Intent           string    `db:"intent" json:"intent,omitempty"`
+	PromptTokens     int       `db:"prompt_tokens" json:"prompt_tokens"`
+	CompletionTokens int       `db:"completion_tokens" json:"completion_tokens"`
+	TotalTokens      int       `db:"total_tokens" json:"total_tokens"`
+	CostUSD          float64   `db:"cost_usd" json:"cost_usd"`
+	LatencyMS        int64     `db:"latency_ms" json:"latency_ms"`
+	FinishReason     string    `db:"finish_reason" json:"finish_reason,omitempty"`
+	Error            string    `db:"error" json:"error,omitempty"`
+	CreatedAt        time.Time `db:"created_at" json:"created_at"`
+}
+
+// ModelPrice is the per-million-token price used to cost LLM calls.
+type ModelPrice struct {
+	ID               int       `db:"id" json:"id"`
+	Model            string    `db:"model" json:"model"`
+	InputPerMillion  float64   `db:"input_per_million" json:"input_per_million"`
+	OutputPerMillion float64   `db:"output_per_million" json:"output_per_million"`
+	CreatedAt        time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (p *ModelPrice) Cost(promptTokens, completionTokens int) float64 {
+	return float64(promptTokens)/1e6*p.InputPerMillion + float64(completionTokens)/1e6*p.OutputPerMillion
+}
+
+type BudgetAction string
+
+const (
+	BudgetActionRefuse    BudgetAction = "refuse"
+	BudgetActionDowngrade BudgetAction = "downgrade"
+)
+
+// UsageBudget caps monthly LLM spend. Once the month-to-date cost reaches
+// MonthlyLimitUSD, calls are refused or sent to the downgrade model.
+type UsageBudget struct {
+	MonthlyLimitUSD   float64      `db:"monthly_limit_usd" json:"monthly_limit_usd"`
+	Action            BudgetAction `db:"action" json:"action"`
+	DowngradeProvider string       `db:"downgrade_provider" json:"downgrade_provider,omitempty"`
+	DowngradeModel    string       `db:"downgrade_model" json:"downgrade_model,omitempty"`
+	IsActive          bool         `db:"is_active" json:"is_active"`
+	UpdatedAt         time.Time    `db:"updated_at" json:"updated_at"`
+}
+
+type UsagePeriod string
+
+const (
+	UsagePeriodDay   UsagePeriod = "day"
+	UsagePeriodMonth UsagePeriod = "month"
+)
+
+type UsageGroupBy string
+
+const (
+	UsageGroupByGoal     UsageGroupBy = "goal"
+	UsageGroupByAgent    UsageGroupBy = "agent"
+	UsageGroupByModel    UsageGroupBy = "model"
+	UsageGroupByProvider UsageGroupBy = "provider"
+)
+
+// UsageQuery selects usage aggregates. From and To are inclusive YYYY-MM-DD
+// dates; empty values leave that side of the range open.
+type UsageQuery struct {
+	GroupBy UsageGroupBy `json:"group_by"`
+	Period  UsagePeriod  `json:"period"`
+	From    string       `json:"from,omitempty"`
+	To      string       `json:"to,omitempty"`
+}
+
+type UsageAggregate struct {
+	Period           string  `db:"period" json:"period"`
+	Key              string  `db:"key" json:"key"`
+	Calls            int     `db:"calls" json:"calls"`
+	PromptTokens     int     `db:"prompt_tokens" json:"prompt_tokens"`
+	CompletionTokens int     `db:"completion_tokens" json:"completion_tokens"`
+	TotalTokens      int     `db:"total_tokens" json:"total_tokens"`
+	CostUSD          float64 `db:"cost_usd" json:"cost_usd"`
+}
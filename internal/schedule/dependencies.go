@@ -0,0 +1,99 @@
+package schedule
+
+import (
+	"sort"
+	"time"
+
+	"agent-coach/internal/models"
+)
+
+// Dependencies links open tasks to their open prerequisites and dependents.
+// A nil *Dependencies has no links.
+type Dependencies struct {
+	prerequisites map[string][]*models.Task
+	dependents    map[string][]*models.Task
+}
+
+// NewDependencies indexes the dependencies between tasks. Dependencies on
+// tasks missing from tasks or no longer open are ignored.
+func NewDependencies(tasks []*models.Task, dependencies []*models.TaskDependency) *Dependencies {
+	d := &Dependencies{prerequisites: make(map[string][]*models.Task), dependents: make(map[string][]*models.Task)}
+	byID := make(map[string]*models.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+	for _, dependency := range dependencies {
+		task, prerequisite := byID[dependency.TaskID], byID[dependency.DependsOnID]
+		if task == nil || prerequisite == nil || !task.IsOpen() || !prerequisite.IsOpen() {
+			continue
+		}
+		d.prerequisites[task.ID] = append(d.prerequisites[task.ID], prerequisite)
+		d.dependents[prerequisite.ID] = append(d.dependents[prerequisite.ID], task)
+	}
+	return d
+}
+
+// Prerequisites returns the open tasks taskID depends on.
+func (d *Dependencies) Prerequisites(taskID string) []*models.Task {
+	if d == nil {
+		return nil
+	}
+	return d.prerequisites[taskID]
+}
+
+// Dependents returns the open tasks that depend on taskID.
+func (d *Dependencies) Dependents(taskID string) []*models.Task {
+	if d == nil {
+		return nil
+	}
+	return d.dependents[taskID]
+}
+
+// Earliest returns the first day the task may be due on: the latest due day
+// of its prerequisites, taken from days when they are in it and from their
+// due dates in loc otherwise. It is the zero time when nothing holds the task
+// back.
+func (d *Dependencies) Earliest(taskID string, days map[string]time.Time, loc *time.Location) time.Time {
+	var earliest time.Time
+	for _, prerequisite := range d.Prerequisites(taskID) {
+		day, ok := days[prerequisite.ID]
+		if !ok {
+			day, ok = prerequisite.DueDay(loc)
+		}
+		if ok && day.After(earliest) {
+			earliest = day
+		}
+	}
+	return earliest
+}
+
+// Sort orders tasks so that each comes after its prerequisites, keeping the
+// given order otherwise.
+func (d *Dependencies) Sort(tasks []*models.Task) []*models.Task {
+	rank := make(map[string]int, len(tasks))
+	var depth func(task *models.Task, seen map[string]bool) int
+	depth = func(task *models.Task, seen map[string]bool) int {
+		if r, ok := rank[task.ID]; ok {
+			return r
+		}
+		if seen[task.ID] {
+			return 0
+		}
+		seen[task.ID] = true
+		r := 0
+		for _, prerequisite := range d.Prerequisites(task.ID) {
+			if pr := depth(prerequisite, seen) + 1; pr > r {
+				r = pr
+			}
+		}
+		rank[task.ID] = r
+		return r
+	}
+	for _, task := range tasks {
+		depth(task, make(map[string]bool))
+	}
+
+	sorted := append([]*models.Task(nil), tasks...)
+	sort.SliceStable(sorted, func(i, j int) bool { return rank[sorted[i].ID] < rank[sorted[j].ID] })
+	return sorted
+}
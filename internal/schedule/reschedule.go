@@ -0,0 +1,168 @@
+// Package schedule places tasks on days without exceeding the user's daily
+// capacity.
+package schedule
+
+import (
+	"sort"
+	"time"
+
+	"agent-coach/internal/models"
+)
+
+const (
+	// DefaultTaskMinutes is assumed for tasks without an estimate.
+	DefaultTaskMinutes = 30
+	// Horizon is how many days ahead, counting today, work may be moved.
+	Horizon = 28
+
+	dayFormat = "2006-01-02"
+)
+
+// Capacity returns how many minutes of task work fit on a day, given as
+// midnight UTC of its date.
+type Capacity func(day time.Time) int
+
+// DailyCapacity gives every day the same capacity.
+func DailyCapacity(minutes int) Capacity {
+	return func(time.Time) int { return minutes }
+}
+
+// TaskMinutes is the time a task is planned to take.
+func TaskMinutes(task *models.Task) int {
+	if task.EstimatedMinutes != nil && *task.EstimatedMinutes > 0 {
+		return *task.EstimatedMinutes
+	}
+	return DefaultTaskMinutes
+}
+
+// Reschedule spreads overdue tasks over the days from today on. Tasks are
+// placed by priority, then by how long they have been overdue, each on the
+// first day with room for it (see Scheduler.FirstFit). upcoming are the open
+// tasks already due from today on; their time counts against each day's
+// capacity.
+//
+// No task is placed before one of its prerequisites in dependencies. A
+// prerequisite is placed before its dependents, and a dependent due before
+// the day its prerequisite moves to is moved as well, onto that day or
+// later even if no day has room for it.
+func Reschedule(overdue, upcoming []*models.Task, dependencies *Dependencies, capacity Capacity, today time.Time, loc *time.Location) *models.ReschedulePlan {
+	today = models.CalendarDate(today)
+	plan := &models.ReschedulePlan{Moves: []*models.RescheduleMove{}, Unscheduled: []*models.RescheduleMove{}}
+
+	scheduler := NewScheduler(capacity, upcoming, loc)
+	booked := make(map[string]bool, len(upcoming))
+	for _, task := range upcoming {
+		booked[task.ID] = true
+	}
+
+	pending := append([]*models.Task(nil), overdue...)
+	sort.SliceStable(pending, func(i, j int) bool {
+		if pending[i].Priority != pending[j].Priority {
+			return pending[i].Priority > pending[j].Priority
+		}
+		di, _ := pending[i].DueDay(loc)
+		dj, _ := pending[j].DueDay(loc)
+		return di.Before(dj)
+	})
+	queued := make(map[string]bool, len(pending))
+	for _, task := range pending {
+		queued[task.ID] = true
+	}
+
+	days := make(map[string]time.Time)
+	pushed := make(map[string]bool)
+	for len(pending) > 0 {
+		next := 0
+		for i, task := range pending {
+			if !waiting(task, dependencies, queued) {
+				next = i
+				break
+			}
+		}
+		task := pending[next]
+		pending = append(pending[:next], pending[next+1:]...)
+		delete(queued, task.ID)
+
+		minutes := TaskMinutes(task)
+		move := &models.RescheduleMove{
+			TaskID:   task.ID,
+			GoalID:   task.GoalID,
+			Title:    task.Title,
+			Priority: task.Priority,
+			Minutes:  minutes,
+			OldDue:   task.FormatDue(loc),
+		}
+
+		from := today
+		if earliest := dependencies.Earliest(task.ID, days, loc); earliest.After(from) {
+			from = earliest
+		}
+		day, ok := scheduler.FirstFit(from, minutes)
+		if !ok && pushed[task.ID] {
+			day, ok = from, true
+		}
+		if !ok {
+			plan.Unscheduled = append(plan.Unscheduled, move)
+			continue
+		}
+		scheduler.Book(day, minutes)
+		days[task.ID] = day
+		move.NewDue = day.Format(dayFormat)
+		plan.Moves = append(plan.Moves, move)
+
+		for _, dependent := range dependencies.Dependents(task.ID) {
+			if queued[dependent.ID] {
+				continue
+			}
+			current, placed := days[dependent.ID]
+			if !placed {
+				var hasDue bool
+				if current, hasDue = dependent.DueDay(loc); !hasDue {
+					continue
+				}
+			}
+			if !current.Before(day) {
+				continue
+			}
+			if placed || booked[dependent.ID] {
+				scheduler.Book(current, -TaskMinutes(dependent))
+			}
+			delete(days, dependent.ID)
+			delete(booked, dependent.ID)
+			plan.Moves = withoutMove(plan.Moves, dependent.ID)
+			plan.Unscheduled = withoutMove(plan.Unscheduled, dependent.ID)
+			pushed[dependent.ID] = true
+			queued[dependent.ID] = true
+			pending = append(pending, dependent)
+		}
+	}
+
+	touched := make(map[string]time.Time)
+	for _, move := range plan.Moves {
+		touched[move.NewDue] = days[move.TaskID]
+	}
+	for key, day := range touched {
+		plan.Load = append(plan.Load, &models.DayLoad{Day: key, Minutes: scheduler.Load(day), Capacity: scheduler.Capacity(day)})
+	}
+	sort.Slice(plan.Load, func(i, j int) bool { return plan.Load[i].Day < plan.Load[j].Day })
+	return plan
+}
+
+// waiting reports whether one of the task's prerequisites is still queued.
+func waiting(task *models.Task, dependencies *Dependencies, queued map[string]bool) bool {
+	for _, prerequisite := range dependencies.Prerequisites(task.ID) {
+		if queued[prerequisite.ID] {
+			return true
+		}
+	}
+	return false
+}
+
+func withoutMove(moves []*models.RescheduleMove, taskID string) []*models.RescheduleMove {
+	for i, move := range moves {
+		if move.TaskID == taskID {
+			return append(moves[:i], moves[i+1:]...)
+		}
+	}
+	return moves
+}
@@ -0,0 +1,112 @@
+package schedule
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"agent-coach/internal/models"
+)
+
+func task(id string, priority, minutes int, due string) *models.Task {
+	day, _ := time.Parse("2006-01-02", due)
+	t := &models.Task{ID: id, Title: id, Status: models.TaskStatusPending, Priority: priority, DueDate: &day, DueKind: models.DueKindDate}
+	if minutes > 0 {
+		t.EstimatedMinutes = &minutes
+	}
+	return t
+}
+
+func TestReschedule(t *testing.T) {
+	today := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)
+
+	overdue := []*models.Task{
+		task("low-old", 0, 30, "2026-03-01"),
+		task("high", 2, 45, "2026-03-08"),
+		task("low-recent", 0, 30, "2026-03-09"),
+		task("no-estimate", 1, 0, "2026-03-09"),
+	}
+	upcoming := []*models.Task{
+		task("today", 0, 40, "2026-03-10"),
+		task("tomorrow", 0, 60, "2026-03-11"),
+	}
+
+	plan := Reschedule(overdue, upcoming, nil, DailyCapacity(90), today, time.UTC)
+
+	got := make(map[string]string)
+	var order []string
+	for _, move := range plan.Moves {
+		got[move.TaskID] = move.NewDue
+		order = append(order, move.TaskID)
+	}
+	// 40 minutes are already booked today, so the 45-minute high-priority
+	// task fits today and the default 30-minute task tomorrow; the rest spill
+	// over in priority order, oldest first.
+	want := map[string]string{
+		"high":        "2026-03-10",
+		"no-estimate": "2026-03-11",
+		"low-old":     "2026-03-12",
+		"low-recent":  "2026-03-12",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("moves = %v, want %v", got, want)
+	}
+	if wantOrder := []string{"high", "no-estimate", "low-old", "low-recent"}; !reflect.DeepEqual(order, wantOrder) {
+		t.Errorf("order = %v, want %v", order, wantOrder)
+	}
+
+	wantLoad := []*models.DayLoad{
+		{Day: "2026-03-10", Minutes: 85, Capacity: 90},
+		{Day: "2026-03-11", Minutes: 90, Capacity: 90},
+		{Day: "2026-03-12", Minutes: 60, Capacity: 90},
+	}
+	if !reflect.DeepEqual(plan.Load, wantLoad) {
+		t.Errorf("load = %+v, want %+v", plan.Load, wantLoad)
+	}
+}
+
+func TestRescheduleOversizedAndUnschedulable(t *testing.T) {
+	today := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	upcoming := []*models.Task{task("today", 0, 10, "2026-03-10")}
+
+	plan := Reschedule([]*models.Task{task("marathon", 0, 300, "2026-03-01")}, upcoming, nil, DailyCapacity(60), today, time.UTC)
+	if len(plan.Moves) != 1 || plan.Moves[0].NewDue != "2026-03-11" {
+		t.Errorf("oversized task moves = %+v, want the first empty day", plan.Moves)
+	}
+
+	plan = Reschedule([]*models.Task{task("any", 0, 30, "2026-03-01")}, nil, nil, DailyCapacity(0), today, time.UTC)
+	if len(plan.Moves) != 0 || len(plan.Unscheduled) != 1 || plan.Unscheduled[0].OldDue != "2026-03-01" {
+		t.Errorf("plan without capacity = %+v", plan)
+	}
+}
+
+func TestRescheduleKeepsPrerequisitesFirst(t *testing.T) {
+	today := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	newDues := func(plan *models.ReschedulePlan) map[string]string {
+		got := make(map[string]string)
+		for _, move := range plan.Moves {
+			got[move.TaskID] = move.NewDue
+		}
+		return got
+	}
+
+	// The dependent has the higher priority but still goes after the task it
+	// depends on.
+	basics, generics := task("basics", 0, 60, "2026-03-01"), task("generics", 2, 60, "2026-03-02")
+	deps := NewDependencies([]*models.Task{basics, generics}, []*models.TaskDependency{{TaskID: "generics", DependsOnID: "basics"}})
+	plan := Reschedule([]*models.Task{basics, generics}, nil, deps, DailyCapacity(60), today, time.UTC)
+	want := map[string]string{"basics": "2026-03-10", "generics": "2026-03-11"}
+	if got := newDues(plan); !reflect.DeepEqual(got, want) {
+		t.Errorf("overdue pair = %v, want %v", got, want)
+	}
+
+	// Moving an overdue prerequisite past its upcoming dependent moves the
+	// dependent too.
+	setup, build := task("setup", 0, 60, "2026-03-05"), task("build", 0, 30, "2026-03-10")
+	deps = NewDependencies([]*models.Task{setup, build}, []*models.TaskDependency{{TaskID: "build", DependsOnID: "setup"}})
+	plan = Reschedule([]*models.Task{setup}, []*models.Task{build}, deps, DailyCapacity(60), today, time.UTC)
+	want = map[string]string{"setup": "2026-03-11", "build": "2026-03-12"}
+	if got := newDues(plan); !reflect.DeepEqual(got, want) {
+		t.Errorf("pushed dependent = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+package schedule
+
+import (
+	"time"
+
+	"agent-coach/internal/models"
+)
+
+// Scheduler tracks the planned task time per day against capacity. Days are
+// calendar dates; any time of day is ignored.
+type Scheduler struct {
+	capacity Capacity
+	load     map[string]int
+}
+
+// NewScheduler books the time of tasks on their due days in loc.
+func NewScheduler(capacity Capacity, tasks []*models.Task, loc *time.Location) *Scheduler {
+	s := &Scheduler{capacity: capacity, load: make(map[string]int)}
+	for _, task := range tasks {
+		if day, ok := task.DueDay(loc); ok {
+			s.Book(day, TaskMinutes(task))
+		}
+	}
+	return s
+}
+
+func (s *Scheduler) Load(day time.Time) int {
+	return s.load[day.Format(dayFormat)]
+}
+
+func (s *Scheduler) Capacity(day time.Time) int {
+	return s.capacity(models.CalendarDate(day))
+}
+
+// Fits reports whether minutes more work fit on day.
+func (s *Scheduler) Fits(day time.Time, minutes int) bool {
+	limit := s.Capacity(day)
+	return limit > 0 && s.Load(day)+minutes <= limit
+}
+
+func (s *Scheduler) Book(day time.Time, minutes int) {
+	s.load[day.Format(dayFormat)] += minutes
+}
+
+// FirstFit returns the first day from from on, within the horizon, where
+// minutes more work fit. A task longer than a whole day's capacity goes on
+// the first empty day with any capacity.
+func (s *Scheduler) FirstFit(from time.Time, minutes int) (time.Time, bool) {
+	from = models.CalendarDate(from)
+	for i := 0; i < Horizon; i++ {
+		day := from.AddDate(0, 0, i)
+		if s.Fits(day, minutes) {
+			return day, true
+		}
+	}
+	for i := 0; i < Horizon; i++ {
+		day := from.AddDate(0, 0, i)
+		if s.Capacity(day) > 0 && s.Load(day) == 0 {
+			return day, true
+		}
+	}
+	return time.Time{}, false
+}
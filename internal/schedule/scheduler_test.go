@@ -0,0 +1,43 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"agent-coach/internal/models"
+)
+
+func TestSchedulerSkipsFullAndBlackoutDays(t *testing.T) {
+	availability := models.UniformAvailability(60)
+	availability.WeekdayMinutes[time.Sunday] = 0
+	availability.Blackouts = []*models.BlackoutDate{{Day: "2026-03-11", Reason: "travel"}}
+
+	booked := []*models.Task{task("existing", 0, 45, "2026-03-10")}
+	s := NewScheduler(availability.MinutesOn, booked, time.UTC)
+
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	if s.Fits(day(10), 30) {
+		t.Error("30 more minutes fit on a day with 45 of 60 booked")
+	}
+	if !s.Fits(day(10), 15) {
+		t.Error("15 more minutes do not fit on a day with 45 of 60 booked")
+	}
+	if s.Capacity(day(11)) != 0 {
+		t.Errorf("blackout capacity = %d, want 0", s.Capacity(day(11)))
+	}
+
+	// 2026-03-15 is a Sunday with no availability.
+	s.Book(day(12), 60)
+	s.Book(day(13), 60)
+	s.Book(day(14), 60)
+	got, ok := s.FirstFit(day(10), 30)
+	if !ok || !got.Equal(day(16)) {
+		t.Errorf("FirstFit = %s, %v, want 2026-03-16", got.Format(dayFormat), ok)
+	}
+
+	// A task longer than any day goes on the first empty working day.
+	got, ok = s.FirstFit(day(10), 90)
+	if !ok || !got.Equal(day(16)) {
+		t.Errorf("FirstFit oversized = %s, %v, want 2026-03-16", got.Format(dayFormat), ok)
+	}
+}
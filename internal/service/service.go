@@ -2,30 +2,52 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
 
 	"agent-coach/internal/agent"
 	"agent-coach/internal/llm"
 	"agent-coach/internal/models"
+	"agent-coach/internal/schedule"
 	"agent-coach/internal/storage"
+	"agent-coach/internal/streak"
 )
 
 type Service struct {
-	db           *storage.DB
-	goalRepo     *storage.GoalRepository
-	taskRepo     *storage.TaskRepository
-	convRepo     *storage.ConversationRepository
-	llmRouter    *llm.Router
-	orchestrator *agent.Orchestrator
+	db            *storage.DB
+	goalRepo      *storage.GoalRepository
+	taskRepo      *storage.TaskRepository
+	milestoneRepo *storage.MilestoneRepository
+	resourceRepo  *storage.ResourceRepository
+	convRepo      *storage.ConversationRepository
+	traceRepo     *storage.TraceRepository
+	usageRepo     *storage.UsageRepository
+	activityRepo  *storage.ActivityRepository
+	settingsRepo  *storage.SettingsRepository
+	availRepo     *storage.AvailabilityRepository
+	depRepo       *storage.DependencyRepository
+	llmRouter     *llm.Router
+	orchestrator  *agent.Orchestrator
 }
 
 func NewService(db *storage.DB, router *llm.Router) *Service {
 	return &Service{
-		db:           db,
-		goalRepo:     storage.NewGoalRepository(db),
-		taskRepo:     storage.NewTaskRepository(db),
-		convRepo:     storage.NewConversationRepository(db),
-		llmRouter:    router,
-		orchestrator: agent.NewOrchestrator(db, router),
+		db:            db,
+		goalRepo:      storage.NewGoalRepository(db),
+		taskRepo:      storage.NewTaskRepository(db),
+		milestoneRepo: storage.NewMilestoneRepository(db),
+		resourceRepo:  storage.NewResourceRepository(db),
+		convRepo:      storage.NewConversationRepository(db),
+		traceRepo:     storage.NewTraceRepository(db),
+		usageRepo:     storage.NewUsageRepository(db),
+		activityRepo:  storage.NewActivityRepository(db),
+		settingsRepo:  storage.NewSettingsRepository(db),
+		availRepo:     storage.NewAvailabilityRepository(db),
+		depRepo:       storage.NewDependencyRepository(db),
+		llmRouter:     router,
+		orchestrator:  agent.NewOrchestrator(db, router),
 	}
 }
 
@@ -38,6 +60,10 @@ func (s *Service) GetGoal(ctx context.Context, id string) (*models.Goal, error)
 	return s.goalRepo.GetByID(ctx, id)
 }
 
+func (s *Service) ListGoals(ctx context.Context, query models.GoalQuery) (*models.GoalPage, error) {
+	return s.goalRepo.List(ctx, query)
+}
+
 func (s *Service) UpdateGoal(ctx context.Context, goal *models.Goal) error {
 	return s.goalRepo.Update(ctx, goal)
 }
@@ -60,8 +86,199 @@ func (s *Service) GetTasksByGoalID(ctx context.Context, goalID string) ([]*model
 	return s.taskRepo.GetByGoalID(ctx, goalID)
 }
 
+// GetDependencyGraph returns the goal's tasks and the dependencies between
+// them, with tasks waiting on open prerequisites marked as blocked.
+func (s *Service) GetDependencyGraph(ctx context.Context, goalID string) (*models.DependencyGraph, error) {
+	tasks, err := s.taskRepo.GetByGoalID(ctx, goalID)
+	if err != nil {
+		return nil, err
+	}
+	dependencies, err := s.depRepo.GetByGoalID(ctx, goalID)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewDependencyGraph(tasks, dependencies), nil
+}
+
+// AddTaskDependency records that taskID cannot start before dependsOnID is
+// done. It fails with storage.ErrDependencyCycle if dependsOnID already
+// waits on taskID.
+func (s *Service) AddTaskDependency(ctx context.Context, taskID, dependsOnID string) error {
+	return s.depRepo.Add(ctx, taskID, dependsOnID)
+}
+
+func (s *Service) RemoveTaskDependency(ctx context.Context, taskID, dependsOnID string) error {
+	return s.depRepo.Remove(ctx, taskID, dependsOnID)
+}
+
 func (s *Service) GetTodaysTasks(ctx context.Context) ([]*models.Task, error) {
-	return s.taskRepo.GetDueToday(ctx)
+	loc, err := s.location(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.taskRepo.GetDueToday(ctx, loc)
+}
+
+func (s *Service) GetThisWeeksTasks(ctx context.Context) ([]*models.Task, error) {
+	loc, err := s.location(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.taskRepo.GetDueThisWeek(ctx, loc)
+}
+
+// GetOverdueTasks returns open tasks past their due date, for one goal or
+// all goals when goalID is empty.
+func (s *Service) GetOverdueTasks(ctx context.Context, goalID string) ([]*models.Task, error) {
+	loc, err := s.location(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.taskRepo.GetOverdue(ctx, goalID, time.Now().In(loc))
+}
+
+// PreviewReschedule proposes new due days for overdue tasks without changing
+// anything. Every goal's upcoming tasks count against the user's
+// availability for each day.
+func (s *Service) PreviewReschedule(ctx context.Context, goalID string) (*models.ReschedulePlan, error) {
+	plan, _, _, err := s.planReschedule(ctx, goalID)
+	return plan, err
+}
+
+// ErrStalePlan is returned by ApplyReschedule when the tasks or availability
+// changed since the plan was previewed.
+var ErrStalePlan = errors.New("the schedule changed since this plan was previewed; preview it again")
+
+// ApplyReschedule moves tasks to the days in a previewed plan, from which
+// the user may have dropped moves. The plan is recomputed first so that only
+// days that are still free and not in the past are used: a move that no
+// longer matches fails with ErrStalePlan, and moves for tasks completed or
+// deleted since the preview are skipped. The moves are applied together or
+// not at all, and the moved tasks are returned.
+func (s *Service) ApplyReschedule(ctx context.Context, plan *models.ReschedulePlan) ([]*models.Task, error) {
+	current, dependencies, loc, err := s.planReschedule(ctx, plan.GoalID)
+	if err != nil {
+		return nil, err
+	}
+	planned := make(map[string]string, len(current.Moves))
+	for _, move := range current.Moves {
+		planned[move.TaskID] = move.NewDue
+	}
+
+	var days []time.Time
+	var moves []*models.RescheduleMove
+	for _, move := range plan.Moves {
+		newDue, ok := planned[move.TaskID]
+		if !ok {
+			continue
+		}
+		if newDue != move.NewDue {
+			return nil, ErrStalePlan
+		}
+		day, err := time.Parse("2006-01-02", newDue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid new due date for task %s: %w", move.TaskID, err)
+		}
+		days = append(days, day)
+		moves = append(moves, move)
+	}
+	if err := checkMoveOrder(moves, days, dependencies, loc); err != nil {
+		return nil, err
+	}
+
+	var moved []*models.Task
+	dueDates := make(map[string]time.Time, len(moves))
+	for i, move := range moves {
+		task, err := s.taskRepo.GetByID(ctx, move.TaskID)
+		if err != nil {
+			return nil, err
+		}
+		if task == nil {
+			continue
+		}
+
+		due := task.MoveDueDay(days[i], loc)
+		dueDates[task.ID] = due
+		task.DueDate = &due
+		moved = append(moved, task)
+	}
+	if err := s.taskRepo.Reschedule(ctx, dueDates); err != nil {
+		return nil, err
+	}
+	return moved, nil
+}
+
+// checkMoveOrder makes sure that the moves kept from a plan put no task
+// before one of its prerequisites, as when a move the plan needed to keep a
+// dependent after its prerequisite was dropped.
+func checkMoveOrder(moves []*models.RescheduleMove, days []time.Time, dependencies *schedule.Dependencies, loc *time.Location) error {
+	moved := make(map[string]time.Time, len(moves))
+	for i, move := range moves {
+		moved[move.TaskID] = days[i]
+	}
+	for _, move := range moves {
+		day := moved[move.TaskID]
+		if earliest := dependencies.Earliest(move.TaskID, moved, loc); earliest.After(day) {
+			return fmt.Errorf("%q cannot move to %s before a task it depends on", move.Title, move.NewDue)
+		}
+		for _, dependent := range dependencies.Dependents(move.TaskID) {
+			if _, ok := moved[dependent.ID]; ok {
+				continue
+			}
+			if due, ok := dependent.DueDay(loc); ok && due.Before(day) {
+				return fmt.Errorf("moving %q to %s also needs %q, which depends on it, moved", move.Title, move.NewDue, dependent.Title)
+			}
+		}
+	}
+	return nil
+}
+
+// planReschedule computes the reschedule plan for goalID from the current
+// tasks, their dependencies and the availability. It also returns the
+// dependencies and the time zone the plan was made in.
+func (s *Service) planReschedule(ctx context.Context, goalID string) (*models.ReschedulePlan, *schedule.Dependencies, *time.Location, error) {
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	loc := settings.Location()
+	now := time.Now().In(loc)
+
+	availability, err := s.availRepo.Get(ctx, settings.DailyMinutes)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	overdue, err := s.taskRepo.GetOverdue(ctx, goalID, now)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// Every goal's tasks take up the user's time, but only the goal's own
+	// tasks can depend on each other.
+	open, err := s.taskRepo.GetOpen(ctx, "")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	edges, err := s.depRepo.GetByGoalID(ctx, goalID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// A datetime task due earlier today is both overdue and due today.
+	isOverdue := make(map[string]bool, len(overdue))
+	for _, task := range overdue {
+		isOverdue[task.ID] = true
+	}
+	var upcoming []*models.Task
+	for _, task := range open {
+		if task.DueDate != nil && !isOverdue[task.ID] {
+			upcoming = append(upcoming, task)
+		}
+	}
+
+	dependencies := schedule.NewDependencies(open, edges)
+	plan := schedule.Reschedule(overdue, upcoming, dependencies, availability.MinutesOn, now, loc)
+	plan.GoalID = goalID
+	return plan, dependencies, loc, nil
 }
 
 func (s *Service) CompleteTask(ctx context.Context, id string, actualMinutes *int) error {
@@ -72,9 +289,202 @@ func (s *Service) LogStruggle(ctx context.Context, id string, notes string) erro
 	return s.taskRepo.LogStruggle(ctx, id, notes)
 }
 
+// Milestone Operations
+
+func (s *Service) GetMilestonesByGoalID(ctx context.Context, goalID string) ([]*models.Milestone, error) {
+	return s.milestoneRepo.GetByGoalID(ctx, goalID)
+}
+
+func (s *Service) UpdateMilestone(ctx context.Context, milestone *models.Milestone) error {
+	if milestone.WeekStart < 1 || milestone.WeekEnd < milestone.WeekStart {
+		return fmt.Errorf("invalid week range %d-%d", milestone.WeekStart, milestone.WeekEnd)
+	}
+	switch milestone.Status {
+	case models.MilestoneStatusPending, models.MilestoneStatusInProgress, models.MilestoneStatusCompleted:
+	default:
+		return fmt.Errorf("invalid milestone status: %s", milestone.Status)
+	}
+	return s.milestoneRepo.Update(ctx, milestone)
+}
+
+func (s *Service) DeleteMilestone(ctx context.Context, id string) error {
+	return s.milestoneRepo.Delete(ctx, id)
+}
+
+// Resource Operations
+
+func (s *Service) GetResourcesByGoalID(ctx context.Context, goalID string) ([]*models.Resource, error) {
+	return s.resourceRepo.GetByGoalID(ctx, goalID)
+}
+
+func (s *Service) GetResourcesForTask(ctx context.Context, taskID string) ([]*models.Resource, error) {
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, storage.ErrNotFound
+	}
+	return s.resourceRepo.GetForTask(ctx, task)
+}
+
+func (s *Service) UpdateResource(ctx context.Context, resource *models.Resource) error {
+	switch resource.Status {
+	case models.ResourceStatusToRead, models.ResourceStatusInProgress, models.ResourceStatusDone:
+	default:
+		return fmt.Errorf("invalid resource status: %s", resource.Status)
+	}
+	return s.resourceRepo.Update(ctx, resource)
+}
+
+func (s *Service) DeleteResource(ctx context.Context, id string) error {
+	return s.resourceRepo.Delete(ctx, id)
+}
+
+// Activity Operations
+
+// GetStreak returns the current and longest streak for a goal, or across all
+// goals when goalID is empty.
+func (s *Service) GetStreak(ctx context.Context, goalID string) (*models.Streak, error) {
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	days, err := s.activityRepo.GetDays(ctx, goalID, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return streak.Compute(days, settings, time.Now().In(settings.Location())), nil
+}
+
+// GetActivityCalendar returns one entry per day between from and to
+// (inclusive YYYY-MM-DD dates). An empty from means a year before to, and an
+// empty to means today.
+func (s *Service) GetActivityCalendar(ctx context.Context, goalID string, from, to string) ([]*models.DailyActivity, error) {
+	loc, err := s.location(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	end := time.Now().In(loc)
+	if to != "" {
+		day, err := time.ParseInLocation("2006-01-02", to, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to date: %w", err)
+		}
+		end = day
+	}
+	start := end.AddDate(-1, 0, 1)
+	if from != "" {
+		day, err := time.ParseInLocation("2006-01-02", from, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from date: %w", err)
+		}
+		start = day
+	}
+	if start.After(end) {
+		return nil, fmt.Errorf("from date %s is after to date %s", start.Format("2006-01-02"), end.Format("2006-01-02"))
+	}
+
+	days, err := s.activityRepo.GetDays(ctx, goalID, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	return streak.Calendar(days, goalID, start, end), nil
+}
+
+// Settings Operations
+
+func (s *Service) GetUserSettings(ctx context.Context) (*models.UserSettings, error) {
+	return s.settingsRepo.Get(ctx)
+}
+
+func (s *Service) SaveUserSettings(ctx context.Context, settings *models.UserSettings) error {
+	if settings.TimeZone != "" {
+		if _, err := time.LoadLocation(settings.TimeZone); err != nil {
+			return fmt.Errorf("unknown time zone %q", settings.TimeZone)
+		}
+	}
+	if settings.GraceDays < 0 || settings.GraceDays > 7 {
+		return fmt.Errorf("grace days must be between 0 and 7, got %d", settings.GraceDays)
+	}
+	if settings.DailyMinutes < 1 || settings.DailyMinutes > 24*60 {
+		return fmt.Errorf("daily minutes must be between 1 and %d, got %d", 24*60, settings.DailyMinutes)
+	}
+	seen := make(map[time.Weekday]bool)
+	for _, day := range settings.RestDays {
+		if day < time.Sunday || day > time.Saturday {
+			return fmt.Errorf("invalid rest day: %d", day)
+		}
+		seen[day] = true
+	}
+	if len(seen) == 7 {
+		return fmt.Errorf("at least one day of the week must not be a rest day")
+	}
+
+	previous, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.settingsRepo.Save(ctx, settings); err != nil {
+		return err
+	}
+	// Activity days are taken in the user's time zone, so they move with it.
+	if previous.TimeZone != settings.TimeZone || !reflect.DeepEqual(previous.RestDays, settings.RestDays) {
+		return s.activityRepo.Rebuild(ctx, settings.Location())
+	}
+	return nil
+}
+
+// PrepareActivity fills the daily activity table for a database that has
+// history from before activity was tracked.
+func (s *Service) PrepareActivity(ctx context.Context) error {
+	loc, err := s.location(ctx)
+	if err != nil {
+		return err
+	}
+	return s.activityRepo.EnsureBuilt(ctx, loc)
+}
+
+// GetAvailability returns the minutes available for tasks on each weekday,
+// falling back to the daily minutes setting, and the blackout dates.
+func (s *Service) GetAvailability(ctx context.Context) (*models.Availability, error) {
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.availRepo.Get(ctx, settings.DailyMinutes)
+}
+
+func (s *Service) SaveAvailability(ctx context.Context, availability *models.Availability) error {
+	if len(availability.WeekdayMinutes) != 7 {
+		return fmt.Errorf("weekday minutes must have 7 entries, got %d", len(availability.WeekdayMinutes))
+	}
+	for day, minutes := range availability.WeekdayMinutes {
+		if minutes < 0 || minutes > 24*60 {
+			return fmt.Errorf("minutes for %s must be between 0 and %d, got %d", time.Weekday(day), 24*60, minutes)
+		}
+	}
+	for _, blackout := range availability.Blackouts {
+		if _, err := time.Parse("2006-01-02", blackout.Day); err != nil {
+			return fmt.Errorf("invalid blackout date %q, use YYYY-MM-DD", blackout.Day)
+		}
+	}
+	return s.availRepo.Save(ctx, availability)
+}
+
+// location returns the user's time zone.
+func (s *Service) location(ctx context.Context) (*time.Location, error) {
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return settings.Location(), nil
+}
+
 // Chat Operations
-func (s *Service) Chat(ctx context.Context, message string, goalID string) (*agent.AgentOutput, error) {
-	return s.orchestrator.ProcessMessage(ctx, message, goalID)
+func (s *Service) Chat(ctx context.Context, message string, goalID string, onEvent agent.EventHandler) (*agent.AgentOutput, error) {
+	return s.orchestrator.ProcessMessage(ctx, message, goalID, onEvent)
 }
 
 func (s *Service) StartOnboarding(ctx context.Context, coachID string, goalTitle string) (*agent.AgentOutput, error) {
@@ -84,3 +494,43 @@ func (s *Service) StartOnboarding(ctx context.Context, coachID string, goalTitle
 func (s *Service) GetConversationHistory(ctx context.Context, goalID string, limit int) ([]*models.Conversation, error) {
 	return s.convRepo.GetByGoalID(ctx, goalID, limit)
 }
+
+func (s *Service) GetTurnTrace(ctx context.Context, turnID string) ([]*models.TurnStep, error) {
+	return s.traceRepo.GetByTurnID(ctx, turnID)
+}
+
+// Usage Operations
+
+func (s *Service) GetUsageSummary(ctx context.Context, query models.UsageQuery) ([]*models.UsageAggregate, error) {
+	return s.usageRepo.Aggregate(ctx, query)
+}
+
+func (s *Service) GetMonthToDateCost(ctx context.Context) (float64, error) {
+	now := time.Now().UTC()
+	return s.usageRepo.CostSince(ctx, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC))
+}
+
+func (s *Service) GetModelPrices(ctx context.Context) ([]*models.ModelPrice, error) {
+	return s.usageRepo.ListPrices(ctx)
+}
+
+func (s *Service) SaveModelPrice(ctx context.Context, price *models.ModelPrice) error {
+	return s.usageRepo.SavePrice(ctx, price)
+}
+
+func (s *Service) DeleteModelPrice(ctx context.Context, id int) error {
+	return s.usageRepo.DeletePrice(ctx, id)
+}
+
+func (s *Service) GetUsageBudget(ctx context.Context) (*models.UsageBudget, error) {
+	return s.usageRepo.GetBudget(ctx)
+}
+
+func (s *Service) SaveUsageBudget(ctx context.Context, budget *models.UsageBudget) error {
+	switch budget.Action {
+	case models.BudgetActionRefuse, models.BudgetActionDowngrade:
+	default:
+		return fmt.Errorf("invalid budget action: %q", budget.Action)
+	}
+	return s.usageRepo.SaveBudget(ctx, budget)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"agent-coach/internal/llm"
+	"agent-coach/internal/models"
+	"agent-coach/internal/storage"
+)
+
+func newTestService(t *testing.T) (*Service, *storage.DB) {
+	t.Helper()
+
+	db, err := storage.Open(":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Migrate(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	router, err := llm.NewRouter(db)
+	if err != nil {
+		t.Fatalf("new router: %v", err)
+	}
+	return NewService(db, router), db
+}
+
+func TestApplyRescheduleRejectsStalePlans(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+	if err := svc.SaveUserSettings(ctx, &models.UserSettings{TimeZone: "UTC", GraceDays: 1, DailyMinutes: 60}); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+
+	today := models.CalendarDate(time.Now().UTC())
+	yesterday := today.AddDate(0, 0, -1)
+	minutes := 45
+	overdue := &models.Task{GoalID: "g1", Title: "overdue", Status: models.TaskStatusPending, DueDate: &yesterday, EstimatedMinutes: &minutes}
+	if err := svc.CreateTask(ctx, overdue); err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	plan, err := svc.PreviewReschedule(ctx, "g1")
+	if err != nil {
+		t.Fatalf("PreviewReschedule: %v", err)
+	}
+	if len(plan.Moves) != 1 || plan.Moves[0].NewDue != today.Format("2006-01-02") {
+		t.Fatalf("plan moves = %+v, want the overdue task today", plan.Moves)
+	}
+
+	tampered := *plan
+	tampered.Moves = []*models.RescheduleMove{{TaskID: overdue.ID, NewDue: yesterday.Format("2006-01-02")}}
+	if _, err := svc.ApplyReschedule(ctx, &tampered); !errors.Is(err, ErrStalePlan) {
+		t.Errorf("past day: error = %v, want ErrStalePlan", err)
+	}
+
+	// Booking today after the preview leaves no room for the planned move.
+	filler := &models.Task{GoalID: "g1", Title: "filler", Status: models.TaskStatusPending, DueDate: &today, EstimatedMinutes: &minutes}
+	if err := svc.CreateTask(ctx, filler); err != nil {
+		t.Fatalf("create filler: %v", err)
+	}
+	if _, err := svc.ApplyReschedule(ctx, plan); !errors.Is(err, ErrStalePlan) {
+		t.Errorf("full day: error = %v, want ErrStalePlan", err)
+	}
+
+	plan, err = svc.PreviewReschedule(ctx, "g1")
+	if err != nil {
+		t.Fatalf("PreviewReschedule: %v", err)
+	}
+	moved, err := svc.ApplyReschedule(ctx, plan)
+	if err != nil || len(moved) != 1 || moved[0].FormatDue(time.UTC) != today.AddDate(0, 0, 1).Format("2006-01-02") {
+		t.Errorf("ApplyReschedule = %+v, %v; want the task moved to tomorrow", moved, err)
+	}
+}
+
+func TestApplyRescheduleKeepsDependentsAfterPrerequisites(t *testing.T) {
+	ctx := context.Background()
+	svc, db := newTestService(t)
+	if err := svc.SaveUserSettings(ctx, &models.UserSettings{TimeZone: "UTC", GraceDays: 1, DailyMinutes: 60}); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+
+	today := models.CalendarDate(time.Now().UTC())
+	yesterday := today.AddDate(0, 0, -1)
+	minutes := 45
+	basics := &models.Task{GoalID: "g1", Title: "basics", Status: models.TaskStatusPending, DueDate: &yesterday, EstimatedMinutes: &minutes}
+	project := &models.Task{GoalID: "g1", Title: "project", Status: models.TaskStatusPending, DueDate: &today, EstimatedMinutes: &minutes}
+	for _, task := range []*models.Task{basics, project} {
+		if err := svc.CreateTask(ctx, task); err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+	}
+	if err := storage.NewDependencyRepository(db).Add(ctx, project.ID, basics.ID); err != nil {
+		t.Fatalf("add dependency: %v", err)
+	}
+
+	plan, err := svc.PreviewReschedule(ctx, "g1")
+	if err != nil {
+		t.Fatalf("PreviewReschedule: %v", err)
+	}
+	days := make(map[string]string)
+	for _, move := range plan.Moves {
+		days[move.TaskID] = move.NewDue
+	}
+	if days[basics.ID] == "" || days[project.ID] < days[basics.ID] {
+		t.Fatalf("plan moves = %v, want project no earlier than basics", days)
+	}
+
+	// Dropping the dependent's move would leave it before its prerequisite.
+	trimmed := *plan
+	trimmed.Moves = nil
+	for _, move := range plan.Moves {
+		if move.TaskID == basics.ID {
+			trimmed.Moves = append(trimmed.Moves, move)
+		}
+	}
+	if _, err := svc.ApplyReschedule(ctx, &trimmed); err == nil {
+		t.Error("ApplyReschedule without the dependent's move succeeded")
+	}
+
+	if _, err := svc.ApplyReschedule(ctx, plan); err != nil {
+		t.Fatalf("ApplyReschedule: %v", err)
+	}
+}
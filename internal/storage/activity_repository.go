@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"agent-coach/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ActivityRepository struct {
+	db *DB
+}
+
+func NewActivityRepository(db *DB) *ActivityRepository {
+	return &ActivityRepository{db: db}
+}
+
+// Rebuild recomputes the daily activity table from completed tasks and
+// check-ins, bucketing each event by its calendar day in loc. The task and
+// check-in repositories keep the table up to date as events happen, so it
+// only needs rebuilding when the days themselves move, such as after a time
+// zone change.
+func (r *ActivityRepository) Rebuild(ctx context.Context, loc *time.Location) error {
+	var completions []struct {
+		GoalID        string    `db:"goal_id"`
+		CompletedAt   time.Time `db:"completed_at"`
+		ActualMinutes *int      `db:"actual_minutes"`
+	}
+	err := r.db.SelectContext(ctx, &completions, `
+		SELECT goal_id, completed_at, actual_minutes
+		FROM tasks WHERE status = 'completed' AND completed_at IS NOT NULL
+	`)
+	if err != nil {
+		return err
+	}
+
+	var checkIns []struct {
+		GoalID    string    `db:"goal_id"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+	if err := r.db.SelectContext(ctx, &checkIns, `SELECT goal_id, created_at FROM checkins`); err != nil {
+		return err
+	}
+
+	days := make(map[[2]string]*models.DailyActivity)
+	dayOf := func(goalID string, at time.Time) *models.DailyActivity {
+		key := [2]string{goalID, at.In(loc).Format("2006-01-02")}
+		day, ok := days[key]
+		if !ok {
+			day = &models.DailyActivity{GoalID: key[0], Day: key[1]}
+			days[key] = day
+		}
+		return day
+	}
+	for _, c := range completions {
+		day := dayOf(c.GoalID, c.CompletedAt)
+		day.TasksCompleted++
+		if c.ActualMinutes != nil {
+			day.MinutesSpent += *c.ActualMinutes
+		}
+	}
+	for _, c := range checkIns {
+		dayOf(c.GoalID, c.CreatedAt).CheckIns++
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM daily_activity`); err != nil {
+		return err
+	}
+	for _, day := range days {
+		_, err := tx.NamedExecContext(ctx, `
+			INSERT INTO daily_activity (goal_id, day, tasks_completed, minutes_spent, checkins)
+			VALUES (:goal_id, :day, :tasks_completed, :minutes_spent, :checkins)
+		`, day)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// EnsureBuilt rebuilds the table if it is empty while there are completed
+// tasks or check-ins, as in a database created before activity was tracked.
+func (r *ActivityRepository) EnsureBuilt(ctx context.Context, loc *time.Location) error {
+	var missing bool
+	err := r.db.GetContext(ctx, &missing, `
+		SELECT NOT EXISTS (SELECT 1 FROM daily_activity) AND (
+			EXISTS (SELECT 1 FROM tasks WHERE status = 'completed' AND completed_at IS NOT NULL)
+			OR EXISTS (SELECT 1 FROM checkins)
+		)
+	`)
+	if err != nil || !missing {
+		return err
+	}
+	return r.Rebuild(ctx, loc)
+}
+
+// GetDays returns the active days between from and to (inclusive YYYY-MM-DD
+// dates, empty for an open end) in date order. An empty goalID sums the
+// activity of every goal.
+func (r *ActivityRepository) GetDays(ctx context.Context, goalID string, from, to string) ([]*models.DailyActivity, error) {
+	if to == "" {
+		to = "9999-12-31"
+	}
+
+	var days []*models.DailyActivity
+	var err error
+	if goalID == "" {
+		err = r.db.SelectContext(ctx, &days, `
+			SELECT '' AS goal_id, day, SUM(tasks_completed) AS tasks_completed,
+				SUM(minutes_spent) AS minutes_spent, SUM(checkins) AS checkins
+			FROM daily_activity WHERE day >= ? AND day <= ?
+			GROUP BY day ORDER BY day ASC
+		`, from, to)
+	} else {
+		err = r.db.SelectContext(ctx, &days, `
+			SELECT goal_id, day, tasks_completed, minutes_spent, checkins
+			FROM daily_activity WHERE goal_id = ? AND day >= ? AND day <= ?
+			ORDER BY day ASC
+		`, goalID, from, to)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+// activityLocation returns the time zone activity is bucketed in: the user's
+// saved time zone, or the local one.
+func activityLocation(ctx context.Context, q sqlx.QueryerContext) (*time.Location, error) {
+	var settings models.UserSettings
+	err := sqlx.GetContext(ctx, q, &settings.TimeZone, `SELECT time_zone FROM user_settings WHERE id = 1`)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	return settings.Location(), nil
+}
+
+// addActivity adds the counts, which may be negative, to the goal's activity
+// on the calendar day of at, dropping the day once nothing is left on it.
+func addActivity(ctx context.Context, tx sqlx.ExtContext, goalID string, at time.Time, tasks, minutes, checkIns int) error {
+	loc, err := activityLocation(ctx, tx)
+	if err != nil {
+		return err
+	}
+	day := at.In(loc).Format("2006-01-02")
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO daily_activity (goal_id, day, tasks_completed, minutes_spent, checkins)
+		VALUES (?, ?, MAX(?, 0), MAX(?, 0), MAX(?, 0))
+		ON CONFLICT(goal_id, day) DO UPDATE SET
+			tasks_completed = MAX(tasks_completed + ?, 0),
+			minutes_spent = MAX(minutes_spent + ?, 0),
+			checkins = MAX(checkins + ?, 0)
+	`, goalID, day, tasks, minutes, checkIns, tasks, minutes, checkIns)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM daily_activity
+		WHERE goal_id = ? AND day = ? AND tasks_completed = 0 AND minutes_spent = 0 AND checkins = 0
+	`, goalID, day)
+	return err
+}
+
+// completion is the part of a task that counts towards daily activity.
+type completion struct {
+	GoalID        string     `db:"goal_id"`
+	Status        string     `db:"status"`
+	CompletedAt   *time.Time `db:"completed_at"`
+	ActualMinutes *int       `db:"actual_minutes"`
+}
+
+// addCompletion adds (sign 1) or removes (sign -1) a task's completion from
+// daily activity. Tasks that are not completed count for nothing.
+func addCompletion(ctx context.Context, tx sqlx.ExtContext, c *completion, sign int) error {
+	if c == nil || c.Status != string(models.TaskStatusCompleted) || c.CompletedAt == nil {
+		return nil
+	}
+	minutes := 0
+	if c.ActualMinutes != nil {
+		minutes = *c.ActualMinutes
+	}
+	return addActivity(ctx, tx, c.GoalID, *c.CompletedAt, sign, sign*minutes, 0)
+}
+
+// getCompletion loads a task's completion, or nil if the task does not exist.
+func getCompletion(ctx context.Context, q sqlx.QueryerContext, taskID string) (*completion, error) {
+	var c completion
+	err := sqlx.GetContext(ctx, q, &c, `SELECT goal_id, status, completed_at, actual_minutes FROM tasks WHERE id = ?`, taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"agent-coach/internal/models"
+)
+
+func TestActivityRebuildBucketsByLocalDay(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	repo := NewActivityRepository(db)
+
+	// 02:30 UTC on the 11th is still the 10th five hours west of UTC.
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	minutes := 40
+	completed := func(id, goalID string, at time.Time, actual *int) {
+		t.Helper()
+		_, err := db.Exec(`
+			INSERT INTO tasks (id, goal_id, title, status, priority, actual_minutes, completed_at)
+			VALUES (?, ?, ?, 'completed', 0, ?, ?)
+		`, id, goalID, id, actual, at)
+		if err != nil {
+			t.Fatalf("insert task: %v", err)
+		}
+	}
+	completed("t1", "g1", time.Date(2026, 3, 11, 2, 30, 0, 0, time.UTC), &minutes)
+	completed("t2", "g1", time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC), nil)
+	completed("t3", "g2", time.Date(2026, 3, 10, 16, 0, 0, 0, time.UTC), &minutes)
+	if err := NewCheckInRepository(db).Create(ctx, &models.CheckIn{GoalID: "g1", Summary: "ok"}); err != nil {
+		t.Fatalf("create check-in: %v", err)
+	}
+
+	if err := repo.Rebuild(ctx, loc); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+
+	days, err := repo.GetDays(ctx, "", "2026-03-01", "2026-03-31")
+	if err != nil {
+		t.Fatalf("GetDays: %v", err)
+	}
+	want := []*models.DailyActivity{{Day: "2026-03-10", TasksCompleted: 3, MinutesSpent: 80}}
+	if !reflect.DeepEqual(days, want) {
+		t.Errorf("all goals = %+v, want %+v", days, want)
+	}
+
+	days, err = repo.GetDays(ctx, "g1", "", "")
+	if err != nil {
+		t.Fatalf("GetDays: %v", err)
+	}
+	if len(days) != 2 || days[0].TasksCompleted != 2 || days[1].CheckIns != 1 {
+		t.Errorf("g1 days = %+v", days)
+	}
+}
+
+func TestActivityFollowsTaskAndCheckInChanges(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	tasks, activity := NewTaskRepository(db), NewActivityRepository(db)
+
+	if err := NewSettingsRepository(db).Save(ctx, &models.UserSettings{TimeZone: "Asia/Tokyo", GraceDays: 1, DailyMinutes: 60}); err != nil {
+		t.Fatalf("save settings: %v", err)
+	}
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	today := time.Now().In(tokyo).Format("2006-01-02")
+	days := func() []*models.DailyActivity {
+		t.Helper()
+		days, err := activity.GetDays(ctx, "g1", "", "")
+		if err != nil {
+			t.Fatalf("GetDays: %v", err)
+		}
+		return days
+	}
+
+	task := &models.Task{GoalID: "g1", Title: "read", Status: models.TaskStatusPending}
+	if err := tasks.Create(ctx, task); err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	minutes := 25
+	if err := tasks.MarkComplete(ctx, task.ID, &minutes); err != nil {
+		t.Fatalf("MarkComplete: %v", err)
+	}
+	want := []*models.DailyActivity{{GoalID: "g1", Day: today, TasksCompleted: 1, MinutesSpent: 25}}
+	if got := days(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after completing = %+v, want %+v", got, want)
+	}
+
+	if err := NewCheckInRepository(db).Create(ctx, &models.CheckIn{GoalID: "g1", Summary: "ok"}); err != nil {
+		t.Fatalf("create check-in: %v", err)
+	}
+	reopened, _ := tasks.GetByID(ctx, task.ID)
+	reopened.Status, reopened.CompletedAt = models.TaskStatusPending, nil
+	if err := tasks.Update(ctx, reopened); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	want = []*models.DailyActivity{{GoalID: "g1", Day: today, CheckIns: 1}}
+	if got := days(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after reopening = %+v, want %+v", got, want)
+	}
+
+	// An incremental table matches a full rebuild.
+	if err := tasks.MarkComplete(ctx, task.ID, &minutes); err != nil {
+		t.Fatalf("MarkComplete: %v", err)
+	}
+	incremental := days()
+	if err := activity.Rebuild(ctx, tokyo); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	if got := days(); !reflect.DeepEqual(got, incremental) {
+		t.Errorf("rebuilt = %+v, incremental = %+v", got, incremental)
+	}
+
+	if err := tasks.Delete(ctx, task.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if got := days(); len(got) != 1 || got[0].TasksCompleted != 0 || got[0].CheckIns != 1 {
+		t.Errorf("after deleting = %+v, want only the check-in", got)
+	}
+}
+
+func TestActivityEnsureBuiltFillsEmptyTable(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	activity := NewActivityRepository(db)
+
+	_, err := db.Exec(`
+		INSERT INTO tasks (id, goal_id, title, status, priority, completed_at)
+		VALUES ('t1', 'g1', 'old', 'completed', 0, ?)
+	`, time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("insert task: %v", err)
+	}
+
+	if err := activity.EnsureBuilt(ctx, time.UTC); err != nil {
+		t.Fatalf("EnsureBuilt: %v", err)
+	}
+	days, err := activity.GetDays(ctx, "g1", "", "")
+	if err != nil || len(days) != 1 || days[0].Day != "2026-03-10" {
+		t.Errorf("days = %+v, %v; want 2026-03-10", days, err)
+	}
+}
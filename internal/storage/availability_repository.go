@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+
+	"agent-coach/internal/models"
+)
+
+type AvailabilityRepository struct {
+	db *DB
+}
+
+func NewAvailabilityRepository(db *DB) *AvailabilityRepository {
+	return &AvailabilityRepository{db: db}
+}
+
+// Get returns the user's availability. Weekdays without a saved value get
+// defaultMinutes, the daily minutes setting.
+func (r *AvailabilityRepository) Get(ctx context.Context, defaultMinutes int) (*models.Availability, error) {
+	availability := models.UniformAvailability(defaultMinutes)
+
+	var weekdays []struct {
+		Weekday int `db:"weekday"`
+		Minutes int `db:"minutes"`
+	}
+	if err := r.db.SelectContext(ctx, &weekdays, `SELECT weekday, minutes FROM weekday_availability`); err != nil {
+		return nil, err
+	}
+	for _, weekday := range weekdays {
+		availability.WeekdayMinutes[weekday.Weekday] = weekday.Minutes
+	}
+
+	if err := r.db.SelectContext(ctx, &availability.Blackouts, `SELECT day, reason FROM blackout_dates ORDER BY day ASC`); err != nil {
+		return nil, err
+	}
+	return availability, nil
+}
+
+// Save replaces the weekday minutes and blackout dates.
+func (r *AvailabilityRepository) Save(ctx context.Context, availability *models.Availability) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM weekday_availability`); err != nil {
+		return err
+	}
+	for weekday, minutes := range availability.WeekdayMinutes {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO weekday_availability (weekday, minutes) VALUES (?, ?)`, weekday, minutes); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM blackout_dates`); err != nil {
+		return err
+	}
+	for _, blackout := range availability.Blackouts {
+		if _, err := tx.NamedExecContext(ctx, `INSERT INTO blackout_dates (day, reason) VALUES (:day, :reason)`, blackout); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
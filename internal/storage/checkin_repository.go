@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"agent-coach/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type CheckInRepository struct {
+	db *DB
+}
+
+func NewCheckInRepository(db *DB) *CheckInRepository {
+	return &CheckInRepository{db: db}
+}
+
+// checkInRow carries the JSON-encoded wins and struggles columns.
+type checkInRow struct {
+	models.CheckIn
+	WinsJSON      sql.NullString `db:"wins"`
+	StrugglesJSON sql.NullString `db:"struggles"`
+}
+
+func (row *checkInRow) toModel() *models.CheckIn {
+	checkIn := row.CheckIn
+	if row.WinsJSON.Valid {
+		json.Unmarshal([]byte(row.WinsJSON.String), &checkIn.Wins)
+	}
+	if row.StrugglesJSON.Valid {
+		json.Unmarshal([]byte(row.StrugglesJSON.String), &checkIn.Struggles)
+	}
+	return &checkIn
+}
+
+func (r *CheckInRepository) Create(ctx context.Context, checkIn *models.CheckIn) error {
+	if checkIn.ID == "" {
+		checkIn.ID = uuid.New().String()
+	}
+	checkIn.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO checkins (id, goal_id, summary, wins, struggles, mood_rating, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, query, checkIn.ID, checkIn.GoalID, checkIn.Summary,
+		toNullString(marshalJSON(checkIn.Wins)), toNullString(marshalJSON(checkIn.Struggles)),
+		checkIn.MoodRating, checkIn.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err := addActivity(ctx, tx, checkIn.GoalID, checkIn.CreatedAt, 0, 0, 1); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *CheckInRepository) GetByGoalID(ctx context.Context, goalID string, limit int) ([]*models.CheckIn, error) {
+	query := `
+		SELECT id, goal_id, summary, wins, struggles, mood_rating, created_at
+		FROM checkins WHERE goal_id = ? ORDER BY created_at DESC LIMIT ?
+	`
+
+	var rows []checkInRow
+	if err := r.db.SelectContext(ctx, &rows, query, goalID, limit); err != nil {
+		return nil, err
+	}
+
+	checkIns := make([]*models.CheckIn, len(rows))
+	for i := range rows {
+		checkIns[i] = rows[i].toModel()
+	}
+
+	return checkIns, nil
+}
+
+func (r *CheckInRepository) GetLatestByGoalID(ctx context.Context, goalID string) (*models.CheckIn, error) {
+	checkIns, err := r.GetByGoalID(ctx, goalID, 1)
+	if err != nil || len(checkIns) == 0 {
+		return nil, err
+	}
+	return checkIns[0], nil
+}
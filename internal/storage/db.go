@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -12,22 +14,36 @@ type DB struct {
 	*sqlx.DB
 }
 
-func NewDB() (*DB, error) {
-	dbPath := getDBPath()
-
+// NewDB opens the database at dbPath, creating its directory if needed, and
+// applies any pending migrations so a fresh install starts with the full
+// schema.
+func NewDB(dbPath string) (*DB, error) {
 	dir := filepath.Dir(dbPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, err
 	}
 
-	db, err := sqlx.Connect("sqlite3", dbPath)
+	db, err := Open(dbPath)
 	if err != nil {
 		return nil, err
 	}
-	return &DB{db}, nil
+	if _, err := db.Migrate(context.Background()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate database %s: %w", dbPath, err)
+	}
+	return db, nil
 }
 
-func getDBPath() string {
-	dataDir := "data"
-	return filepath.Join(dataDir, "agent-coach.db")
+// Open connects to the SQLite database at dataSourceName. An in-memory
+// database (":memory:") is limited to one connection, since every connection
+// would otherwise get its own empty database.
+func Open(dataSourceName string) (*DB, error) {
+	db, err := sqlx.Connect("sqlite3", dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	if dataSourceName == ":memory:" {
+		db.SetMaxOpenConns(1)
+	}
+	return &DB{db}, nil
 }
@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"agent-coach/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrDependencyCycle is returned when a dependency would make a task wait,
+// directly or through other tasks, on itself.
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+type DependencyRepository struct {
+	db *DB
+}
+
+func NewDependencyRepository(db *DB) *DependencyRepository {
+	return &DependencyRepository{db: db}
+}
+
+// Add records that taskID depends on each of dependsOn. Every prerequisite
+// must belong to the task's goal, and none may already depend on the task;
+// nothing is saved if any of them fails.
+func (r *DependencyRepository) Add(ctx context.Context, taskID string, dependsOn ...string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := addDependencies(ctx, tx, taskID, dependsOn); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// addDependencies is Add within tx, so that a task and its dependencies can
+// be saved together.
+func addDependencies(ctx context.Context, tx *sqlx.Tx, taskID string, dependsOn []string) error {
+	var goalID string
+	err := tx.GetContext(ctx, &goalID, `SELECT goal_id FROM tasks WHERE id = ?`, taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("task %s: %w", taskID, ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+
+	var existing []*models.TaskDependency
+	err = tx.SelectContext(ctx, &existing, `
+		SELECT d.task_id, d.depends_on_id, d.created_at
+		FROM task_dependencies d JOIN tasks t ON t.id = d.task_id
+		WHERE t.goal_id = ?
+	`, goalID)
+	if err != nil {
+		return err
+	}
+	edges := make(map[string][]string)
+	for _, dependency := range existing {
+		edges[dependency.TaskID] = append(edges[dependency.TaskID], dependency.DependsOnID)
+	}
+
+	for _, prerequisiteID := range dependsOn {
+		var prerequisiteGoalID string
+		err := tx.GetContext(ctx, &prerequisiteGoalID, `SELECT goal_id FROM tasks WHERE id = ?`, prerequisiteID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && prerequisiteGoalID != goalID) {
+			return fmt.Errorf("prerequisite %s: %w", prerequisiteID, ErrNotFound)
+		}
+		if err != nil {
+			return err
+		}
+		if path := dependencyPath(edges, prerequisiteID, taskID); path != nil {
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(append([]string{taskID}, path...), " -> "))
+		}
+		edges[taskID] = append(edges[taskID], prerequisiteID)
+
+		_, err = tx.ExecContext(ctx, `INSERT OR IGNORE INTO task_dependencies (task_id, depends_on_id) VALUES (?, ?)`, taskID, prerequisiteID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dependencyPath returns the chain of tasks from from to to following the
+// edges from each task to its prerequisites, or nil if to is not reachable.
+func dependencyPath(edges map[string][]string, from, to string) []string {
+	visited := make(map[string]bool)
+	var walk func(id string) []string
+	walk = func(id string) []string {
+		if id == to {
+			return []string{id}
+		}
+		if visited[id] {
+			return nil
+		}
+		visited[id] = true
+		for _, next := range edges[id] {
+			if path := walk(next); path != nil {
+				return append([]string{id}, path...)
+			}
+		}
+		return nil
+	}
+	return walk(from)
+}
+
+// Remove deletes the dependency of taskID on dependsOnID.
+func (r *DependencyRepository) Remove(ctx context.Context, taskID, dependsOnID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM task_dependencies WHERE task_id = ? AND depends_on_id = ?`, taskID, dependsOnID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// GetByGoalID returns the dependencies between the goal's tasks. An empty
+// goalID covers every goal.
+func (r *DependencyRepository) GetByGoalID(ctx context.Context, goalID string) ([]*models.TaskDependency, error) {
+	query := `
+		SELECT d.task_id, d.depends_on_id, d.created_at
+		FROM task_dependencies d JOIN tasks t ON t.id = d.task_id
+		WHERE ? = '' OR t.goal_id = ?
+		ORDER BY d.created_at ASC, d.task_id ASC
+	`
+
+	var dependencies []*models.TaskDependency
+	if err := r.db.SelectContext(ctx, &dependencies, query, goalID, goalID); err != nil {
+		return nil, err
+	}
+	return dependencies, nil
+}
+
+// GetPrerequisites returns the tasks taskID depends on.
+func (r *DependencyRepository) GetPrerequisites(ctx context.Context, taskID string) ([]*models.Task, error) {
+	query := `
+		SELECT t.id, t.goal_id, t.parent_id, t.title, t.description, t.due_date, t.due_kind, t.status, t.priority,
+			t.difficulty_rating, t.estimated_minutes, t.actual_minutes, t.struggle_notes, t.milestone_id, t.created_at, t.completed_at
+		FROM task_dependencies d JOIN tasks t ON t.id = d.depends_on_id
+		WHERE d.task_id = ?
+		ORDER BY t.due_date ASC, t.priority DESC
+	`
+
+	var tasks []*models.Task
+	if err := r.db.SelectContext(ctx, &tasks, query, taskID); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
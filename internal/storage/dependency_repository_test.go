@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"agent-coach/internal/models"
+)
+
+func TestDependenciesRejectCyclesAndBlockDueTasks(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	tasks, dependencies := NewTaskRepository(db), NewDependencyRepository(db)
+
+	today := models.CalendarDate(time.Now().In(time.UTC))
+	create := func(goalID, title string) *models.Task {
+		t.Helper()
+		task := &models.Task{GoalID: goalID, Title: title, Status: models.TaskStatusPending, DueDate: &today}
+		if err := tasks.Create(ctx, task); err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+		return task
+	}
+	basics, generics, iterators := create("goal-1", "basics"), create("goal-1", "generics"), create("goal-1", "iterators")
+	other := create("goal-2", "other goal")
+
+	if err := dependencies.Add(ctx, generics.ID, basics.ID); err != nil {
+		t.Fatalf("add generics -> basics: %v", err)
+	}
+	if err := dependencies.Add(ctx, iterators.ID, generics.ID); err != nil {
+		t.Fatalf("add iterators -> generics: %v", err)
+	}
+	if err := dependencies.Add(ctx, basics.ID, iterators.ID); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("closing the cycle: error = %v, want ErrDependencyCycle", err)
+	}
+	if err := dependencies.Add(ctx, basics.ID, basics.ID); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("self dependency: error = %v, want ErrDependencyCycle", err)
+	}
+	if err := dependencies.Add(ctx, basics.ID, other.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("dependency on another goal: error = %v, want ErrNotFound", err)
+	}
+
+	dueToday := func() map[string]bool {
+		t.Helper()
+		due, err := tasks.GetDueToday(ctx, time.UTC)
+		if err != nil {
+			t.Fatalf("GetDueToday: %v", err)
+		}
+		titles := make(map[string]bool)
+		for _, task := range due {
+			titles[task.Title] = true
+		}
+		return titles
+	}
+	if due := dueToday(); !due["basics"] || due["generics"] || due["iterators"] {
+		t.Errorf("due today = %v, want basics but not its dependents", due)
+	}
+
+	if err := tasks.MarkComplete(ctx, basics.ID, nil); err != nil {
+		t.Fatalf("complete basics: %v", err)
+	}
+	if due := dueToday(); !due["generics"] || due["iterators"] {
+		t.Errorf("due today after basics = %v, want generics unblocked", due)
+	}
+
+	goalTasks, err := tasks.GetByGoalID(ctx, "goal-1")
+	if err != nil {
+		t.Fatalf("GetByGoalID: %v", err)
+	}
+	edges, err := dependencies.GetByGoalID(ctx, "goal-1")
+	if err != nil {
+		t.Fatalf("GetByGoalID dependencies: %v", err)
+	}
+	graph := models.NewDependencyGraph(goalTasks, edges)
+	if len(graph.Nodes) != 3 || len(graph.Edges) != 2 {
+		t.Fatalf("graph has %d nodes and %d edges, want 3 and 2", len(graph.Nodes), len(graph.Edges))
+	}
+	for _, node := range graph.Nodes {
+		if node.Blocked != (node.ID == iterators.ID) {
+			t.Errorf("%s blocked = %v", node.Title, node.Blocked)
+		}
+	}
+
+	if err := tasks.Delete(ctx, generics.ID); err != nil {
+		t.Fatalf("delete generics: %v", err)
+	}
+	if edges, _ := dependencies.GetByGoalID(ctx, "goal-1"); len(edges) != 0 {
+		t.Errorf("dependencies after delete = %v, want none", edges)
+	}
+}
+
+func TestCreateTaskSavesDependenciesAtomically(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	tasks, dependencies := NewTaskRepository(db), NewDependencyRepository(db)
+
+	basics := &models.Task{GoalID: "goal-1", Title: "basics", Status: models.TaskStatusPending}
+	if err := tasks.Create(ctx, basics); err != nil {
+		t.Fatalf("create basics: %v", err)
+	}
+
+	orphan := &models.Task{GoalID: "goal-1", Title: "orphan", Status: models.TaskStatusPending}
+	if err := tasks.Create(ctx, orphan, basics.ID, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("create with a missing prerequisite: error = %v, want ErrNotFound", err)
+	}
+	if task, err := tasks.GetByID(ctx, orphan.ID); err != nil || task != nil {
+		t.Errorf("task after failed create = %+v, %v; want none", task, err)
+	}
+
+	generics := &models.Task{GoalID: "goal-1", Title: "generics", Status: models.TaskStatusPending}
+	if err := tasks.Create(ctx, generics, basics.ID); err != nil {
+		t.Fatalf("create generics: %v", err)
+	}
+	if err := dependencies.Remove(ctx, generics.ID, basics.ID); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := dependencies.Remove(ctx, generics.ID, basics.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("removing twice: error = %v, want ErrNotFound", err)
+	}
+	if edges, _ := dependencies.GetByGoalID(ctx, "goal-1"); len(edges) != 0 {
+		t.Errorf("dependencies after remove = %v, want none", edges)
+	}
+}
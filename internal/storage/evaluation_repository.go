@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"agent-coach/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type EvaluationRepository struct {
+	db *DB
+}
+
+func NewEvaluationRepository(db *DB) *EvaluationRepository {
+	return &EvaluationRepository{db: db}
+}
+
+func (r *EvaluationRepository) CreateRating(ctx context.Context, rating *models.PerformanceRating) error {
+	if rating.ID == "" {
+		rating.ID = uuid.New().String()
+	}
+	rating.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO performance_ratings (id, goal_id, task_id, rating, feedback, created_at)
+		VALUES (:id, :goal_id, :task_id, :rating, :feedback, :created_at)
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, rating)
+	return err
+}
+
+func (r *EvaluationRepository) GetRatingsByGoalID(ctx context.Context, goalID string) ([]*models.PerformanceRating, error) {
+	query := `
+		SELECT id, goal_id, task_id, rating, feedback, created_at
+		FROM performance_ratings WHERE goal_id = ? ORDER BY created_at ASC
+	`
+
+	var ratings []*models.PerformanceRating
+	if err := r.db.SelectContext(ctx, &ratings, query, goalID); err != nil {
+		return nil, err
+	}
+	return ratings, nil
+}
+
+func (r *EvaluationRepository) CreateWeakness(ctx context.Context, weakness *models.Weakness) error {
+	if weakness.ID == "" {
+		weakness.ID = uuid.New().String()
+	}
+	weakness.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO weaknesses (id, goal_id, area, evidence, suggestion, created_at)
+		VALUES (:id, :goal_id, :area, :evidence, :suggestion, :created_at)
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, weakness)
+	return err
+}
+
+func (r *EvaluationRepository) GetWeaknessesByGoalID(ctx context.Context, goalID string) ([]*models.Weakness, error) {
+	query := `
+		SELECT id, goal_id, area, evidence, suggestion, created_at
+		FROM weaknesses WHERE goal_id = ? ORDER BY created_at DESC
+	`
+
+	var weaknesses []*models.Weakness
+	if err := r.db.SelectContext(ctx, &weaknesses, query, goalID); err != nil {
+		return nil, err
+	}
+	return weaknesses, nil
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"agent-coach/internal/models"
 
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 )
 
 type GoalRepository struct {
@@ -80,12 +83,34 @@ func (r *GoalRepository) Update(ctx context.Context, goal *models.Goal) error {
 	return nil
 }
 
+// goalData lists the statements that delete what a goal owns: its plan,
+// evaluations, check-ins and daily activity. Conversations and LLM usage are
+// kept as history.
+var goalData = []string{
+	`DELETE FROM task_dependencies WHERE task_id IN (SELECT id FROM tasks WHERE goal_id = ?1)
+		OR depends_on_id IN (SELECT id FROM tasks WHERE goal_id = ?1)`,
+	`DELETE FROM tasks WHERE goal_id = ?`,
+	`DELETE FROM milestones WHERE goal_id = ?`,
+	`DELETE FROM resources WHERE goal_id = ?`,
+	`DELETE FROM performance_ratings WHERE goal_id = ?`,
+	`DELETE FROM weaknesses WHERE goal_id = ?`,
+	`DELETE FROM checkins WHERE goal_id = ?`,
+	`DELETE FROM daily_activity WHERE goal_id = ?`,
+}
+
+// Delete removes the goal together with everything in goalData, in one
+// transaction.
 func (r *GoalRepository) Delete(ctx context.Context, id string) error {
-	result, err := r.db.ExecContext(ctx, "DELETE FROM goals WHERE id = ?", id)
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
+	result, err := tx.ExecContext(ctx, "DELETE FROM goals WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
 	rows, err := result.RowsAffected()
 	if err != nil {
 		return err
@@ -94,7 +119,12 @@ func (r *GoalRepository) Delete(ctx context.Context, id string) error {
 		return ErrNotFound
 	}
 
-	return nil
+	for _, query := range goalData {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
 func (r *GoalRepository) UpdateStatus(ctx context.Context, id string, status string) error {
@@ -114,3 +144,225 @@ func (r *GoalRepository) UpdateStatus(ctx context.Context, id string, status str
 
 	return nil
 }
+
+const (
+	defaultGoalPageSize = 50
+	maxGoalPageSize     = 200
+)
+
+var goalSortColumns = map[models.GoalSortField]string{
+	models.GoalSortCreatedAt: "created_at",
+	models.GoalSortUpdatedAt: "updated_at",
+	// Goals without a target date sort last either way.
+	models.GoalSortTargetDate: "target_date IS NULL, target_date",
+	models.GoalSortTitle:      "title COLLATE NOCASE",
+}
+
+// List returns one page of goals matching q, each with its task summary.
+func (r *GoalRepository) List(ctx context.Context, q models.GoalQuery) (*models.GoalPage, error) {
+	sortBy, descending := q.SortBy, q.Descending
+	if sortBy == "" {
+		sortBy, descending = models.GoalSortCreatedAt, true
+	}
+	order, ok := goalSortColumns[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported goal sort: %q", q.SortBy)
+	}
+	if descending {
+		order += " DESC"
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultGoalPageSize
+	}
+	limit = min(limit, maxGoalPageSize)
+	offset := max(q.Offset, 0)
+
+	where, args, err := goalFilter(q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.GoalPage{Goals: []*models.GoalSummary{}, Limit: limit, Offset: offset}
+	if err := r.db.GetContext(ctx, &page.Total, `SELECT COUNT(*) FROM goals`+where, args...); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, title, description, target_date, status, context, created_at, updated_at
+		FROM goals` + where + `
+		ORDER BY ` + order + `, id ASC
+		LIMIT ? OFFSET ?
+	`
+	var goals []models.Goal
+	if err := r.db.SelectContext(ctx, &goals, query, append(args, limit, offset)...); err != nil {
+		return nil, err
+	}
+	if len(goals) == 0 {
+		return page, nil
+	}
+
+	ids := make([]string, len(goals))
+	summaries := make(map[string]*models.GoalSummary, len(goals))
+	for i, goal := range goals {
+		ids[i] = goal.ID
+		summary := &models.GoalSummary{Goal: goal, LastActivityAt: goal.UpdatedAt}
+		summaries[goal.ID] = summary
+		page.Goals = append(page.Goals, summary)
+	}
+
+	if err := r.loadTaskCounts(ctx, ids, summaries); err != nil {
+		return nil, err
+	}
+	if err := r.loadLastActivity(ctx, ids, summaries); err != nil {
+		return nil, err
+	}
+	if err := r.loadNextDueTasks(ctx, ids, summaries); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// goalFilter builds the WHERE clause for the filters set in q.
+func goalFilter(q models.GoalQuery) (string, []interface{}, error) {
+	var conditions []string
+	var args []interface{}
+
+	if len(q.Statuses) > 0 {
+		condition, statusArgs, err := sqlx.In("status IN (?)", q.Statuses)
+		if err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, condition)
+		args = append(args, statusArgs...)
+	}
+	// target_date is stored as a timestamp string; its date prefix is
+	// compared so the range is inclusive of whole days.
+	if q.TargetFrom != "" {
+		if _, err := time.Parse("2006-01-02", q.TargetFrom); err != nil {
+			return "", nil, fmt.Errorf("invalid target from date: %w", err)
+		}
+		conditions = append(conditions, "substr(target_date, 1, 10) >= ?")
+		args = append(args, q.TargetFrom)
+	}
+	if q.TargetTo != "" {
+		if _, err := time.Parse("2006-01-02", q.TargetTo); err != nil {
+			return "", nil, fmt.Errorf("invalid target to date: %w", err)
+		}
+		conditions = append(conditions, "substr(target_date, 1, 10) <= ?")
+		args = append(args, q.TargetTo)
+	}
+	if search := strings.TrimSpace(q.Search); search != "" {
+		pattern := "%" + likeEscaper.Replace(search) + "%"
+		conditions = append(conditions, `(title LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil, nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *GoalRepository) loadTaskCounts(ctx context.Context, ids []string, summaries map[string]*models.GoalSummary) error {
+	query, args, err := sqlx.In(`
+		SELECT goal_id, status, COUNT(*) AS count
+		FROM tasks WHERE goal_id IN (?)
+		GROUP BY goal_id, status
+	`, ids)
+	if err != nil {
+		return err
+	}
+
+	var counts []struct {
+		GoalID string            `db:"goal_id"`
+		Status models.TaskStatus `db:"status"`
+		Count  int               `db:"count"`
+	}
+	if err := r.db.SelectContext(ctx, &counts, r.db.Rebind(query), args...); err != nil {
+		return err
+	}
+
+	for _, c := range counts {
+		summary := summaries[c.GoalID]
+		summary.TotalTasks += c.Count
+		switch c.Status {
+		case models.TaskStatusPending:
+			summary.PendingTasks += c.Count
+		case models.TaskStatusInProgress:
+			summary.InProgressTasks += c.Count
+		case models.TaskStatusCompleted:
+			summary.CompletedTasks += c.Count
+		case models.TaskStatusSkipped:
+			summary.SkippedTasks += c.Count
+		}
+	}
+	return nil
+}
+
+func (r *GoalRepository) loadLastActivity(ctx context.Context, ids []string, summaries map[string]*models.GoalSummary) error {
+	query, args, err := sqlx.In(`
+		SELECT goal_id, MAX(at) AS last_activity FROM (
+			SELECT goal_id, completed_at AS at FROM tasks WHERE completed_at IS NOT NULL
+			UNION ALL SELECT goal_id, created_at FROM conversations
+			UNION ALL SELECT goal_id, created_at FROM checkins
+		)
+		WHERE goal_id IN (?)
+		GROUP BY goal_id
+	`, ids)
+	if err != nil {
+		return err
+	}
+
+	// MAX over a subquery loses the DATETIME column type, so the driver
+	// returns the raw text and it is parsed here.
+	var rows []struct {
+		GoalID       string `db:"goal_id"`
+		LastActivity string `db:"last_activity"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		at, ok := parseSQLiteTime(row.LastActivity)
+		if !ok {
+			continue
+		}
+		if summary := summaries[row.GoalID]; at.After(summary.LastActivityAt) {
+			summary.LastActivityAt = at
+		}
+	}
+	return nil
+}
+
+// loadNextDueTasks picks each goal's open task with the earliest due date
+// among those not waiting on a prerequisite.
+func (r *GoalRepository) loadNextDueTasks(ctx context.Context, ids []string, summaries map[string]*models.GoalSummary) error {
+	query, args, err := sqlx.In(`
+		SELECT id, goal_id, parent_id, title, description, due_date, due_kind, status, priority,
+			difficulty_rating, estimated_minutes, actual_minutes, struggle_notes, milestone_id, created_at, completed_at
+		FROM tasks
+		WHERE goal_id IN (?) AND status IN ('pending', 'in_progress') AND due_date IS NOT NULL
+			AND `+unblocked+`
+		ORDER BY due_date ASC, priority DESC
+	`, ids)
+	if err != nil {
+		return err
+	}
+
+	var tasks []models.Task
+	if err := r.db.SelectContext(ctx, &tasks, r.db.Rebind(query), args...); err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if summary := summaries[task.GoalID]; summary.NextDueTask == nil {
+			summary.NextDueTask = &task
+		}
+	}
+	return nil
+}
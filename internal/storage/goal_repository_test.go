@@ -0,0 +1,270 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"agent-coach/internal/models"
+)
+
+// newTestDB returns an in-memory database with every migration applied.
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+
+	db, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Migrate(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestGoalContextRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	repo := NewGoalRepository(newTestDB(t))
+
+	goal := &models.Goal{
+		Title:  "Learn Go",
+		Status: models.GoalStatusActive,
+		Context: models.JSONMap{
+			"hoursPerWeek": float64(6),
+			"level":        "beginner",
+			"topics":       []interface{}{"concurrency", "testing"},
+		},
+	}
+	if err := repo.Create(ctx, goal); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, goal.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if !reflect.DeepEqual(got.Context, goal.Context) {
+		t.Errorf("context = %#v, want %#v", got.Context, goal.Context)
+	}
+
+	got.Context = nil
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err = repo.GetByID(ctx, goal.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Context != nil {
+		t.Errorf("cleared context = %#v, want nil", got.Context)
+	}
+}
+
+func TestConversationMetadataRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	repo := NewConversationRepository(newTestDB(t))
+
+	goalID := "goal-1"
+	conversations := []*models.Conversation{
+		{GoalID: &goalID, SessionID: goalID, Role: models.RoleUser, Content: "hi"},
+		{GoalID: &goalID, SessionID: goalID, Role: models.RoleAssistant, Content: "hello", AgentType: models.AgentTypeGeneral,
+			Metadata: models.JSONMap{"intent": "general", "confidence": 0.9}},
+	}
+	for _, conv := range conversations {
+		if err := repo.Create(ctx, conv); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	got, err := repo.GetBySessionID(ctx, goalID)
+	if err != nil {
+		t.Fatalf("GetBySessionID: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d conversations, want 2", len(got))
+	}
+	if got[0].Metadata != nil {
+		t.Errorf("metadata without a value = %#v, want nil", got[0].Metadata)
+	}
+	if !reflect.DeepEqual(got[1].Metadata, conversations[1].Metadata) {
+		t.Errorf("metadata = %#v, want %#v", got[1].Metadata, conversations[1].Metadata)
+	}
+}
+
+func TestGoalListFiltersSortsAndSummarizes(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	goals := NewGoalRepository(db)
+	tasks := NewTaskRepository(db)
+
+	date := func(s string) *time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return &d
+	}
+	golang := &models.Goal{Title: "Learn Go", Description: "Concurrency and 100% test coverage", Status: models.GoalStatusActive, TargetDate: date("2026-06-30")}
+	spanish := &models.Goal{Title: "Spanish B1", Status: models.GoalStatusActive, TargetDate: date("2026-12-31")}
+	guitar := &models.Goal{Title: "guitar basics", Status: models.GoalStatusPaused}
+	for _, goal := range []*models.Goal{golang, spanish, guitar} {
+		if err := goals.Create(ctx, goal); err != nil {
+			t.Fatalf("create goal: %v", err)
+		}
+	}
+
+	for _, task := range []*models.Task{
+		{GoalID: golang.ID, Title: "Goroutines", Status: models.TaskStatusPending, DueDate: date("2026-05-03")},
+		{GoalID: golang.ID, Title: "Channels", Status: models.TaskStatusInProgress, DueDate: date("2026-05-01")},
+		{GoalID: golang.ID, Title: "Install Go", Status: models.TaskStatusPending},
+		{GoalID: golang.ID, Title: "Hello world", Status: models.TaskStatusCompleted, DueDate: date("2026-04-01")},
+	} {
+		if err := tasks.Create(ctx, task); err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+	}
+	done, _ := tasks.GetByGoalID(ctx, golang.ID)
+	for _, task := range done {
+		if task.Status == models.TaskStatusCompleted {
+			if err := tasks.MarkComplete(ctx, task.ID, nil); err != nil {
+				t.Fatalf("MarkComplete: %v", err)
+			}
+		}
+	}
+
+	titles := func(page *models.GoalPage) []string {
+		var out []string
+		for _, goal := range page.Goals {
+			out = append(out, goal.Title)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name  string
+		query models.GoalQuery
+		want  []string
+		total int
+	}{
+		{"status", models.GoalQuery{Statuses: []models.GoalStatus{models.GoalStatusActive}, SortBy: models.GoalSortTitle},
+			[]string{"Learn Go", "Spanish B1"}, 2},
+		{"target range", models.GoalQuery{TargetFrom: "2026-06-30", TargetTo: "2026-06-30"}, []string{"Learn Go"}, 1},
+		{"search escapes wildcards", models.GoalQuery{Search: "100%"}, []string{"Learn Go"}, 1},
+		{"title ignores case", models.GoalQuery{SortBy: models.GoalSortTitle}, []string{"guitar basics", "Learn Go", "Spanish B1"}, 3},
+		{"target date puts undated last", models.GoalQuery{SortBy: models.GoalSortTargetDate, Descending: true},
+			[]string{"Spanish B1", "Learn Go", "guitar basics"}, 3},
+		{"page", models.GoalQuery{SortBy: models.GoalSortTitle, Limit: 1, Offset: 1}, []string{"Learn Go"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := goals.List(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if got := titles(page); !reflect.DeepEqual(got, tt.want) || page.Total != tt.total {
+				t.Errorf("List = %v (total %d), want %v (total %d)", got, page.Total, tt.want, tt.total)
+			}
+		})
+	}
+
+	page, err := goals.List(ctx, models.GoalQuery{Search: "learn"})
+	if err != nil || len(page.Goals) != 1 {
+		t.Fatalf("List = %+v, %v", page, err)
+	}
+	summary := page.Goals[0]
+	if summary.TotalTasks != 4 || summary.PendingTasks != 2 || summary.InProgressTasks != 1 || summary.CompletedTasks != 1 {
+		t.Errorf("task counts = %+v", summary)
+	}
+	if summary.NextDueTask == nil || summary.NextDueTask.Title != "Channels" {
+		t.Errorf("next due task = %+v, want Channels", summary.NextDueTask)
+	}
+	if !summary.LastActivityAt.After(summary.UpdatedAt) {
+		t.Errorf("last activity %v not after goal update %v", summary.LastActivityAt, summary.UpdatedAt)
+	}
+
+	if _, err := goals.List(ctx, models.GoalQuery{SortBy: "priority"}); err == nil {
+		t.Error("unknown sort accepted")
+	}
+}
+
+func TestGoalDeleteRemovesItsData(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	goals := NewGoalRepository(db)
+	tasks := NewTaskRepository(db)
+
+	completedAt := time.Now()
+	minutes := 30
+	var ids []string
+	for _, title := range []string{"Learn Go", "Learn Rust"} {
+		goal := &models.Goal{Title: title, Status: models.GoalStatusActive}
+		if err := goals.Create(ctx, goal); err != nil {
+			t.Fatalf("create goal: %v", err)
+		}
+		ids = append(ids, goal.ID)
+
+		first := &models.Task{GoalID: goal.ID, Title: "Basics", Status: models.TaskStatusCompleted, CompletedAt: &completedAt, ActualMinutes: &minutes}
+		if err := tasks.Create(ctx, first); err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+		if err := tasks.Create(ctx, &models.Task{GoalID: goal.ID, Title: "Project", Status: models.TaskStatusPending}, first.ID); err != nil {
+			t.Fatalf("create dependent task: %v", err)
+		}
+		if err := NewMilestoneRepository(db).Create(ctx, &models.Milestone{GoalID: goal.ID, Title: "Week 1", WeekStart: 1, WeekEnd: 1}); err != nil {
+			t.Fatalf("create milestone: %v", err)
+		}
+		if err := NewResourceRepository(db).Create(ctx, &models.Resource{GoalID: goal.ID, Title: "The book"}); err != nil {
+			t.Fatalf("create resource: %v", err)
+		}
+		evaluations := NewEvaluationRepository(db)
+		if err := evaluations.CreateRating(ctx, &models.PerformanceRating{GoalID: goal.ID, TaskID: first.ID, Rating: 4}); err != nil {
+			t.Fatalf("create rating: %v", err)
+		}
+		if err := evaluations.CreateWeakness(ctx, &models.Weakness{GoalID: goal.ID, Area: "Testing"}); err != nil {
+			t.Fatalf("create weakness: %v", err)
+		}
+		if err := NewCheckInRepository(db).Create(ctx, &models.CheckIn{GoalID: goal.ID, Summary: "Good week"}); err != nil {
+			t.Fatalf("create check-in: %v", err)
+		}
+	}
+
+	if err := goals.Delete(ctx, ids[0]); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	count := func(query string, goalID string) int {
+		var n int
+		if err := db.GetContext(ctx, &n, query, goalID); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		return n
+	}
+	queries := []string{
+		`SELECT COUNT(*) FROM goals WHERE id = ?`,
+		`SELECT COUNT(*) FROM task_dependencies WHERE task_id IN (SELECT id FROM tasks WHERE goal_id = ?)`,
+		`SELECT COUNT(*) FROM tasks WHERE goal_id = ?`,
+		`SELECT COUNT(*) FROM milestones WHERE goal_id = ?`,
+		`SELECT COUNT(*) FROM resources WHERE goal_id = ?`,
+		`SELECT COUNT(*) FROM performance_ratings WHERE goal_id = ?`,
+		`SELECT COUNT(*) FROM weaknesses WHERE goal_id = ?`,
+		`SELECT COUNT(*) FROM checkins WHERE goal_id = ?`,
+		`SELECT COUNT(*) FROM daily_activity WHERE goal_id = ?`,
+	}
+	for _, query := range queries {
+		if n := count(query, ids[0]); n != 0 {
+			t.Errorf("%s = %d for the deleted goal, want 0", query, n)
+		}
+		if n := count(query, ids[1]); n == 0 {
+			t.Errorf("%s = 0 for the other goal, want its rows kept", query)
+		}
+	}
+	var dependencies int
+	if err := db.GetContext(ctx, &dependencies, `SELECT COUNT(*) FROM task_dependencies`); err != nil || dependencies != 1 {
+		t.Errorf("task dependencies = %d, %v; want only the other goal's", dependencies, err)
+	}
+
+	if err := goals.Delete(ctx, ids[0]); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
 )
 
 // ErrNotFound is returned when a record is not found
@@ -28,3 +32,15 @@ func marshalJSON(v interface{}) string {
 	}
 	return string(data)
 }
+
+// parseSQLiteTime parses a timestamp the driver returned as text, which
+// happens for computed columns that have no declared DATETIME type.
+func parseSQLiteTime(s string) (time.Time, bool) {
+	s = strings.TrimSuffix(s, "Z")
+	for _, format := range sqlite3.SQLiteTimestampFormats {
+		if t, err := time.ParseInLocation(format, s, time.UTC); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+const (
+	appName        = "agent-coach"
+	DefaultProfile = "default"
+
+	// EnvDataDir and EnvProfile override the config file for the current run.
+	EnvDataDir = "AGENT_COACH_DATA_DIR"
+	EnvProfile = "AGENT_COACH_PROFILE"
+)
+
+// legacyPath is where releases before the per-OS data directory kept the
+// database, relative to the working directory.
+var legacyPath = filepath.Join("data", appName+".db")
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Location is where the database for the active profile lives.
+type Location struct {
+	DataDir string `json:"dataDir"`
+	Profile string `json:"profile"`
+	Path    string `json:"path"`
+}
+
+// dataConfig is the config file stored in the user config directory.
+type dataConfig struct {
+	DataDir string `json:"dataDir,omitempty"`
+	Profile string `json:"profile,omitempty"`
+}
+
+// ResolveLocation picks the data directory and profile from, in order, the
+// environment, the config file and the platform defaults.
+func ResolveLocation() (*Location, error) {
+	cfg, err := loadDataConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	dataDir := os.Getenv(EnvDataDir)
+	if dataDir == "" {
+		dataDir = cfg.DataDir
+	}
+	if dataDir == "" {
+		if dataDir, err = userDataDir(); err != nil {
+			return nil, err
+		}
+		if err := adoptLegacyDatabase(filepath.Join(dataDir, appName+".db")); err != nil {
+			return nil, fmt.Errorf("copy %s into %s: %w", legacyPath, dataDir, err)
+		}
+	}
+
+	profile := os.Getenv(EnvProfile)
+	if profile == "" {
+		profile = cfg.Profile
+	}
+	if profile == "" {
+		profile = DefaultProfile
+	}
+
+	return NewLocation(dataDir, profile)
+}
+
+// adoptLegacyDatabase copies the database from legacyPath to path, the
+// default profile's database, when path does not exist yet, so that users
+// upgrading from a release that kept it under data/ keep their goals. The
+// old file is left in place.
+func adoptLegacyDatabase(path string) error {
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if _, err := os.Stat(legacyPath); errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// Changes not yet checkpointed live in the write-ahead log, which must
+	// come along. The database itself is renamed into place last, so a copy
+	// cut short is retried on the next launch.
+	for _, suffix := range []string{"-wal", "-shm", ""} {
+		if err := copyFile(legacyPath+suffix, path+suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile copies src to dst through a temporary file renamed into place.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// NewLocation returns the location of profile's database under dataDir. The
+// default profile keeps the original agent-coach.db file name; other
+// profiles live under profiles/.
+func NewLocation(dataDir, profile string) (*Location, error) {
+	if !profileNamePattern.MatchString(profile) {
+		return nil, fmt.Errorf("invalid profile name %q: use letters, digits, '-' or '_'", profile)
+	}
+
+	dataDir, err := filepath.Abs(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dataDir, appName+".db")
+	if profile != DefaultProfile {
+		path = filepath.Join(dataDir, "profiles", profile+".db")
+	}
+	return &Location{DataDir: dataDir, Profile: profile, Path: path}, nil
+}
+
+// ListProfiles returns the profiles with a database under dataDir.
+func ListProfiles(dataDir string) ([]string, error) {
+	profiles := []string{DefaultProfile}
+
+	files, err := filepath.Glob(filepath.Join(dataDir, "profiles", "*.db"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".db")
+		if profileNamePattern.MatchString(name) && name != DefaultProfile {
+			profiles = append(profiles, name)
+		}
+	}
+	sort.Strings(profiles[1:])
+	return profiles, nil
+}
+
+// SaveProfile makes profile the active profile for future launches.
+func SaveProfile(profile string) error {
+	if !profileNamePattern.MatchString(profile) {
+		return fmt.Errorf("invalid profile name %q", profile)
+	}
+
+	cfg, err := loadDataConfig()
+	if err != nil {
+		return err
+	}
+	cfg.Profile = profile
+
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func loadDataConfig() (*dataConfig, error) {
+	cfg := &dataConfig{}
+
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appName, "config.json"), nil
+}
+
+// userDataDir is the platform location for application data:
+// ~/Library/Application Support on macOS, %AppData% on Windows and
+// $XDG_DATA_HOME (~/.local/share) elsewhere.
+func userDataDir() (string, error) {
+	switch runtime.GOOS {
+	case "darwin", "windows":
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, appName), nil
+	}
+
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, appName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", appName), nil
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// isolateConfig points the user config directory at a temp dir and clears
+// the location overrides.
+func isolateConfig(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "share"))
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", filepath.Join(dir, "config"))
+	t.Setenv(EnvDataDir, "")
+	t.Setenv(EnvProfile, "")
+	return dir
+}
+
+func TestResolveLocation(t *testing.T) {
+	dir := isolateConfig(t)
+
+	location, err := ResolveLocation()
+	if err != nil {
+		t.Fatalf("ResolveLocation: %v", err)
+	}
+	if location.Profile != DefaultProfile || filepath.Base(location.Path) != "agent-coach.db" {
+		t.Errorf("default location = %+v", location)
+	}
+
+	if err := SaveProfile("work"); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+	location, err = ResolveLocation()
+	if err != nil {
+		t.Fatalf("ResolveLocation: %v", err)
+	}
+	if location.Profile != "work" || location.Path != filepath.Join(location.DataDir, "profiles", "work.db") {
+		t.Errorf("saved profile location = %+v", location)
+	}
+
+	dataDir := filepath.Join(dir, "custom")
+	t.Setenv(EnvDataDir, dataDir)
+	t.Setenv(EnvProfile, "side-project")
+	location, err = ResolveLocation()
+	if err != nil {
+		t.Fatalf("ResolveLocation: %v", err)
+	}
+	want := &Location{DataDir: dataDir, Profile: "side-project", Path: filepath.Join(dataDir, "profiles", "side-project.db")}
+	if !reflect.DeepEqual(location, want) {
+		t.Errorf("env location = %+v, want %+v", location, want)
+	}
+
+	t.Setenv(EnvProfile, "../escape")
+	if _, err := ResolveLocation(); err == nil {
+		t.Error("profile with a path separator accepted")
+	}
+}
+
+func TestListProfiles(t *testing.T) {
+	dataDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dataDir, "profiles"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"work.db", "home.db", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dataDir, "profiles", name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	profiles, err := ListProfiles(dataDir)
+	if err != nil {
+		t.Fatalf("ListProfiles: %v", err)
+	}
+	if want := []string{DefaultProfile, "home", "work"}; !reflect.DeepEqual(profiles, want) {
+		t.Errorf("ListProfiles = %v, want %v", profiles, want)
+	}
+}
+
+func TestResolveLocationAdoptsLegacyDatabase(t *testing.T) {
+	dir := isolateConfig(t)
+	legacy := filepath.Join(dir, "data", "agent-coach.db")
+	previous := legacyPath
+	legacyPath = legacy
+	t.Cleanup(func() { legacyPath = previous })
+
+	if err := os.MkdirAll(filepath.Dir(legacy), 0755); err != nil {
+		t.Fatal(err)
+	}
+	db, err := Open(legacy)
+	if err != nil {
+		t.Fatalf("open legacy db: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE goals (title TEXT); INSERT INTO goals VALUES ('Learn Go')`); err != nil {
+		t.Fatalf("seed legacy db: %v", err)
+	}
+	db.Close()
+
+	location, err := ResolveLocation()
+	if err != nil {
+		t.Fatalf("ResolveLocation: %v", err)
+	}
+	adopted, err := Open(location.Path)
+	if err != nil {
+		t.Fatalf("open adopted db: %v", err)
+	}
+	var title string
+	err = adopted.Get(&title, `SELECT title FROM goals`)
+	adopted.Close()
+	if err != nil || title != "Learn Go" {
+		t.Fatalf("adopted goal = %q, %v; want the legacy goal", title, err)
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Errorf("legacy database removed: %v", err)
+	}
+
+	// Once the default database exists the legacy one is not copied again.
+	if err := os.WriteFile(legacy, []byte("stale"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ResolveLocation(); err != nil {
+		t.Fatalf("ResolveLocation: %v", err)
+	}
+	if data, _ := os.ReadFile(location.Path); string(data) == "stale" {
+		t.Error("legacy database copied over the existing one")
+	}
+}
@@ -0,0 +1,276 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"agent-coach/internal/migrations"
+)
+
+// versionTable is the table goose records applied migrations in. Reusing it
+// keeps databases migrated with the goose CLI compatible with the embedded
+// runner and the other way around.
+const versionTable = "goose_db_version"
+
+// lockTimeout is how long a migration run waits for another process holding
+// the database write lock.
+const lockTimeout = 30 * time.Second
+
+type Migration struct {
+	Version int64  `json:"version"`
+	Name    string `json:"name"`
+	Applied bool   `json:"applied"`
+	up      string
+	down    string
+}
+
+// MigrationError reports the migration that failed to apply or roll back.
+type MigrationError struct {
+	Migration string
+	Direction string
+	Err       error
+}
+
+func (e *MigrationError) Error() string {
+	return fmt.Sprintf("migration %s (%s) failed: %v", e.Migration, e.Direction, e.Err)
+}
+
+func (e *MigrationError) Unwrap() error {
+	return e.Err
+}
+
+// Migrate applies every pending embedded migration. All pending migrations
+// run in one transaction that holds the SQLite write lock, so concurrent
+// starts wait for each other and a failure leaves the schema untouched.
+func (db *DB) Migrate(ctx context.Context) (int, error) {
+	all, err := loadMigrations(migrations.FS)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	err = db.withMigrationLock(ctx, func(conn *sql.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range all {
+			if versions[m.Version] {
+				continue
+			}
+			if _, err := conn.ExecContext(ctx, m.up); err != nil {
+				return &MigrationError{Migration: m.Name, Direction: "up", Err: err}
+			}
+			if err := recordVersion(ctx, conn, m.Version, true); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return applied, nil
+}
+
+// MigrateDown rolls back the latest steps applied migrations, newest first.
+func (db *DB) MigrateDown(ctx context.Context, steps int) ([]string, error) {
+	all, err := loadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	var rolledBack []string
+	err = db.withMigrationLock(ctx, func(conn *sql.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(all) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+			m := all[i]
+			if !versions[m.Version] {
+				continue
+			}
+			if _, err := conn.ExecContext(ctx, m.down); err != nil {
+				return &MigrationError{Migration: m.Name, Direction: "down", Err: err}
+			}
+			if err := recordVersion(ctx, conn, m.Version, false); err != nil {
+				return err
+			}
+			rolledBack = append(rolledBack, m.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rolledBack, nil
+}
+
+// MigrationStatus lists every embedded migration and whether it is applied.
+func (db *DB) MigrationStatus(ctx context.Context) ([]*Migration, error) {
+	all, err := loadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	var status []*Migration
+	err = db.withMigrationLock(ctx, func(conn *sql.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range all {
+			m.Applied = versions[m.Version]
+			status = append(status, m)
+		}
+		return nil
+	})
+	return status, err
+}
+
+// withMigrationLock runs fn on a single connection inside a BEGIN IMMEDIATE
+// transaction, which takes the database write lock up front. Other processes
+// (another app instance, the goose CLI) block until the run commits.
+func (db *DB) withMigrationLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA busy_timeout = %d", lockTimeout.Milliseconds())); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return fmt.Errorf("lock database for migrations: %w", err)
+	}
+
+	if err := ensureVersionTable(ctx, conn); err != nil {
+		conn.ExecContext(ctx, "ROLLBACK")
+		return err
+	}
+	if err := fn(conn); err != nil {
+		conn.ExecContext(ctx, "ROLLBACK")
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, "COMMIT")
+	return err
+}
+
+func ensureVersionTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS `+versionTable+` (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			version_id INTEGER NOT NULL,
+			is_applied INTEGER NOT NULL,
+			tstamp TIMESTAMP DEFAULT (datetime('now'))
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", versionTable, err)
+	}
+
+	var count int
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+versionTable).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		// goose seeds the table with version 0.
+		return recordVersion(ctx, conn, 0, true)
+	}
+	return nil
+}
+
+// appliedVersions returns the versions whose most recent record marks them
+// applied, mirroring how goose reads its history.
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]bool, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version_id, is_applied FROM `+versionTable+` ORDER BY id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := make(map[int64]bool)
+	versions := make(map[int64]bool)
+	for rows.Next() {
+		var version int64
+		var isApplied bool
+		if err := rows.Scan(&version, &isApplied); err != nil {
+			return nil, err
+		}
+		if seen[version] {
+			continue
+		}
+		seen[version] = true
+		if isApplied {
+			versions[version] = true
+		}
+	}
+	return versions, rows.Err()
+}
+
+func recordVersion(ctx context.Context, conn *sql.Conn, version int64, applied bool) error {
+	_, err := conn.ExecContext(ctx, `INSERT INTO `+versionTable+` (version_id, is_applied) VALUES (?, ?)`, version, applied)
+	return err
+}
+
+// loadMigrations reads the goose-format files in fsys, ordered by version.
+func loadMigrations(fsys fs.FS) ([]*Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var all []*Migration
+	for _, name := range names {
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: name must start with a version", name)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: invalid version: %w", name, err)
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		up, down, err := splitMigration(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", name, err)
+		}
+
+		all = append(all, &Migration{
+			Version: version,
+			Name:    strings.TrimSuffix(path.Base(name), ".sql"),
+			up:      up,
+			down:    down,
+		})
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].Version < all[j].Version })
+	return all, nil
+}
+
+// splitMigration separates the Up and Down sections of a goose SQL file. The
+// StatementBegin/End annotations are plain comments to SQLite, so each
+// section runs as a single multi-statement exec.
+func splitMigration(data string) (up, down string, err error) {
+	_, rest, ok := strings.Cut(data, "-- +goose Up")
+	if !ok {
+		return "", "", fmt.Errorf("missing -- +goose Up annotation")
+	}
+	up, down, _ = strings.Cut(rest, "-- +goose Down")
+	return strings.TrimSpace(up), strings.TrimSpace(down), nil
+}
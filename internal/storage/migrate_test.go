@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateUpAndDown(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+
+	status, err := db.MigrationStatus(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	total := len(status)
+	latest := status[total-1].Name
+
+	applied, err := db.Migrate(ctx)
+	if err != nil || applied != total {
+		t.Fatalf("Migrate = %d, %v; want %d", applied, err, total)
+	}
+	if applied, err := db.Migrate(ctx); err != nil || applied != 0 {
+		t.Fatalf("second Migrate = %d, %v; want 0", applied, err)
+	}
+
+	rolledBack, err := db.MigrateDown(ctx, 1)
+	if err != nil || len(rolledBack) != 1 || rolledBack[0] != latest {
+		t.Fatalf("MigrateDown = %v, %v; want [%s]", rolledBack, err, latest)
+	}
+	status, err = db.MigrationStatus(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status[total-1].Applied || !status[total-2].Applied {
+		t.Errorf("after down: latest applied=%v, previous applied=%v", status[total-1].Applied, status[total-2].Applied)
+	}
+
+	if applied, err := db.Migrate(ctx); err != nil || applied != 1 {
+		t.Fatalf("re-Migrate = %d, %v; want 1", applied, err)
+	}
+
+	// Rolling everything back must leave only the version table.
+	if _, err := db.MigrateDown(ctx, total); err != nil {
+		t.Fatalf("MigrateDown all: %v", err)
+	}
+	var tables []string
+	if err := db.Select(&tables, `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`); err != nil {
+		t.Fatalf("list tables: %v", err)
+	}
+	if len(tables) != 1 || tables[0] != versionTable {
+		t.Errorf("tables after full rollback = %v", tables)
+	}
+}
+
+func TestSplitMigration(t *testing.T) {
+	up, down, err := splitMigration("-- +goose Up\nCREATE TABLE a (id TEXT);\n\n-- +goose Down\nDROP TABLE a;\n")
+	if err != nil || up != "CREATE TABLE a (id TEXT);" || down != "DROP TABLE a;" {
+		t.Errorf("splitMigration = %q, %q, %v", up, down, err)
+	}
+	if _, _, err := splitMigration("CREATE TABLE a (id TEXT);"); err == nil {
+		t.Error("missing Up annotation accepted")
+	}
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"agent-coach/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type MilestoneRepository struct {
+	db *DB
+}
+
+func NewMilestoneRepository(db *DB) *MilestoneRepository {
+	return &MilestoneRepository{db: db}
+}
+
+const milestoneColumns = `
+	m.id, m.goal_id, m.title, COALESCE(m.description, '') AS description, m.week_start, m.week_end,
+	m.status, m.created_at, m.updated_at,
+	(SELECT COUNT(*) FROM tasks t WHERE t.milestone_id = m.id) AS total_tasks,
+	(SELECT COUNT(*) FROM tasks t WHERE t.milestone_id = m.id AND t.status = 'completed') AS completed_tasks
+`
+
+func (r *MilestoneRepository) Create(ctx context.Context, milestone *models.Milestone) error {
+	if milestone.ID == "" {
+		milestone.ID = uuid.New().String()
+	}
+	if milestone.Status == "" {
+		milestone.Status = models.MilestoneStatusPending
+	}
+	milestone.CreatedAt = time.Now()
+	milestone.UpdatedAt = milestone.CreatedAt
+
+	query := `
+		INSERT INTO milestones (id, goal_id, title, description, week_start, week_end, status, created_at, updated_at)
+		VALUES (:id, :goal_id, :title, :description, :week_start, :week_end, :status, :created_at, :updated_at)
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, milestone)
+	return err
+}
+
+func (r *MilestoneRepository) GetByID(ctx context.Context, id string) (*models.Milestone, error) {
+	query := `SELECT ` + milestoneColumns + ` FROM milestones m WHERE m.id = ?`
+
+	var milestone models.Milestone
+	err := r.db.GetContext(ctx, &milestone, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &milestone, nil
+}
+
+func (r *MilestoneRepository) GetByGoalID(ctx context.Context, goalID string) ([]*models.Milestone, error) {
+	query := `SELECT ` + milestoneColumns + ` FROM milestones m WHERE m.goal_id = ? ORDER BY m.week_start ASC, m.created_at ASC`
+
+	var milestones []*models.Milestone
+	if err := r.db.SelectContext(ctx, &milestones, query, goalID); err != nil {
+		return nil, err
+	}
+	return milestones, nil
+}
+
+func (r *MilestoneRepository) Update(ctx context.Context, milestone *models.Milestone) error {
+	milestone.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE milestones SET
+			title = :title, description = :description, week_start = :week_start,
+			week_end = :week_end, status = :status, updated_at = :updated_at
+		WHERE id = :id
+	`
+
+	result, err := r.db.NamedExecContext(ctx, query, milestone)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete removes the milestone and unlinks its tasks, which are kept.
+func (r *MilestoneRepository) Delete(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE tasks SET milestone_id = NULL WHERE milestone_id = ?", id); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM milestones WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return tx.Commit()
+}
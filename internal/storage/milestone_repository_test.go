@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"agent-coach/internal/models"
+)
+
+func TestMilestoneRepositoryTracksTaskProgress(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	repo := NewMilestoneRepository(db)
+	tasks := NewTaskRepository(db)
+
+	later := &models.Milestone{GoalID: "g1", Title: "Concurrency", WeekStart: 3, WeekEnd: 4}
+	first := &models.Milestone{GoalID: "g1", Title: "Basics", Description: "Syntax and types", WeekStart: 1, WeekEnd: 2}
+	for _, milestone := range []*models.Milestone{later, first, {GoalID: "g2", Title: "Other goal", WeekStart: 1, WeekEnd: 1}} {
+		if err := repo.Create(ctx, milestone); err != nil {
+			t.Fatalf("create milestone: %v", err)
+		}
+	}
+	if first.Status != models.MilestoneStatusPending {
+		t.Errorf("new milestone status = %q, want pending", first.Status)
+	}
+
+	for _, status := range []models.TaskStatus{models.TaskStatusCompleted, models.TaskStatusPending, models.TaskStatusPending} {
+		if err := tasks.Create(ctx, &models.Task{GoalID: "g1", Title: "Exercise", Status: status, MilestoneID: &first.ID}); err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+	}
+
+	milestones, err := repo.GetByGoalID(ctx, "g1")
+	if err != nil {
+		t.Fatalf("GetByGoalID: %v", err)
+	}
+	if len(milestones) != 2 || milestones[0].ID != first.ID || milestones[1].ID != later.ID {
+		t.Fatalf("milestones = %+v, want Basics then Concurrency", milestones)
+	}
+	if got := milestones[0]; got.TotalTasks != 3 || got.CompletedTasks != 1 || got.Description != "Syntax and types" {
+		t.Errorf("Basics = %+v, want 1 of 3 tasks completed", got)
+	}
+	if got := milestones[1]; got.TotalTasks != 0 || got.CompletedTasks != 0 {
+		t.Errorf("Concurrency = %+v, want no tasks", got)
+	}
+
+	first.Status = models.MilestoneStatusInProgress
+	first.WeekEnd = 3
+	if err := repo.Update(ctx, first); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	stored, err := repo.GetByID(ctx, first.ID)
+	if err != nil || stored == nil {
+		t.Fatalf("GetByID = %v, %v", stored, err)
+	}
+	if stored.Status != models.MilestoneStatusInProgress || stored.WeekEnd != 3 || stored.TotalTasks != 3 {
+		t.Errorf("updated milestone = %+v", stored)
+	}
+
+	if err := repo.Delete(ctx, first.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if stored, err := repo.GetByID(ctx, first.ID); stored != nil || err != nil {
+		t.Errorf("GetByID after Delete = %v, %v; want nil, nil", stored, err)
+	}
+	remaining, err := tasks.GetByGoalID(ctx, "g1")
+	if err != nil {
+		t.Fatalf("GetByGoalID tasks: %v", err)
+	}
+	if len(remaining) != 3 {
+		t.Fatalf("tasks after Delete = %d, want the milestone's tasks kept", len(remaining))
+	}
+	for _, task := range remaining {
+		if task.MilestoneID != nil {
+			t.Errorf("task %s still linked to milestone %s", task.ID, *task.MilestoneID)
+		}
+	}
+
+	if err := repo.Delete(ctx, first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+	if err := repo.Update(ctx, first); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update of a deleted milestone = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"agent-coach/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type ResourceRepository struct {
+	db *DB
+}
+
+func NewResourceRepository(db *DB) *ResourceRepository {
+	return &ResourceRepository{db: db}
+}
+
+func (r *ResourceRepository) Create(ctx context.Context, resource *models.Resource) error {
+	if resource.ID == "" {
+		resource.ID = uuid.New().String()
+	}
+	if resource.Status == "" {
+		resource.Status = models.ResourceStatusToRead
+	}
+	resource.CreatedAt = time.Now()
+	resource.UpdatedAt = resource.CreatedAt
+
+	query := `
+		INSERT INTO resources (id, goal_id, milestone_id, task_id, title, type, url, status, notes, created_at, updated_at)
+		VALUES (:id, :goal_id, :milestone_id, :task_id, :title, :type, :url, :status, :notes, :created_at, :updated_at)
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, resource)
+	return err
+}
+
+func (r *ResourceRepository) GetByID(ctx context.Context, id string) (*models.Resource, error) {
+	query := `
+		SELECT id, goal_id, milestone_id, task_id, title, type, url, status, notes, created_at, updated_at
+		FROM resources WHERE id = ?
+	`
+
+	var resource models.Resource
+	err := r.db.GetContext(ctx, &resource, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &resource, nil
+}
+
+func (r *ResourceRepository) GetByGoalID(ctx context.Context, goalID string) ([]*models.Resource, error) {
+	query := `
+		SELECT id, goal_id, milestone_id, task_id, title, type, url, status, notes, created_at, updated_at
+		FROM resources WHERE goal_id = ? ORDER BY created_at ASC
+	`
+
+	var resources []*models.Resource
+	if err := r.db.SelectContext(ctx, &resources, query, goalID); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// GetForTask returns the resources attached to the task directly or through
+// its milestone.
+func (r *ResourceRepository) GetForTask(ctx context.Context, task *models.Task) ([]*models.Resource, error) {
+	query := `
+		SELECT id, goal_id, milestone_id, task_id, title, type, url, status, notes, created_at, updated_at
+		FROM resources
+		WHERE task_id = ? OR (task_id IS NULL AND milestone_id IS NOT NULL AND milestone_id = ?)
+		ORDER BY created_at ASC
+	`
+
+	var resources []*models.Resource
+	if err := r.db.SelectContext(ctx, &resources, query, task.ID, task.MilestoneID); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// Update saves the user-editable fields of a resource: its status and notes.
+func (r *ResourceRepository) Update(ctx context.Context, resource *models.Resource) error {
+	resource.UpdatedAt = time.Now()
+
+	query := `UPDATE resources SET status = :status, notes = :notes, updated_at = :updated_at WHERE id = :id`
+
+	result, err := r.db.NamedExecContext(ctx, query, resource)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *ResourceRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM resources WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"agent-coach/internal/models"
+)
+
+func TestResourceRepositoryForTask(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	repo := NewResourceRepository(db)
+
+	milestone := &models.Milestone{GoalID: "g1", Title: "Basics", WeekStart: 1, WeekEnd: 2}
+	other := &models.Milestone{GoalID: "g1", Title: "Concurrency", WeekStart: 3, WeekEnd: 4}
+	for _, m := range []*models.Milestone{milestone, other} {
+		if err := NewMilestoneRepository(db).Create(ctx, m); err != nil {
+			t.Fatalf("create milestone: %v", err)
+		}
+	}
+	task := &models.Task{GoalID: "g1", Title: "Tour of Go", Status: models.TaskStatusPending, MilestoneID: &milestone.ID}
+	sibling := &models.Task{GoalID: "g1", Title: "Effective Go", Status: models.TaskStatusPending, MilestoneID: &milestone.ID}
+	for _, tk := range []*models.Task{task, sibling} {
+		if err := NewTaskRepository(db).Create(ctx, tk); err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+	}
+
+	forTask := &models.Resource{GoalID: "g1", TaskID: &task.ID, Title: "A Tour of Go", Type: "course", URL: "https://go.dev/tour"}
+	forMilestone := &models.Resource{GoalID: "g1", MilestoneID: &milestone.ID, Title: "The Go Programming Language", Type: "book"}
+	unrelated := []*models.Resource{
+		{GoalID: "g1", Title: "Go blog"},
+		{GoalID: "g1", MilestoneID: &other.ID, Title: "Concurrency in Go"},
+		{GoalID: "g1", TaskID: &sibling.ID, MilestoneID: &milestone.ID, Title: "Effective Go"},
+	}
+	for _, resource := range append([]*models.Resource{forTask, forMilestone}, unrelated...) {
+		if err := repo.Create(ctx, resource); err != nil {
+			t.Fatalf("create resource: %v", err)
+		}
+	}
+	if forTask.Status != models.ResourceStatusToRead {
+		t.Errorf("new resource status = %q, want to_read", forTask.Status)
+	}
+
+	resources, err := repo.GetForTask(ctx, task)
+	if err != nil {
+		t.Fatalf("GetForTask: %v", err)
+	}
+	if len(resources) != 2 || resources[0].ID != forTask.ID || resources[1].ID != forMilestone.ID {
+		t.Errorf("resources for task = %+v, want its own and its milestone's", resources)
+	}
+
+	all, err := repo.GetByGoalID(ctx, "g1")
+	if err != nil || len(all) != 5 {
+		t.Errorf("GetByGoalID = %d resources, %v; want 5", len(all), err)
+	}
+
+	forTask.Status = models.ResourceStatusDone
+	forTask.Notes = "Finished the basics section"
+	forTask.Title = "Ignored"
+	if err := repo.Update(ctx, forTask); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	stored, err := repo.GetByID(ctx, forTask.ID)
+	if err != nil || stored == nil {
+		t.Fatalf("GetByID = %v, %v", stored, err)
+	}
+	if stored.Status != models.ResourceStatusDone || stored.Notes != "Finished the basics section" || stored.Title != "A Tour of Go" {
+		t.Errorf("updated resource = %+v, want only status and notes changed", stored)
+	}
+	if stored.URL != "https://go.dev/tour" || stored.TaskID == nil || *stored.TaskID != task.ID {
+		t.Errorf("stored resource = %+v", stored)
+	}
+
+	if err := repo.Delete(ctx, forTask.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if stored, err := repo.GetByID(ctx, forTask.ID); stored != nil || err != nil {
+		t.Errorf("GetByID after Delete = %v, %v; want nil, nil", stored, err)
+	}
+	if err := repo.Delete(ctx, forTask.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"agent-coach/internal/models"
+)
+
+type SettingsRepository struct {
+	db *DB
+}
+
+func NewSettingsRepository(db *DB) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+// Get returns the saved settings, or the defaults if none were saved.
+func (r *SettingsRepository) Get(ctx context.Context) (*models.UserSettings, error) {
+	query := `SELECT time_zone, rest_days, grace_days, daily_minutes, updated_at FROM user_settings WHERE id = 1`
+
+	var settings models.UserSettings
+	err := r.db.GetContext(ctx, &settings, query)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.DefaultUserSettings(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r *SettingsRepository) Save(ctx context.Context, settings *models.UserSettings) error {
+	query := `
+		INSERT INTO user_settings (id, time_zone, rest_days, grace_days, daily_minutes)
+		VALUES (1, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			time_zone = excluded.time_zone,
+			rest_days = excluded.rest_days,
+			grace_days = excluded.grace_days,
+			daily_minutes = excluded.daily_minutes,
+			updated_at = CURRENT_TIMESTAMP
+	`
+	_, err := r.db.ExecContext(ctx, query, settings.TimeZone, settings.RestDays, settings.GraceDays, settings.DailyMinutes)
+	return err
+}
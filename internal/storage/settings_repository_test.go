@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"agent-coach/internal/models"
+)
+
+func TestSettingsRestDaysRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	repo := NewSettingsRepository(db)
+
+	saved := &models.UserSettings{RestDays: models.Weekdays{time.Saturday, time.Sunday}, GraceDays: 1, DailyMinutes: 60}
+	if err := repo.Save(ctx, saved); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err := repo.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !reflect.DeepEqual(got.RestDays, saved.RestDays) {
+		t.Errorf("rest days = %v, want %v", got.RestDays, saved.RestDays)
+	}
+
+	if _, err := db.Exec(`UPDATE user_settings SET rest_days = 'not json' WHERE id = 1`); err != nil {
+		t.Fatalf("corrupt rest days: %v", err)
+	}
+	if _, err := repo.Get(ctx); err == nil {
+		t.Error("Get with corrupt rest days succeeded, want an error")
+	}
+}
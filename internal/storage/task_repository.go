@@ -9,6 +9,7 @@ import (
 	"agent-coach/internal/models"
 
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 )
 
 type TaskRepository struct {
@@ -19,31 +20,53 @@ func NewTaskRepository(db *DB) *TaskRepository {
 	return &TaskRepository{db: db}
 }
 
-func (r *TaskRepository) Create(ctx context.Context, task *models.Task) error {
+// Create saves a new task together with its dependencies on the tasks in
+// dependsOn; nothing is saved if any of them is rejected.
+func (r *TaskRepository) Create(ctx context.Context, task *models.Task, dependsOn ...string) error {
 	if task.ID == "" {
 		task.ID = uuid.New().String()
 	}
 	task.CreatedAt = time.Now()
+	task.NormalizeDue()
 
 	// entity := r.toEntity(task)
 
 	query := `
-		INSERT INTO tasks (id, goal_id, parent_id, title, description, due_date, status, 
+		INSERT INTO tasks (id, goal_id, parent_id, title, description, due_date, due_kind, status,
 			priority, difficulty_rating, estimated_minutes, actual_minutes, struggle_notes, 
-			created_at, completed_at)
-		VALUES (:id, :goal_id, :parent_id, :title, :description, :due_date, :status,
+			milestone_id, created_at, completed_at)
+		VALUES (:id, :goal_id, :parent_id, :title, :description, :due_date, :due_kind, :status,
 			:priority, :difficulty_rating, :estimated_minutes, :actual_minutes, :struggle_notes,
-			:created_at, :completed_at)
+			:milestone_id, :created_at, :completed_at)
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, task)
-	return err
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.NamedExecContext(ctx, query, task); err != nil {
+		return err
+	}
+	err = addCompletion(ctx, tx, &completion{
+		GoalID: task.GoalID, Status: string(task.Status), CompletedAt: task.CompletedAt, ActualMinutes: task.ActualMinutes,
+	}, 1)
+	if err != nil {
+		return err
+	}
+	if len(dependsOn) > 0 {
+		if err := addDependencies(ctx, tx, task.ID, dependsOn); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
 func (r *TaskRepository) GetByID(ctx context.Context, id string) (*models.Task, error) {
 	query := `
-		SELECT id, goal_id, parent_id, title, description, due_date, status, priority,
-			difficulty_rating, estimated_minutes, actual_minutes, struggle_notes, created_at, completed_at
+		SELECT id, goal_id, parent_id, title, description, due_date, due_kind, status, priority,
+			difficulty_rating, estimated_minutes, actual_minutes, struggle_notes, milestone_id, created_at, completed_at
 		FROM tasks WHERE id = ?
 	`
 
@@ -61,8 +84,8 @@ func (r *TaskRepository) GetByID(ctx context.Context, id string) (*models.Task,
 
 func (r *TaskRepository) GetByGoalID(ctx context.Context, goalID string) ([]*models.Task, error) {
 	query := `
-		SELECT id, goal_id, parent_id, title, description, due_date, status, priority,
-			difficulty_rating, estimated_minutes, actual_minutes, struggle_notes, created_at, completed_at
+		SELECT id, goal_id, parent_id, title, description, due_date, due_kind, status, priority,
+			difficulty_rating, estimated_minutes, actual_minutes, struggle_notes, milestone_id, created_at, completed_at
 		FROM tasks WHERE goal_id = ? ORDER BY priority DESC, due_date ASC
 	`
 
@@ -81,8 +104,8 @@ func (r *TaskRepository) GetByGoalID(ctx context.Context, goalID string) ([]*mod
 
 func (r *TaskRepository) GetPendingByGoalID(ctx context.Context, goalID string) ([]*models.Task, error) {
 	query := `
-		SELECT id, goal_id, parent_id, title, description, due_date, status, priority,
-			difficulty_rating, estimated_minutes, actual_minutes, struggle_notes, created_at, completed_at
+		SELECT id, goal_id, parent_id, title, description, due_date, due_kind, status, priority,
+			difficulty_rating, estimated_minutes, actual_minutes, struggle_notes, milestone_id, created_at, completed_at
 		FROM tasks WHERE goal_id = ? AND status IN ('pending', 'in_progress') 
 		ORDER BY priority DESC, due_date ASC
 	`
@@ -100,17 +123,113 @@ func (r *TaskRepository) GetPendingByGoalID(ctx context.Context, goalID string)
 	return tasks, nil
 }
 
-func (r *TaskRepository) GetDueToday(ctx context.Context) ([]*models.Task, error) {
+// GetOpen returns the pending and in-progress tasks of a goal, or of every
+// goal when goalID is empty.
+func (r *TaskRepository) GetOpen(ctx context.Context, goalID string) ([]*models.Task, error) {
+	query := `
+		SELECT id, goal_id, parent_id, title, description, due_date, due_kind, status, priority,
+			difficulty_rating, estimated_minutes, actual_minutes, struggle_notes, milestone_id, created_at, completed_at
+		FROM tasks WHERE status IN ('pending', 'in_progress') AND (? = '' OR goal_id = ?)
+		ORDER BY priority DESC, due_date ASC
+	`
+
+	var entities []models.Task
+	if err := r.db.SelectContext(ctx, &entities, query, goalID, goalID); err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*models.Task, len(entities))
+	for i, entity := range entities {
+		tasks[i] = &entity
+	}
+
+	return tasks, nil
+}
+
+// unblocked is a condition on tasks that holds when none of the task's
+// prerequisites is still open.
+const unblocked = `NOT EXISTS (
+	SELECT 1 FROM task_dependencies d JOIN tasks p ON p.id = d.depends_on_id
+	WHERE d.task_id = tasks.id AND p.status IN ('pending', 'in_progress')
+)`
+
+// GetDueToday returns the open tasks due today in loc that can be started:
+// tasks still waiting on a prerequisite are left out.
+func (r *TaskRepository) GetDueToday(ctx context.Context, loc *time.Location) ([]*models.Task, error) {
+	today := time.Now().In(loc)
+	return r.getDueBetween(ctx, today, today, loc, true)
+}
+
+// GetDueThisWeek returns the open tasks due in the current Monday-to-Sunday
+// week in loc.
+func (r *TaskRepository) GetDueThisWeek(ctx context.Context, loc *time.Location) ([]*models.Task, error) {
+	monday, sunday := weekOf(time.Now().In(loc))
+	return r.GetDueBetween(ctx, monday, sunday, loc)
+}
+
+// GetDueBetween returns the open tasks due on the calendar days from through
+// to (inclusive), taking each day's date from its own location and its
+// bounds from loc. Date-only due dates match by date; datetime due dates
+// match when the instant falls between local midnight on from and local
+// midnight after to.
+func (r *TaskRepository) GetDueBetween(ctx context.Context, from, to time.Time, loc *time.Location) ([]*models.Task, error) {
+	return r.getDueBetween(ctx, from, to, loc, false)
+}
+
+func (r *TaskRepository) getDueBetween(ctx context.Context, from, to time.Time, loc *time.Location, unblockedOnly bool) ([]*models.Task, error) {
+	fromDay, toDay := models.CalendarDate(from), models.CalendarDate(to)
+	start := time.Date(fromDay.Year(), fromDay.Month(), fromDay.Day(), 0, 0, 0, 0, loc)
+	end := time.Date(toDay.Year(), toDay.Month(), toDay.Day()+1, 0, 0, 0, 0, loc)
+
+	query := `
+		SELECT id, goal_id, parent_id, title, description, due_date, due_kind, status, priority,
+			difficulty_rating, estimated_minutes, actual_minutes, struggle_notes, milestone_id, created_at, completed_at
+		FROM tasks
+		WHERE status IN ('pending', 'in_progress') AND (
+			(due_kind = 'date' AND substr(due_date, 1, 10) BETWEEN ? AND ?)
+			OR (due_kind = 'datetime' AND julianday(due_date) >= julianday(?) AND julianday(due_date) < julianday(?))
+		)
+	`
+	if unblockedOnly {
+		query += " AND " + unblocked
+	}
+	query += " ORDER BY priority DESC, due_date ASC"
+
+	var entities []models.Task
+	err := r.db.SelectContext(ctx, &entities, query,
+		fromDay.Format("2006-01-02"), toDay.Format("2006-01-02"),
+		start.UTC().Format(sqliteTimeFormat), end.UTC().Format(sqliteTimeFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*models.Task, len(entities))
+	for i, entity := range entities {
+		tasks[i] = &entity
+	}
+
+	return tasks, nil
+}
+
+// GetOverdue returns the open tasks that are past due at now: date-only
+// tasks due before today in now's location and datetime tasks due before
+// now. An empty goalID covers every goal.
+func (r *TaskRepository) GetOverdue(ctx context.Context, goalID string, now time.Time) ([]*models.Task, error) {
 	query := `
-		SELECT id, goal_id, parent_id, title, description, due_date, status, priority,
-			difficulty_rating, estimated_minutes, actual_minutes, struggle_notes, created_at, completed_at
-		FROM tasks 
-		WHERE date(due_date) = date('now') AND status IN ('pending', 'in_progress')
-		ORDER BY priority DESC
+		SELECT id, goal_id, parent_id, title, description, due_date, due_kind, status, priority,
+			difficulty_rating, estimated_minutes, actual_minutes, struggle_notes, milestone_id, created_at, completed_at
+		FROM tasks
+		WHERE status IN ('pending', 'in_progress') AND (? = '' OR goal_id = ?) AND (
+			(due_kind = 'date' AND substr(due_date, 1, 10) < ?)
+			OR (due_kind = 'datetime' AND julianday(due_date) < julianday(?))
+		)
+		ORDER BY priority DESC, due_date ASC
 	`
 
 	var entities []models.Task
-	if err := r.db.SelectContext(ctx, &entities, query); err != nil {
+	err := r.db.SelectContext(ctx, &entities, query, goalID, goalID,
+		models.CalendarDate(now).Format("2006-01-02"), now.UTC().Format(sqliteTimeFormat))
+	if err != nil {
 		return nil, err
 	}
 
@@ -122,47 +241,84 @@ func (r *TaskRepository) GetDueToday(ctx context.Context) ([]*models.Task, error
 	return tasks, nil
 }
 
+// weekOf returns the Monday and Sunday of t's week.
+func weekOf(t time.Time) (time.Time, time.Time) {
+	offset := (int(t.Weekday()) + 6) % 7
+	monday := time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+	return monday, monday.AddDate(0, 0, 6)
+}
+
 func (r *TaskRepository) Update(ctx context.Context, task *models.Task) error {
+	task.NormalizeDue()
+
 	query := `
 		UPDATE tasks SET 
-			title = :title, description = :description, due_date = :due_date,
+			title = :title, description = :description, due_date = :due_date, due_kind = :due_kind,
 			status = :status, priority = :priority, difficulty_rating = :difficulty_rating,
 			estimated_minutes = :estimated_minutes, actual_minutes = :actual_minutes,
-			struggle_notes = :struggle_notes, completed_at = :completed_at
+			struggle_notes = :struggle_notes, milestone_id = :milestone_id, completed_at = :completed_at
 		WHERE id = :id
 	`
 
-	result, err := r.db.NamedExecContext(ctx, query, task)
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	rows, err := result.RowsAffected()
+	before, err := getCompletion(ctx, tx, task.ID)
 	if err != nil {
 		return err
 	}
-	if rows == 0 {
+	if before == nil {
 		return ErrNotFound
 	}
+	if _, err := tx.NamedExecContext(ctx, query, task); err != nil {
+		return err
+	}
+	if err := r.moveCompletion(ctx, tx, task.ID, before); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
 
-	return nil
+// moveCompletion replaces the task's completion as it was before a change
+// with its current one in daily activity.
+func (r *TaskRepository) moveCompletion(ctx context.Context, tx *sqlx.Tx, id string, before *completion) error {
+	after, err := getCompletion(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if err := addCompletion(ctx, tx, before, -1); err != nil {
+		return err
+	}
+	return addCompletion(ctx, tx, after, 1)
 }
 
 func (r *TaskRepository) Delete(ctx context.Context, id string) error {
-	result, err := r.db.ExecContext(ctx, "DELETE FROM tasks WHERE id = ?", id)
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	rows, err := result.RowsAffected()
+	before, err := getCompletion(ctx, tx, id)
 	if err != nil {
 		return err
 	}
-	if rows == 0 {
+	if before == nil {
 		return ErrNotFound
 	}
-
-	return nil
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tasks WHERE id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM task_dependencies WHERE task_id = ? OR depends_on_id = ?", id, id); err != nil {
+		return err
+	}
+	if err := addCompletion(ctx, tx, before, -1); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *TaskRepository) MarkComplete(ctx context.Context, id string, actualMinutes *int) error {
@@ -174,20 +330,26 @@ func (r *TaskRepository) MarkComplete(ctx context.Context, id string, actualMinu
 		minutes = *actualMinutes
 	}
 
-	result, err := r.db.ExecContext(ctx, query, now, minutes, id)
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	rows, err := result.RowsAffected()
+	before, err := getCompletion(ctx, tx, id)
 	if err != nil {
 		return err
 	}
-	if rows == 0 {
+	if before == nil {
 		return ErrNotFound
 	}
-
-	return nil
+	if _, err := tx.ExecContext(ctx, query, now, minutes, id); err != nil {
+		return err
+	}
+	if err := r.moveCompletion(ctx, tx, id, before); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *TaskRepository) LogStruggle(ctx context.Context, id string, notes string) error {
@@ -207,3 +369,31 @@ func (r *TaskRepository) LogStruggle(ctx context.Context, id string, notes strin
 
 	return nil
 }
+
+// Reschedule moves each task in dueDates, keyed by task ID, to its new due
+// date in one transaction: if any task is missing, none of them move.
+func (r *TaskRepository) Reschedule(ctx context.Context, dueDates map[string]time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for id, dueDate := range dueDates {
+		before, err := getCompletion(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return ErrNotFound
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE tasks SET due_date = ?, updated_at = ? WHERE id = ?`, dueDate, now, id); err != nil {
+			return err
+		}
+		if err := r.moveCompletion(ctx, tx, id, before); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
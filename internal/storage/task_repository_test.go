@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"agent-coach/internal/models"
+)
+
+func TestGetDueBetweenUsesUserTimeZoneAcrossDST(t *testing.T) {
+	ctx := context.Background()
+	repo := NewTaskRepository(newTestDB(t))
+
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load zone: %v", err)
+	}
+	at := func(year int, month time.Month, day, hour, min int) *time.Time {
+		due := time.Date(year, month, day, hour, min, 0, 0, ny)
+		return &due
+	}
+	onDay := func(year int, month time.Month, day int) *time.Time {
+		due := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		return &due
+	}
+
+	// Clocks spring forward on 2026-03-08 and fall back on 2026-11-01 in
+	// New York, so those days last 23 and 25 hours.
+	tasks := []*models.Task{
+		{Title: "date only", DueDate: onDay(2026, 3, 8)},
+		{Title: "just after midnight", DueKind: models.DueKindDateTime, DueDate: at(2026, 3, 8, 0, 30)},
+		{Title: "late evening", DueKind: models.DueKindDateTime, DueDate: at(2026, 3, 8, 23, 30)},
+		{Title: "previous evening", DueKind: models.DueKindDateTime, DueDate: at(2026, 3, 7, 23, 30)},
+		{Title: "next day", DueKind: models.DueKindDateTime, DueDate: at(2026, 3, 9, 0, 30)},
+		{Title: "fall back evening", DueKind: models.DueKindDateTime, DueDate: at(2026, 11, 1, 23, 30)},
+		{Title: "fall back next day", DueKind: models.DueKindDateTime, DueDate: at(2026, 11, 2, 0, 15)},
+		{Title: "completed", Status: models.TaskStatusCompleted, DueDate: onDay(2026, 3, 8)},
+	}
+	for _, task := range tasks {
+		task.GoalID = "goal-1"
+		if task.Status == "" {
+			task.Status = models.TaskStatusPending
+		}
+		if err := repo.Create(ctx, task); err != nil {
+			t.Fatalf("create %s: %v", task.Title, err)
+		}
+	}
+
+	titles := func(from, to time.Time) []string {
+		t.Helper()
+		due, err := repo.GetDueBetween(ctx, from, to, ny)
+		if err != nil {
+			t.Fatalf("GetDueBetween: %v", err)
+		}
+		var out []string
+		for _, task := range due {
+			out = append(out, task.Title)
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     []string
+	}{
+		{"spring forward day", time.Date(2026, 3, 8, 12, 0, 0, 0, ny), time.Date(2026, 3, 8, 12, 0, 0, 0, ny),
+			[]string{"date only", "just after midnight", "late evening"}},
+		{"day before", time.Date(2026, 3, 7, 9, 0, 0, 0, ny), time.Date(2026, 3, 7, 9, 0, 0, 0, ny),
+			[]string{"previous evening"}},
+		{"fall back day", time.Date(2026, 11, 1, 1, 30, 0, 0, ny), time.Date(2026, 11, 1, 1, 30, 0, 0, ny),
+			[]string{"fall back evening"}},
+		{"week across the change", time.Date(2026, 3, 2, 0, 0, 0, 0, ny), time.Date(2026, 3, 8, 0, 0, 0, 0, ny),
+			[]string{"date only", "just after midnight", "late evening", "previous evening"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := titles(tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("due = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeekOf(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load zone: %v", err)
+	}
+
+	for _, day := range []int{9, 11, 15} {
+		monday, sunday := weekOf(time.Date(2026, 3, day, 22, 0, 0, 0, ny))
+		if monday.Format("2006-01-02") != "2026-03-09" || sunday.Format("2006-01-02") != "2026-03-15" {
+			t.Errorf("weekOf(March %d) = %s..%s, want 2026-03-09..2026-03-15", day, monday.Format("2006-01-02"), sunday.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestGetOverdue(t *testing.T) {
+	ctx := context.Background()
+	repo := NewTaskRepository(newTestDB(t))
+
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	day := func(d int) *time.Time {
+		due := time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC)
+		return &due
+	}
+	hour := func(h int) *time.Time {
+		due := time.Date(2026, 3, 10, h, 0, 0, 0, time.UTC)
+		return &due
+	}
+
+	for _, task := range []*models.Task{
+		{GoalID: "g1", Title: "yesterday", DueDate: day(9), Priority: 1},
+		{GoalID: "g1", Title: "today", DueDate: day(10)},
+		{GoalID: "g1", Title: "this morning", DueKind: models.DueKindDateTime, DueDate: hour(9)},
+		{GoalID: "g1", Title: "tonight", DueKind: models.DueKindDateTime, DueDate: hour(20)},
+		{GoalID: "g1", Title: "done", DueDate: day(8), Status: models.TaskStatusCompleted},
+		{GoalID: "g2", Title: "other goal", DueDate: day(1)},
+	} {
+		if task.Status == "" {
+			task.Status = models.TaskStatusPending
+		}
+		if err := repo.Create(ctx, task); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	titles := func(goalID string) []string {
+		tasks, err := repo.GetOverdue(ctx, goalID, now)
+		if err != nil {
+			t.Fatalf("GetOverdue: %v", err)
+		}
+		var out []string
+		for _, task := range tasks {
+			out = append(out, task.Title)
+		}
+		return out
+	}
+
+	if got, want := titles("g1"), []string{"yesterday", "this morning"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("g1 overdue = %v, want %v", got, want)
+	}
+	if got := titles(""); len(got) != 3 {
+		t.Errorf("all overdue = %v, want 3 tasks", got)
+	}
+}
+
+func TestRescheduleMovesAllTasksOrNone(t *testing.T) {
+	ctx := context.Background()
+	repo := NewTaskRepository(newTestDB(t))
+
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	due := day(9)
+	first := &models.Task{GoalID: "g1", Title: "first", DueDate: &due, Status: models.TaskStatusPending}
+	second := &models.Task{GoalID: "g1", Title: "second", DueDate: &due, Status: models.TaskStatusPending}
+	for _, task := range []*models.Task{first, second} {
+		if err := repo.Create(ctx, task); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	dueDay := func(id string) time.Time {
+		task, err := repo.GetByID(ctx, id)
+		if err != nil || task == nil {
+			t.Fatalf("GetByID(%s) = %v, %v", id, task, err)
+		}
+		return task.DueDate.UTC()
+	}
+
+	err := repo.Reschedule(ctx, map[string]time.Time{first.ID: day(11), "missing": day(12)})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Reschedule with a missing task = %v, want ErrNotFound", err)
+	}
+	if got := dueDay(first.ID); !got.Equal(day(9)) {
+		t.Errorf("first moved to %s although the batch failed", got)
+	}
+
+	if err := repo.Reschedule(ctx, map[string]time.Time{first.ID: day(11), second.ID: day(12)}); err != nil {
+		t.Fatalf("Reschedule: %v", err)
+	}
+	if got := dueDay(first.ID); !got.Equal(day(11)) {
+		t.Errorf("first due %s, want %s", got, day(11))
+	}
+	if got := dueDay(second.ID); !got.Equal(day(12)) {
+		t.Errorf("second due %s, want %s", got, day(12))
+	}
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"agent-coach/internal/models"
+)
+
+type TraceRepository struct {
+	db *DB
+}
+
+func NewTraceRepository(db *DB) *TraceRepository {
+	return &TraceRepository{db: db}
+}
+
+// turnStepRow carries the JSON-encoded tool_calls column.
+type turnStepRow struct {
+	models.TurnStep
+	ToolCallsJSON sql.NullString `db:"tool_calls"`
+}
+
+// CreateSteps stores all steps of a turn atomically.
+func (r *TraceRepository) CreateSteps(ctx context.Context, steps []*models.TurnStep) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO turn_steps (turn_id, goal_id, agent_type, seq, role, content, tool_calls,
+			tool_call_id, tool_name, error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	for _, step := range steps {
+		if step.CreatedAt.IsZero() {
+			step.CreatedAt = time.Now()
+		}
+		var toolCalls sql.NullString
+		if len(step.ToolCalls) > 0 {
+			toolCalls = toNullString(marshalJSON(step.ToolCalls))
+		}
+		result, err := tx.ExecContext(ctx, query, step.TurnID, step.GoalID, step.AgentType, step.Seq,
+			step.Role, step.Content, toolCalls, step.ToolCallID, step.ToolName, step.Error, step.CreatedAt)
+		if err != nil {
+			return err
+		}
+		if step.ID, err = result.LastInsertId(); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *TraceRepository) GetByTurnID(ctx context.Context, turnID string) ([]*models.TurnStep, error) {
+	query := `
+		SELECT id, turn_id, goal_id, agent_type, seq, role, content, tool_calls,
+			tool_call_id, tool_name, error, created_at
+		FROM turn_steps WHERE turn_id = ? ORDER BY seq ASC
+	`
+
+	var rows []turnStepRow
+	if err := r.db.SelectContext(ctx, &rows, query, turnID); err != nil {
+		return nil, err
+	}
+
+	steps := make([]*models.TurnStep, len(rows))
+	for i := range rows {
+		step := rows[i].TurnStep
+		if rows[i].ToolCallsJSON.Valid {
+			json.Unmarshal([]byte(rows[i].ToolCallsJSON.String), &step.ToolCalls)
+		}
+		steps[i] = &step
+	}
+
+	return steps, nil
+}
@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"agent-coach/internal/models"
+)
+
+// sqliteTimeFormat matches the format SQLite uses for CURRENT_TIMESTAMP, so
+// bounds compare correctly against created_at.
+const sqliteTimeFormat = "2006-01-02 15:04:05"
+
+type UsageRepository struct {
+	db *DB
+}
+
+func NewUsageRepository(db *DB) *UsageRepository {
+	return &UsageRepository{db: db}
+}
+
+func (r *UsageRepository) Create(ctx context.Context, usage *models.LLMUsage) error {
+	query := `
+		INSERT INTO llm_usage (provider, model, agent_type, goal_id, intent, prompt_tokens,
+			completion_tokens, total_tokens, cost_usd, latency_ms, finish_reason, error)
+		VALUES (:provider, :model, :agent_type, :goal_id, :intent, :prompt_tokens,
+			:completion_tokens, :total_tokens, :cost_usd, :latency_ms, :finish_reason, :error)
+	`
+
+	result, err := r.db.NamedExecContext(ctx, query, usage)
+	if err != nil {
+		return err
+	}
+	usage.ID, err = result.LastInsertId()
+	return err
+}
+
+// CostSince returns the total cost of calls made at or after since.
+func (r *UsageRepository) CostSince(ctx context.Context, since time.Time) (float64, error) {
+	query := `SELECT COALESCE(SUM(cost_usd), 0) FROM llm_usage WHERE created_at >= ?`
+
+	var cost float64
+	if err := r.db.GetContext(ctx, &cost, query, since.UTC().Format(sqliteTimeFormat)); err != nil {
+		return 0, err
+	}
+	return cost, nil
+}
+
+var usageGroupColumns = map[models.UsageGroupBy]string{
+	models.UsageGroupByGoal:     "goal_id",
+	models.UsageGroupByAgent:    "agent_type",
+	models.UsageGroupByModel:    "model",
+	models.UsageGroupByProvider: "provider",
+}
+
+var usagePeriodFormats = map[models.UsagePeriod]string{
+	models.UsagePeriodDay:   "%Y-%m-%d",
+	models.UsagePeriodMonth: "%Y-%m",
+}
+
+func (r *UsageRepository) Aggregate(ctx context.Context, q models.UsageQuery) ([]*models.UsageAggregate, error) {
+	column, ok := usageGroupColumns[q.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported usage grouping: %q", q.GroupBy)
+	}
+	format, ok := usagePeriodFormats[q.Period]
+	if !ok {
+		return nil, fmt.Errorf("unsupported usage period: %q", q.Period)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT strftime('%s', created_at) AS period, %s AS key, COUNT(*) AS calls,
+			SUM(prompt_tokens) AS prompt_tokens, SUM(completion_tokens) AS completion_tokens,
+			SUM(total_tokens) AS total_tokens, SUM(cost_usd) AS cost_usd
+		FROM llm_usage
+		WHERE created_at >= ? AND created_at < ?
+		GROUP BY period, key
+		ORDER BY period ASC, cost_usd DESC
+	`, format, column)
+
+	from, to, err := usageRange(q)
+	if err != nil {
+		return nil, err
+	}
+
+	var aggregates []*models.UsageAggregate
+	if err := r.db.SelectContext(ctx, &aggregates, query, from, to); err != nil {
+		return nil, err
+	}
+	return aggregates, nil
+}
+
+// usageRange turns the inclusive date range of q into half-open bounds.
+func usageRange(q models.UsageQuery) (string, string, error) {
+	from := "0000-01-01 00:00:00"
+	to := "9999-12-31 23:59:59"
+	if q.From != "" {
+		day, err := time.Parse("2006-01-02", q.From)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid from date: %w", err)
+		}
+		from = day.Format(sqliteTimeFormat)
+	}
+	if q.To != "" {
+		day, err := time.Parse("2006-01-02", q.To)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid to date: %w", err)
+		}
+		to = day.AddDate(0, 0, 1).Format(sqliteTimeFormat)
+	}
+	return from, to, nil
+}
+
+func (r *UsageRepository) GetPrice(ctx context.Context, model string) (*models.ModelPrice, error) {
+	query := `SELECT * FROM model_prices WHERE model = ?`
+
+	var price models.ModelPrice
+	err := r.db.GetContext(ctx, &price, query, model)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &price, nil
+}
+
+func (r *UsageRepository) ListPrices(ctx context.Context) ([]*models.ModelPrice, error) {
+	var prices []*models.ModelPrice
+	if err := r.db.SelectContext(ctx, &prices, `SELECT * FROM model_prices ORDER BY model`); err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// SavePrice inserts or replaces the price for price.Model.
+func (r *UsageRepository) SavePrice(ctx context.Context, price *models.ModelPrice) error {
+	query := `
+		INSERT INTO model_prices (model, input_per_million, output_per_million)
+		VALUES (:model, :input_per_million, :output_per_million)
+		ON CONFLICT(model) DO UPDATE SET
+			input_per_million = excluded.input_per_million,
+			output_per_million = excluded.output_per_million,
+			updated_at = CURRENT_TIMESTAMP
+	`
+	_, err := r.db.NamedExecContext(ctx, query, price)
+	return err
+}
+
+func (r *UsageRepository) DeletePrice(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM model_prices WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// GetBudget returns the configured budget, or nil if none was ever saved.
+func (r *UsageRepository) GetBudget(ctx context.Context) (*models.UsageBudget, error) {
+	query := `
+		SELECT monthly_limit_usd, action, downgrade_provider, downgrade_model, is_active, updated_at
+		FROM usage_budget WHERE id = 1
+	`
+
+	var budget models.UsageBudget
+	err := r.db.GetContext(ctx, &budget, query)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+func (r *UsageRepository) SaveBudget(ctx context.Context, budget *models.UsageBudget) error {
+	query := `
+		INSERT INTO usage_budget (id, monthly_limit_usd, action, downgrade_provider, downgrade_model, is_active)
+		VALUES (1, :monthly_limit_usd, :action, :downgrade_provider, :downgrade_model, :is_active)
+		ON CONFLICT(id) DO UPDATE SET
+			monthly_limit_usd = excluded.monthly_limit_usd,
+			action = excluded.action,
+			downgrade_provider = excluded.downgrade_provider,
+			downgrade_model = excluded.downgrade_model,
+			is_active = excluded.is_active,
+			updated_at = CURRENT_TIMESTAMP
+	`
+	_, err := r.db.NamedExecContext(ctx, query, budget)
+	return err
+}
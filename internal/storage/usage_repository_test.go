@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"agent-coach/internal/models"
+)
+
+func TestUsageAggregateGroupsByPeriod(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	repo := NewUsageRepository(db)
+
+	insert := func(createdAt, model string, tokens int, cost float64) {
+		t.Helper()
+		_, err := db.Exec(`
+			INSERT INTO llm_usage (provider, model, prompt_tokens, completion_tokens, total_tokens, cost_usd, created_at)
+			VALUES ('openrouter', ?, ?, 0, ?, ?, ?)
+		`, model, tokens, tokens, cost, createdAt)
+		if err != nil {
+			t.Fatalf("insert usage: %v", err)
+		}
+	}
+	insert("2026-02-28 23:59:59", "small", 100, 0.25)
+	insert("2026-03-01 08:00:00", "small", 200, 0.5)
+	insert("2026-03-01 09:00:00", "large", 300, 2)
+	insert("2026-03-02 10:00:00", "small", 400, 1)
+
+	days, err := repo.Aggregate(ctx, models.UsageQuery{GroupBy: models.UsageGroupByModel, Period: models.UsagePeriodDay, From: "2026-03-01", To: "2026-03-01"})
+	if err != nil {
+		t.Fatalf("Aggregate days: %v", err)
+	}
+	wantDays := []*models.UsageAggregate{
+		{Period: "2026-03-01", Key: "large", Calls: 1, PromptTokens: 300, TotalTokens: 300, CostUSD: 2},
+		{Period: "2026-03-01", Key: "small", Calls: 1, PromptTokens: 200, TotalTokens: 200, CostUSD: 0.5},
+	}
+	if !reflect.DeepEqual(days, wantDays) {
+		t.Errorf("days = %+v, want %+v", days, wantDays)
+	}
+
+	months, err := repo.Aggregate(ctx, models.UsageQuery{GroupBy: models.UsageGroupByProvider, Period: models.UsagePeriodMonth})
+	if err != nil {
+		t.Fatalf("Aggregate months: %v", err)
+	}
+	wantMonths := []*models.UsageAggregate{
+		{Period: "2026-02", Key: "openrouter", Calls: 1, PromptTokens: 100, TotalTokens: 100, CostUSD: 0.25},
+		{Period: "2026-03", Key: "openrouter", Calls: 3, PromptTokens: 900, TotalTokens: 900, CostUSD: 3.5},
+	}
+	if !reflect.DeepEqual(months, wantMonths) {
+		t.Errorf("months = %+v, want %+v", months, wantMonths)
+	}
+
+	if _, err := repo.Aggregate(ctx, models.UsageQuery{GroupBy: "day_of_week", Period: models.UsagePeriodDay}); err == nil {
+		t.Error("unsupported grouping succeeded")
+	}
+}
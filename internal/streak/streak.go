@@ -0,0 +1,86 @@
+// Package streak turns daily activity into streaks and heatmap calendars.
+package streak
+
+import (
+	"time"
+
+	"agent-coach/internal/models"
+)
+
+const dayFormat = "2006-01-02"
+
+// Compute walks the days from the first active day up to today, where today
+// is the current date in the user's time zone. Active days extend the streak;
+// rest days are skipped; a run of missed days longer than the grace period
+// ends it. Today never breaks the streak, since it is not over yet.
+func Compute(days []*models.DailyActivity, settings *models.UserSettings, today time.Time) *models.Streak {
+	streak := &models.Streak{}
+
+	active := make(map[string]bool, len(days))
+	first := ""
+	for _, day := range days {
+		if !day.Active() {
+			continue
+		}
+		active[day.Day] = true
+		if first == "" || day.Day < first {
+			first = day.Day
+		}
+		if day.Day > streak.LastActiveDay {
+			streak.LastActiveDay = day.Day
+		}
+	}
+	if first == "" {
+		return streak
+	}
+
+	start, err := time.ParseInLocation(dayFormat, first, today.Location())
+	if err != nil {
+		return streak
+	}
+	end := dateOf(today)
+
+	run, missed := 0, 0
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		switch {
+		case active[d.Format(dayFormat)]:
+			run++
+			missed = 0
+			streak.Longest = max(streak.Longest, run)
+		case settings.IsRestDay(d.Weekday()), d.Equal(end):
+		default:
+			missed++
+			if missed > settings.GraceDays {
+				run = 0
+			}
+		}
+	}
+
+	streak.Current = run
+	streak.ActiveToday = active[end.Format(dayFormat)]
+	return streak
+}
+
+// Calendar returns one entry per day from from to to inclusive, filling days
+// without activity with zero counts, for a heatmap.
+func Calendar(days []*models.DailyActivity, goalID string, from, to time.Time) []*models.DailyActivity {
+	byDay := make(map[string]*models.DailyActivity, len(days))
+	for _, day := range days {
+		byDay[day.Day] = day
+	}
+
+	var calendar []*models.DailyActivity
+	for d := dateOf(from); !d.After(dateOf(to)); d = d.AddDate(0, 0, 1) {
+		key := d.Format(dayFormat)
+		if day, ok := byDay[key]; ok {
+			calendar = append(calendar, day)
+			continue
+		}
+		calendar = append(calendar, &models.DailyActivity{GoalID: goalID, Day: key})
+	}
+	return calendar
+}
+
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,76 @@
+package streak
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"agent-coach/internal/models"
+)
+
+func activeDays(days ...string) []*models.DailyActivity {
+	var activity []*models.DailyActivity
+	for _, day := range days {
+		activity = append(activity, &models.DailyActivity{Day: day, TasksCompleted: 1})
+	}
+	return activity
+}
+
+func TestCompute(t *testing.T) {
+	// 2026-03-13 is a Friday.
+	today := time.Date(2026, 3, 13, 21, 0, 0, 0, time.Local)
+	weekends := []time.Weekday{time.Saturday, time.Sunday}
+
+	tests := []struct {
+		name     string
+		days     []*models.DailyActivity
+		restDays []time.Weekday
+		grace    int
+		want     models.Streak
+	}{
+		{"no activity", nil, nil, 1, models.Streak{}},
+		{"active through today", activeDays("2026-03-11", "2026-03-12", "2026-03-13"), nil, 0,
+			models.Streak{Current: 3, Longest: 3, ActiveToday: true, LastActiveDay: "2026-03-13"}},
+		{"today is not over yet", activeDays("2026-03-11", "2026-03-12"), nil, 0,
+			models.Streak{Current: 2, Longest: 2, LastActiveDay: "2026-03-12"}},
+		{"missed day without grace", activeDays("2026-03-10", "2026-03-11", "2026-03-13"), nil, 0,
+			models.Streak{Current: 1, Longest: 2, ActiveToday: true, LastActiveDay: "2026-03-13"}},
+		{"missed day within grace", activeDays("2026-03-10", "2026-03-11", "2026-03-13"), nil, 1,
+			models.Streak{Current: 3, Longest: 3, ActiveToday: true, LastActiveDay: "2026-03-13"}},
+		{"gap longer than grace", activeDays("2026-03-09", "2026-03-12"), nil, 1,
+			models.Streak{Current: 1, Longest: 1, LastActiveDay: "2026-03-12"}},
+		{"weekend rest days", activeDays("2026-03-05", "2026-03-06", "2026-03-09", "2026-03-10", "2026-03-11", "2026-03-12"), weekends, 0,
+			models.Streak{Current: 6, Longest: 6, LastActiveDay: "2026-03-12"}},
+		{"streak ended", activeDays("2026-03-01", "2026-03-02", "2026-03-03"), nil, 1,
+			models.Streak{Current: 0, Longest: 3, LastActiveDay: "2026-03-03"}},
+		{"check-ins count", []*models.DailyActivity{{Day: "2026-03-12", CheckIns: 1}, {Day: "2026-03-13"}}, nil, 0,
+			models.Streak{Current: 1, Longest: 1, LastActiveDay: "2026-03-12"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &models.UserSettings{RestDays: tt.restDays, GraceDays: tt.grace}
+			if got := Compute(tt.days, settings, today); !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Compute = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendarFillsEmptyDays(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2026, 3, 4, 18, 0, 0, 0, time.Local)
+	days := []*models.DailyActivity{{GoalID: "g", Day: "2026-03-02", TasksCompleted: 2, MinutesSpent: 50}}
+
+	calendar := Calendar(days, "g", from, to)
+	var got []string
+	for _, day := range calendar {
+		got = append(got, day.Day)
+	}
+	if want := []string{"2026-03-01", "2026-03-02", "2026-03-03", "2026-03-04"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("calendar days = %v, want %v", got, want)
+	}
+	if calendar[1] != days[0] || calendar[0].Active() || calendar[0].GoalID != "g" {
+		t.Errorf("calendar = %+v", calendar)
+	}
+}
@@ -3,26 +3,36 @@ package tool
 import "agent-coach/internal/models"
 
 var ToolSendCheckIn = models.Tool{
+	Type:        "function",
 	Name:        "send_checkin",
 	Description: "Send a check-in message to the user",
 	Parameters: map[string]models.ToolParam{
 		"message":   {Type: "string", Description: "Check-in message", Required: true},
-		"questions": {Type: "array", Description: "Questions to ask", Required: false},
+		"questions": {Type: "array", Description: "Questions to ask", Required: false, Items: &models.ToolParam{Type: "string"}},
 	},
 }
 
 var ToolRecordCheckIn = models.Tool{
+	Type:        "function",
 	Name:        "record_checkin",
 	Description: "Record the user's check-in response",
 	Parameters: map[string]models.ToolParam{
-		"summary":     {Type: "string", Description: "Summary of the check-in", Required: true},
-		"struggles":   {Type: "array", Description: "Things the user struggled with", Required: false},
-		"wins":        {Type: "array", Description: "Things that went well", Required: false},
-		"mood_rating": {Type: "integer", Description: "Mood rating 1-5", Required: false},
+		"summary":     {Type: "string", Description: "Summary of the check-in", Required: true, MinLength: intPtr(1)},
+		"struggles":   {Type: "array", Description: "Things the user struggled with", Required: false, Items: &models.ToolParam{Type: "string"}},
+		"wins":        {Type: "array", Description: "Things that went well", Required: false, Items: &models.ToolParam{Type: "string"}},
+		"mood_rating": {Type: "integer", Description: "Mood rating 1-5", Required: false, Minimum: floatPtr(1), Maximum: floatPtr(5)},
 	},
 }
 
+type recordCheckInArgs struct {
+	Summary    string   `json:"summary"`
+	Struggles  []string `json:"struggles"`
+	Wins       []string `json:"wins"`
+	MoodRating *int     `json:"mood_rating"`
+}
+
 var ToolAdjustPace = models.Tool{
+	Type:        "function",
 	Name:        "adjust_pace",
 	Description: "Adjust the pace of the user's plan",
 	Parameters: map[string]models.ToolParam{
@@ -31,7 +41,13 @@ var ToolAdjustPace = models.Tool{
 	},
 }
 
+type adjustPaceArgs struct {
+	Adjustment string `json:"adjustment"`
+	Reason     string `json:"reason"`
+}
+
 var ToolCelebrateWin = models.Tool{
+	Type:        "function",
 	Name:        "celebrate_win",
 	Description: "Celebrate a user achievement",
 	Parameters: map[string]models.ToolParam{
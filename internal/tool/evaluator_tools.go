@@ -3,30 +3,45 @@ package tool
 import "agent-coach/internal/models"
 
 var ToolRatePerformance = models.Tool{
+	Type:        "function",
 	Name:        "rate_performance",
 	Description: "Rate the user's performance on a task",
 	Parameters: map[string]models.ToolParam{
 		"task_id":  {Type: "string", Description: "ID of the task", Required: true},
-		"rating":   {Type: "integer", Description: "Performance rating 1-5", Required: true},
+		"rating":   {Type: "integer", Description: "Performance rating 1-5", Required: true, Minimum: floatPtr(1), Maximum: floatPtr(5)},
 		"feedback": {Type: "string", Description: "Feedback for the user", Required: true},
 	},
 }
 
+type ratePerformanceArgs struct {
+	TaskID   string `json:"task_id"`
+	Rating   int    `json:"rating"`
+	Feedback string `json:"feedback"`
+}
+
 var ToolIdentifyWeakness = models.Tool{
+	Type:        "function",
 	Name:        "identify_weakness",
 	Description: "Identify an area where the user needs improvement",
 	Parameters: map[string]models.ToolParam{
-		"area":       {Type: "string", Description: "The weakness area", Required: true},
+		"area":       {Type: "string", Description: "The weakness area", Required: true, MinLength: intPtr(1)},
 		"evidence":   {Type: "string", Description: "Evidence supporting this observation", Required: true},
 		"suggestion": {Type: "string", Description: "Suggested action to improve", Required: true},
 	},
 }
 
+type identifyWeaknessArgs struct {
+	Area       string `json:"area"`
+	Evidence   string `json:"evidence"`
+	Suggestion string `json:"suggestion"`
+}
+
 var ToolSuggestReview = models.Tool{
+	Type:        "function",
 	Name:        "suggest_review",
 	Description: "Suggest topics or tasks for review",
 	Parameters: map[string]models.ToolParam{
-		"topics": {Type: "array", Description: "Topics to review", Required: true},
+		"topics": {Type: "array", Description: "Topics to review", Required: true, MinItems: intPtr(1), Items: &models.ToolParam{Type: "string"}},
 		"reason": {Type: "string", Description: "Why review is needed", Required: true},
 	},
 }
@@ -3,38 +3,58 @@ package tool
 import "agent-coach/internal/models"
 
 var ToolPresentTask = models.Tool{
+	Type:        "function",
 	Name:        "present_task",
 	Description: "Present a task to the user with context and guidance",
 	Parameters: map[string]models.ToolParam{
 		"task_id":  {Type: "string", Description: "ID of the task to present", Required: true},
 		"approach": {Type: "string", Description: "Suggested approach for the task", Required: false},
-		"tips":     {Type: "array", Description: "List of tips for completing the task", Required: false},
+		"tips":     {Type: "array", Description: "List of tips for completing the task", Required: false, Items: &models.ToolParam{Type: "string"}},
 	},
 }
 
+type presentTaskArgs struct {
+	TaskID   string   `json:"task_id"`
+	Approach string   `json:"approach"`
+	Tips     []string `json:"tips"`
+}
+
 var ToolProvideHint = models.Tool{
+	Type:        "function",
 	Name:        "provide_hint",
 	Description: "Provide a hint to help the user with their current task",
 	Parameters: map[string]models.ToolParam{
-		"hint_level": {Type: "integer", Description: "Hint level 1-3 (1=subtle, 3=explicit)", Required: true},
-		"hint":       {Type: "string", Description: "The hint content", Required: true},
+		"hint_level": {Type: "integer", Description: "Hint level 1-3 (1=subtle, 3=explicit)", Required: true, Minimum: floatPtr(1), Maximum: floatPtr(3)},
+		"hint":       {Type: "string", Description: "The hint content", Required: true, MinLength: intPtr(1)},
 	},
 }
 
 var ToolMarkComplete = models.Tool{
+	Type:        "function",
 	Name:        "mark_complete",
 	Description: "Mark a task as completed",
 	Parameters: map[string]models.ToolParam{
 		"task_id":        {Type: "string", Description: "ID of the task", Required: true},
-		"actual_minutes": {Type: "integer", Description: "Actual time taken in minutes", Required: false},
+		"actual_minutes": {Type: "integer", Description: "Actual time taken in minutes", Required: false, Minimum: floatPtr(1)},
 	},
 }
 
+type markCompleteArgs struct {
+	TaskID        string `json:"task_id"`
+	ActualMinutes *int   `json:"actual_minutes"`
+}
+
 var ToolLogStruggle = models.Tool{
+	Type:        "function",
 	Name:        "log_struggle",
 	Description: "Log that the user struggled with a task",
 	Parameters: map[string]models.ToolParam{
 		"task_id": {Type: "string", Description: "ID of the task", Required: true},
-		"notes":   {Type: "string", Description: "Notes about the struggle", Required: true},
+		"notes":   {Type: "string", Description: "Notes about the struggle", Required: true, MinLength: intPtr(1)},
 	},
 }
+
+type logStruggleArgs struct {
+	TaskID string `json:"task_id"`
+	Notes  string `json:"notes"`
+}
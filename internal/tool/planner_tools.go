@@ -3,41 +3,96 @@ package tool
 import "agent-coach/internal/models"
 
 var ToolCreateMilestone = models.Tool{
+	Type:        "function",
 	Name:        "create_milestone",
 	Description: "Create a milestone (major goal checkpoint) with a target week range",
 	Parameters: map[string]models.ToolParam{
-		"title":       {Type: "string", Description: "Milestone title", Required: true},
+		"title":       {Type: "string", Description: "Milestone title", Required: true, MinLength: intPtr(1)},
 		"description": {Type: "string", Description: "What this milestone covers", Required: true},
-		"week_start":  {Type: "integer", Description: "Starting week number", Required: true},
-		"week_end":    {Type: "integer", Description: "Ending week number", Required: true},
+		"week_start":  {Type: "integer", Description: "Starting week number, counting from 1", Required: true, Minimum: floatPtr(1)},
+		"week_end":    {Type: "integer", Description: "Ending week number, not before week_start", Required: true, Minimum: floatPtr(1)},
 	},
 }
 
+type createMilestoneArgs struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	WeekStart   int    `json:"week_start"`
+	WeekEnd     int    `json:"week_end"`
+}
+
 var ToolCreateTask = models.Tool{
+	Type:        "function",
 	Name:        "create_task",
 	Description: "Create a specific task for the user to complete",
 	Parameters: map[string]models.ToolParam{
-		"title":             {Type: "string", Description: "Task title", Required: true},
+		"title":             {Type: "string", Description: "Task title", Required: true, MinLength: intPtr(1)},
 		"description":       {Type: "string", Description: "Task description", Required: false},
-		"due_date":          {Type: "string", Description: "Due date in YYYY-MM-DD format", Required: false},
-		"estimated_minutes": {Type: "integer", Description: "Estimated time in minutes", Required: false},
-		"difficulty":        {Type: "integer", Description: "Difficulty 1-5", Required: false},
-		"priority":          {Type: "integer", Description: "Priority (higher = more important)", Required: false},
+		"due_date":          {Type: "string", Description: "Due date in YYYY-MM-DD format, a whole day in the user's time zone; today or later", Required: false, Format: "date"},
+		"due_time":          {Type: "string", Description: "Optional local time HH:MM on due_date, for tasks due at an exact time", Required: false},
+		"estimated_minutes": {Type: "integer", Description: "Estimated time in minutes", Required: false, Minimum: floatPtr(1)},
+		"difficulty":        {Type: "integer", Description: "Difficulty 1-5", Required: false, Minimum: floatPtr(1), Maximum: floatPtr(5)},
+		"priority":          {Type: "integer", Description: "Priority (higher = more important)", Required: false, Minimum: floatPtr(0)},
+		"milestone_id":      {Type: "string", Description: "ID of the milestone this task belongs to, as returned by create_milestone", Required: false},
+		"allow_overbooking": {Type: "boolean", Description: "Keep due_date even if the day is over the user's available time; only when the user asks for it", Required: false},
+		"depends_on":        {Type: "array", Description: "IDs of tasks that must be done before this one can start, as returned by create_task", Required: false, Items: &models.ToolParam{Type: "string"}},
 	},
 }
 
+type createTaskArgs struct {
+	Title            string   `json:"title"`
+	Description      string   `json:"description"`
+	DueDate          string   `json:"due_date"`
+	DueTime          string   `json:"due_time"`
+	EstimatedMinutes *int     `json:"estimated_minutes"`
+	Difficulty       *int     `json:"difficulty"`
+	Priority         int      `json:"priority"`
+	MilestoneID      string   `json:"milestone_id"`
+	AllowOverbooking bool     `json:"allow_overbooking"`
+	DependsOn        []string `json:"depends_on"`
+}
+
 var ToolSuggestResources = models.Tool{
+	Type:        "function",
 	Name:        "suggest_resources",
-	Description: "Suggest learning resources to the user",
+	Description: "Suggest learning resources to the user and save them to the goal, optionally attached to a milestone or task",
 	Parameters: map[string]models.ToolParam{
-		"resources": {Type: "array", Description: "List of resource objects with title, type, url", Required: true},
+		"resources": {
+			Type:        "array",
+			Description: "Resources to suggest",
+			Required:    true,
+			MinItems:    intPtr(1),
+			Items: &models.ToolParam{
+				Type: "object",
+				Properties: map[string]models.ToolParam{
+					"title": {Type: "string", Description: "Resource title", Required: true, MinLength: intPtr(1)},
+					"type":  {Type: "string", Description: "Kind of resource: article, video, book, course, docs..."},
+					"url":   {Type: "string", Description: "Link to the resource", Format: "uri"},
+					"notes": {Type: "string", Description: "Why this resource helps or which part to focus on"},
+				},
+			},
+		},
+		"milestone_id": {Type: "string", Description: "ID of the milestone the resources support", Required: false},
+		"task_id":      {Type: "string", Description: "ID of the task the resources support", Required: false},
 	},
 }
 
+type suggestResourcesArgs struct {
+	Resources []struct {
+		Title string `json:"title"`
+		Type  string `json:"type"`
+		URL   string `json:"url"`
+		Notes string `json:"notes"`
+	} `json:"resources"`
+	MilestoneID string `json:"milestone_id"`
+	TaskID      string `json:"task_id"`
+}
+
 var ToolAskClarifyingQuestion = models.Tool{
+	Type:        "function",
 	Name:        "ask_clarifying_question",
 	Description: "Ask the user a clarifying question to better understand their needs",
 	Parameters: map[string]models.ToolParam{
-		"question": {Type: "string", Description: "The question to ask", Required: true},
+		"question": {Type: "string", Description: "The question to ask", Required: true, MinLength: intPtr(1)},
 	},
 }
@@ -0,0 +1,170 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"agent-coach/internal/models"
+	"agent-coach/internal/storage"
+)
+
+// newTestGoal returns an in-memory database with every migration applied and
+// an agent context for a goal stored in it.
+func newTestGoal(t *testing.T) (*storage.DB, *models.AgentContext) {
+	t.Helper()
+
+	db, err := storage.Open(":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Migrate(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	goal := &models.Goal{Title: "Learn Go", Status: models.GoalStatusActive}
+	if err := storage.NewGoalRepository(db).Create(context.Background(), goal); err != nil {
+		t.Fatalf("create goal: %v", err)
+	}
+	return db, &models.AgentContext{Goal: goal, Location: time.UTC}
+}
+
+func TestCreateTaskRespectsAvailability(t *testing.T) {
+	ctx := context.Background()
+	db, agentCtx := newTestGoal(t)
+
+	today := models.CalendarDate(agentCtx.Now())
+	date := func(days int) string { return today.AddDate(0, 0, days).Format("2006-01-02") }
+	availability := models.UniformAvailability(60)
+	availability.Blackouts = []*models.BlackoutDate{{Day: date(2)}}
+	if err := storage.NewAvailabilityRepository(db).Save(ctx, availability); err != nil {
+		t.Fatalf("save availability: %v", err)
+	}
+
+	executor := NewToolExecutor(db)
+	create := func(args map[string]any) (map[string]any, error) {
+		return executor.ExecuteTool(ctx, "create_task", args, agentCtx)
+	}
+
+	if _, err := create(map[string]any{"title": "Tour of Go", "due_date": date(1), "estimated_minutes": 45.0}); err != nil {
+		t.Fatalf("create first task: %v", err)
+	}
+
+	_, err := create(map[string]any{"title": "Effective Go", "due_date": date(1), "estimated_minutes": 30.0})
+	var overbooked *OverbookedError
+	if !errors.As(err, &overbooked) {
+		t.Fatalf("error = %v, want OverbookedError", err)
+	}
+	if overbooked.Booked != 45 || overbooked.Capacity != 60 || overbooked.Suggested != date(3) {
+		t.Errorf("overbooked = %+v, want 45 of 60 booked and %s suggested", overbooked, date(3))
+	}
+	if result := ErrorResult(err); result["suggested_date"] != date(3) {
+		t.Errorf("error result = %v", result)
+	}
+
+	result, err := create(map[string]any{"title": "Effective Go", "due_date": date(1), "estimated_minutes": 30.0, "allow_overbooking": true})
+	if err != nil || result["warning"] == nil {
+		t.Errorf("overbooking allowed = %v, %v; want a warning", result, err)
+	}
+
+	// With today full, tomorrow over capacity and the day after blacked out,
+	// a task without a due date lands three days out.
+	if _, err := create(map[string]any{"title": "Filler", "due_date": date(0), "estimated_minutes": 60.0}); err != nil {
+		t.Fatalf("fill today: %v", err)
+	}
+	result, err = create(map[string]any{"title": "Go by Example", "estimated_minutes": 30.0})
+	if err != nil || result["due"] != date(3) {
+		t.Errorf("unscheduled task = %v, %v; want due %s", result, err, date(3))
+	}
+}
+
+func TestCreateTaskWithDependencies(t *testing.T) {
+	ctx := context.Background()
+	db, agentCtx := newTestGoal(t)
+	executor := NewToolExecutor(db)
+	create := func(args map[string]any) (map[string]any, error) {
+		return executor.ExecuteTool(ctx, "create_task", args, agentCtx)
+	}
+
+	today := models.CalendarDate(agentCtx.Now())
+	date := func(days int) string { return today.AddDate(0, 0, days).Format("2006-01-02") }
+
+	basics, err := create(map[string]any{"title": "Basics", "due_date": date(3)})
+	if err != nil {
+		t.Fatalf("create basics: %v", err)
+	}
+	basicsID := basics["task_id"].(string)
+
+	var validationErr *ValidationError
+	_, err = create(map[string]any{"title": "Generics", "due_date": date(1), "depends_on": []any{basicsID}})
+	if !errors.As(err, &validationErr) || validationErr.Errors[0].Field != "due_date" {
+		t.Errorf("due before prerequisite: error = %v, want due_date ValidationError", err)
+	}
+	_, err = create(map[string]any{"title": "Generics", "depends_on": []any{"missing"}})
+	if !errors.As(err, &validationErr) || validationErr.Errors[0].Field != "depends_on" {
+		t.Errorf("unknown prerequisite: error = %v, want depends_on ValidationError", err)
+	}
+
+	generics, err := create(map[string]any{"title": "Generics", "depends_on": []any{basicsID}})
+	if err != nil {
+		t.Fatalf("create generics: %v", err)
+	}
+	if generics["blocked"] != true || generics["due"] != date(3) {
+		t.Errorf("generics = %v, want blocked and scheduled on %s", generics, date(3))
+	}
+
+	presented, err := executor.ExecuteTool(ctx, "present_task", map[string]any{"task_id": generics["task_id"]}, agentCtx)
+	if err != nil {
+		t.Fatalf("present generics: %v", err)
+	}
+	if blockedBy, _ := presented["blocked_by"].([]map[string]any); len(blockedBy) != 1 || blockedBy[0]["task_id"] != basicsID {
+		t.Errorf("blocked_by = %v, want basics", presented["blocked_by"])
+	}
+}
+
+func TestCreateTaskRefusesDaysItCannotSchedule(t *testing.T) {
+	ctx := context.Background()
+	db, agentCtx := newTestGoal(t)
+	executor := NewToolExecutor(db)
+	create := func(args map[string]any) (map[string]any, error) {
+		return executor.ExecuteTool(ctx, "create_task", args, agentCtx)
+	}
+
+	today := models.CalendarDate(agentCtx.Now())
+	date := func(days int) string { return today.AddDate(0, 0, days).Format("2006-01-02") }
+
+	var validationErr *ValidationError
+	_, err := create(map[string]any{"title": "Yesterday", "due_date": date(-1)})
+	if !errors.As(err, &validationErr) || validationErr.Errors[0].Field != "due_date" {
+		t.Errorf("past due date: error = %v, want due_date ValidationError", err)
+	}
+
+	// The prerequisite's day lies past the horizon from today, so its
+	// booking must still be seen when placing the dependent after it.
+	capstone, err := create(map[string]any{"title": "Capstone", "due_date": date(40), "estimated_minutes": 60.0})
+	if err != nil {
+		t.Fatalf("create capstone: %v", err)
+	}
+	retro, err := create(map[string]any{"title": "Retro", "estimated_minutes": 30.0, "depends_on": []any{capstone["task_id"]}})
+	if err != nil || retro["due"] != date(41) {
+		t.Errorf("dependent after a full day = %v, %v; want due %s", retro, err, date(41))
+	}
+
+	if err := storage.NewAvailabilityRepository(db).Save(ctx, models.UniformAvailability(0)); err != nil {
+		t.Fatalf("save availability: %v", err)
+	}
+	_, err = create(map[string]any{"title": "Nowhere", "estimated_minutes": 30.0})
+	var noRoom *NoRoomError
+	if !errors.As(err, &noRoom) || noRoom.From != date(0) {
+		t.Fatalf("full schedule: error = %v, want NoRoomError from %s", err, date(0))
+	}
+	if result := ErrorResult(err); result["error"] != "no room" {
+		t.Errorf("error result = %v", result)
+	}
+	tasks, _ := storage.NewTaskRepository(db).GetByGoalID(ctx, agentCtx.Goal.ID)
+	if len(tasks) != 2 {
+		t.Errorf("saved %d tasks, want the unscheduled one left out", len(tasks))
+	}
+}
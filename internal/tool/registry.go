@@ -0,0 +1,115 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"agent-coach/internal/models"
+)
+
+// ErrUnknownTool is returned for a tool name that was never registered.
+var ErrUnknownTool = errors.New("unknown tool")
+
+// Permission is what running a tool may do to stored data.
+type Permission string
+
+const (
+	// PermissionRead tools only read data or shape the reply.
+	PermissionRead Permission = "read"
+	// PermissionWrite tools create or modify stored data.
+	PermissionWrite Permission = "write"
+)
+
+// Handler runs a tool with arguments already validated against its schema.
+type Handler func(ctx context.Context, args map[string]any, agentCtx *models.AgentContext) (map[string]any, error)
+
+type Registration struct {
+	Definition models.Tool
+	Permission Permission
+	Handler    Handler
+}
+
+// Registry holds the tools available to agents.
+type Registry struct {
+	mu    sync.RWMutex
+	tools map[string]Registration
+}
+
+func NewRegistry() *Registry {
+	return &Registry{tools: make(map[string]Registration)}
+}
+
+// Register adds a tool. Names must be unique.
+func (r *Registry) Register(definition models.Tool, permission Permission, handler Handler) error {
+	if definition.Name == "" {
+		return errors.New("tool definition has no name")
+	}
+	if handler == nil {
+		return fmt.Errorf("tool %s has no handler", definition.Name)
+	}
+	if definition.Type == "" {
+		definition.Type = "function"
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.tools[definition.Name]; exists {
+		return fmt.Errorf("tool %s is already registered", definition.Name)
+	}
+	r.tools[definition.Name] = Registration{Definition: definition, Permission: permission, Handler: handler}
+	return nil
+}
+
+func (r *Registry) Lookup(name string) (Registration, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	registration, ok := r.tools[name]
+	return registration, ok
+}
+
+// Definitions returns the definitions of the named tools, in order.
+func (r *Registry) Definitions(names ...string) ([]models.Tool, error) {
+	tools := make([]models.Tool, 0, len(names))
+	for _, name := range names {
+		registration, ok := r.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownTool, name)
+		}
+		tools = append(tools, registration.Definition)
+	}
+	return tools, nil
+}
+
+// Names lists every registered tool, sorted.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.tools))
+	for name := range r.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Mutates reports whether the named tool may change stored data.
+func (r *Registry) Mutates(name string) bool {
+	registration, ok := r.Lookup(name)
+	return ok && registration.Permission == PermissionWrite
+}
+
+// Execute validates args against the tool's schema and runs its handler.
+// Invalid arguments yield a *ValidationError.
+func (r *Registry) Execute(ctx context.Context, name string, args map[string]any, agentCtx *models.AgentContext) (map[string]any, error) {
+	registration, ok := r.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTool, name)
+	}
+	if err := ValidateArguments(registration.Definition, args); err != nil {
+		return nil, err
+	}
+	return registration.Handler(ctx, args, agentCtx)
+}
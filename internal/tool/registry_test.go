@@ -0,0 +1,58 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"agent-coach/internal/models"
+)
+
+func TestRegistryRunsCustomTools(t *testing.T) {
+	executor := NewToolExecutor(nil)
+
+	echoTopic := models.Tool{
+		Name:        "pick_topic",
+		Description: "Pick a study topic",
+		Parameters: map[string]models.ToolParam{
+			"topic": {Type: "string", Description: "Topic", Required: true},
+		},
+	}
+	err := executor.Register(echoTopic, PermissionRead, func(ctx context.Context, args map[string]any, agentCtx *models.AgentContext) (map[string]any, error) {
+		return map[string]any{"picked": args["topic"]}, nil
+	})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	result, err := executor.ExecuteTool(context.Background(), "pick_topic", map[string]any{"topic": "generics"}, &models.AgentContext{})
+	if err != nil || result["picked"] != "generics" {
+		t.Errorf("ExecuteTool = %v, %v", result, err)
+	}
+	if executor.Mutates("pick_topic") {
+		t.Error("read tool reported as mutating")
+	}
+	if !executor.Mutates("create_task") {
+		t.Error("create_task not reported as mutating")
+	}
+
+	definitions, err := executor.Definitions("pick_topic", "create_task")
+	if err != nil || len(definitions) != 2 || definitions[0].Type != "function" {
+		t.Errorf("Definitions = %+v, %v", definitions, err)
+	}
+
+	if err := executor.Register(echoTopic, PermissionRead, echoArguments); err == nil {
+		t.Error("registering a duplicate name succeeded")
+	}
+}
+
+func TestRegistryRejectsUnknownTools(t *testing.T) {
+	executor := NewToolExecutor(nil)
+
+	if _, err := executor.ExecuteTool(context.Background(), "delete_everything", nil, &models.AgentContext{}); !errors.Is(err, ErrUnknownTool) {
+		t.Errorf("ExecuteTool error = %v, want ErrUnknownTool", err)
+	}
+	if _, err := executor.Definitions("create_task", "delete_everything"); !errors.Is(err, ErrUnknownTool) {
+		t.Errorf("Definitions error = %v, want ErrUnknownTool", err)
+	}
+}
@@ -0,0 +1,199 @@
+package tool
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"agent-coach/internal/models"
+)
+
+// FieldError describes one argument that does not match the tool's schema.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned by ExecuteTool when the model's arguments do
+// not match the tool's schema. It is sent back to the model field by field so
+// it can correct the call.
+type ValidationError struct {
+	Tool   string
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Errors))
+	for i, fieldErr := range e.Errors {
+		parts[i] = fieldErr.Field + ": " + fieldErr.Message
+	}
+	return fmt.Sprintf("invalid arguments for %s: %s", e.Tool, strings.Join(parts, "; "))
+}
+
+// ValidateArguments checks args against the tool's parameter schema. Missing
+// optional arguments and null values are accepted; unknown arguments are
+// ignored.
+func ValidateArguments(tool models.Tool, args map[string]any) error {
+	var errs []FieldError
+	validateObject("", tool.Parameters, args, &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Tool: tool.Name, Errors: errs}
+	}
+	return nil
+}
+
+func validateObject(path string, properties map[string]models.ToolParam, fields map[string]any, errs *[]FieldError) {
+	for _, name := range sortedNames(properties) {
+		param := properties[name]
+		value, ok := fields[name]
+		if !ok || value == nil {
+			if param.Required {
+				*errs = append(*errs, FieldError{Field: join(path, name), Message: "is required"})
+			}
+			continue
+		}
+		validateValue(join(path, name), param, value, errs)
+	}
+}
+
+func validateValue(path string, param models.ToolParam, value any, errs *[]FieldError) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, FieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch param.Type {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if param.MinLength != nil && len(strings.TrimSpace(s)) < *param.MinLength {
+			if *param.MinLength == 1 {
+				fail("must not be empty")
+			} else {
+				fail("must be at least %d characters", *param.MinLength)
+			}
+		}
+		if len(param.Enum) > 0 && !contains(param.Enum, s) {
+			fail("must be one of %s", strings.Join(param.Enum, ", "))
+		}
+		if msg := checkFormat(param.Format, s); msg != "" {
+			fail("%s", msg)
+		}
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			fail("must be a number")
+			return
+		}
+		if param.Type == "integer" && n != math.Trunc(n) {
+			fail("must be a whole number")
+			return
+		}
+		if param.Minimum != nil && n < *param.Minimum {
+			fail("must be at least %v", *param.Minimum)
+		}
+		if param.Maximum != nil && n > *param.Maximum {
+			fail("must be at most %v", *param.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be true or false")
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		if param.MinItems != nil && len(items) < *param.MinItems {
+			fail("must contain at least %d item(s)", *param.MinItems)
+		}
+		if param.MaxItems != nil && len(items) > *param.MaxItems {
+			fail("must contain at most %d item(s)", *param.MaxItems)
+		}
+		if param.Items != nil {
+			for i, item := range items {
+				itemPath := fmt.Sprintf("%s[%d]", path, i)
+				if item == nil {
+					*errs = append(*errs, FieldError{Field: itemPath, Message: "must not be null"})
+					continue
+				}
+				validateValue(itemPath, *param.Items, item, errs)
+			}
+		}
+	case "object":
+		fields, ok := value.(map[string]any)
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		validateObject(path, param.Properties, fields, errs)
+	}
+}
+
+func checkFormat(format, value string) string {
+	switch format {
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "must be a date in YYYY-MM-DD format"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return "must be an RFC 3339 date-time, e.g. 2026-01-15T09:00:00Z"
+		}
+	case "uri":
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			return "must be an absolute URL"
+		}
+	}
+	return ""
+}
+
+// decodeArguments copies validated arguments into a typed struct using its
+// json tags.
+func decodeArguments(args map[string]any, dst any) error {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedNames(properties map[string]models.ToolParam) []string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func intPtr(v int) *int {
+	return &v
+}
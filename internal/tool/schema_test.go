@@ -0,0 +1,119 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"agent-coach/internal/models"
+)
+
+func TestValidateArguments(t *testing.T) {
+	tests := []struct {
+		name string
+		tool models.Tool
+		args map[string]any
+		want []FieldError
+	}{
+		{
+			name: "valid task",
+			tool: ToolCreateTask,
+			args: map[string]any{"title": "Read chapter 1", "due_date": "2026-01-20", "estimated_minutes": 30.0, "difficulty": nil},
+		},
+		{
+			name: "missing and mistyped fields",
+			tool: ToolCreateTask,
+			args: map[string]any{"estimated_minutes": "thirty", "due_date": "next friday", "difficulty": 7.0},
+			want: []FieldError{
+				{Field: "difficulty", Message: "must be at most 5"},
+				{Field: "due_date", Message: "must be a date in YYYY-MM-DD format"},
+				{Field: "estimated_minutes", Message: "must be a number"},
+				{Field: "title", Message: "is required"},
+			},
+		},
+		{
+			name: "fractional integer",
+			tool: ToolRatePerformance,
+			args: map[string]any{"task_id": "t1", "rating": 4.5, "feedback": "Good"},
+			want: []FieldError{{Field: "rating", Message: "must be a whole number"}},
+		},
+		{
+			name: "enum",
+			tool: ToolAdjustPace,
+			args: map[string]any{"adjustment": "much slower", "reason": "tired"},
+			want: []FieldError{{Field: "adjustment", Message: "must be one of slower, faster, maintain"}},
+		},
+		{
+			name: "nested array items",
+			tool: ToolSuggestResources,
+			args: map[string]any{"resources": []any{
+				map[string]any{"title": "Tour of Go", "url": "https://go.dev/tour"},
+				map[string]any{"url": "go.dev"},
+				"Effective Go",
+			}},
+			want: []FieldError{
+				{Field: "resources[1].title", Message: "is required"},
+				{Field: "resources[1].url", Message: "must be an absolute URL"},
+				{Field: "resources[2]", Message: "must be an object"},
+			},
+		},
+		{
+			name: "empty array",
+			tool: ToolSuggestResources,
+			args: map[string]any{"resources": []any{}},
+			want: []FieldError{{Field: "resources", Message: "must contain at least 1 item(s)"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateArguments(tt.tool, tt.args)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("ValidateArguments: %v", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("error = %v, want *ValidationError", err)
+			}
+			if !reflect.DeepEqual(validationErr.Errors, tt.want) {
+				t.Errorf("errors = %+v, want %+v", validationErr.Errors, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteToolRejectsInvalidArgumentsBeforeRunning(t *testing.T) {
+	// The executor has no database: reaching the handler would panic.
+	executor := NewToolExecutor(nil)
+	_, err := executor.ExecuteTool(context.Background(), "mark_complete", map[string]any{"actual_minutes": 20.0}, &models.AgentContext{})
+
+	result := ErrorResult(err)
+	details, ok := result["details"].([]FieldError)
+	if !ok || len(details) != 1 || details[0].Field != "task_id" {
+		t.Errorf("ErrorResult = %+v, want a task_id detail", result)
+	}
+}
+
+func TestJSONSchemaDescribesNestedParameters(t *testing.T) {
+	schema := ToolSuggestResources.JSONSchema()
+
+	resources := schema["properties"].(map[string]any)["resources"].(map[string]any)
+	if resources["minItems"] != 1 {
+		t.Errorf("minItems = %v, want 1", resources["minItems"])
+	}
+	items := resources["items"].(map[string]any)
+	if items["type"] != "object" || !reflect.DeepEqual(items["required"], []string{"title"}) {
+		t.Errorf("items schema = %+v", items)
+	}
+	url := items["properties"].(map[string]any)["url"].(map[string]any)
+	if url["format"] != "uri" {
+		t.Errorf("url schema = %+v", url)
+	}
+	if !reflect.DeepEqual(schema["required"], []string{"resources"}) {
+		t.Errorf("required = %v", schema["required"])
+	}
+}
@@ -2,82 +2,546 @@ package tool
 
 import (
 	"agent-coach/internal/models"
+	"agent-coach/internal/schedule"
 	"agent-coach/internal/storage"
 	"context"
+	"errors"
+	"fmt"
+	"math"
 	"time"
 )
 
+var errNoGoal = errors.New("no active goal; create a goal first")
+
 type ToolExecutor struct {
-	taskRepo *storage.TaskRepository
-	goalRepo *storage.GoalRepository
+	taskRepo      *storage.TaskRepository
+	goalRepo      *storage.GoalRepository
+	evalRepo      *storage.EvaluationRepository
+	checkInRepo   *storage.CheckInRepository
+	milestoneRepo *storage.MilestoneRepository
+	resourceRepo  *storage.ResourceRepository
+	settingsRepo  *storage.SettingsRepository
+	availRepo     *storage.AvailabilityRepository
+	depRepo       *storage.DependencyRepository
+	registry      *Registry
 }
 
 func NewToolExecutor(db *storage.DB) *ToolExecutor {
-	return &ToolExecutor{
-		taskRepo: storage.NewTaskRepository(db),
-		goalRepo: storage.NewGoalRepository(db),
+	e := &ToolExecutor{
+		taskRepo:      storage.NewTaskRepository(db),
+		goalRepo:      storage.NewGoalRepository(db),
+		evalRepo:      storage.NewEvaluationRepository(db),
+		checkInRepo:   storage.NewCheckInRepository(db),
+		milestoneRepo: storage.NewMilestoneRepository(db),
+		resourceRepo:  storage.NewResourceRepository(db),
+		settingsRepo:  storage.NewSettingsRepository(db),
+		availRepo:     storage.NewAvailabilityRepository(db),
+		depRepo:       storage.NewDependencyRepository(db),
+		registry:      NewRegistry(),
+	}
+	e.registerBuiltins()
+	return e
+}
+
+func (e *ToolExecutor) registerBuiltins() {
+	builtins := []Registration{
+		// Planner
+		{ToolCreateMilestone, PermissionWrite, e.executeCreateMilestone},
+		{ToolCreateTask, PermissionWrite, e.executeCreateTask},
+		{ToolSuggestResources, PermissionWrite, e.executeSuggestResources},
+		{ToolAskClarifyingQuestion, PermissionRead, echoArguments},
+		// Executor
+		{ToolPresentTask, PermissionRead, e.executePresentTask},
+		{ToolProvideHint, PermissionRead, echoArguments},
+		{ToolMarkComplete, PermissionWrite, e.executeMarkComplete},
+		{ToolLogStruggle, PermissionWrite, e.executeLogStruggle},
+		// Evaluator
+		{ToolRatePerformance, PermissionWrite, e.executeRatePerformance},
+		{ToolIdentifyWeakness, PermissionWrite, e.executeIdentifyWeakness},
+		{ToolSuggestReview, PermissionRead, echoArguments},
+		// Accountability
+		{ToolSendCheckIn, PermissionRead, echoArguments},
+		{ToolRecordCheckIn, PermissionWrite, e.executeRecordCheckIn},
+		{ToolAdjustPace, PermissionWrite, e.executeAdjustPace},
+		{ToolCelebrateWin, PermissionRead, echoArguments},
 	}
+	for _, builtin := range builtins {
+		if err := e.registry.Register(builtin.Definition, builtin.Permission, builtin.Handler); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Register adds a custom tool that agents can then select by name.
+func (e *ToolExecutor) Register(definition models.Tool, permission Permission, handler Handler) error {
+	return e.registry.Register(definition, permission, handler)
 }
 
+// Definitions returns the definitions of the named tools; an unknown name is
+// an error.
+func (e *ToolExecutor) Definitions(names ...string) ([]models.Tool, error) {
+	return e.registry.Definitions(names...)
+}
+
+// Mutates reports whether running the named tool may change stored data.
+func (e *ToolExecutor) Mutates(toolName string) bool {
+	return e.registry.Mutates(toolName)
+}
+
+// ExecuteTool validates args against the tool's schema and runs it. Invalid
+// arguments yield a *ValidationError and unknown tools ErrUnknownTool.
 func (e *ToolExecutor) ExecuteTool(ctx context.Context, toolName string, args map[string]any, agentCtx *models.AgentContext) (map[string]any, error) {
-	switch toolName {
-	case "create_task":
-		return e.executeCreateTask(ctx, args, agentCtx)
-	case "mark_complete":
-		return e.executeMarkComplete(ctx, args)
-	case "log_struggle":
-		return e.executeLogStruggle(ctx, args)
-	default:
-		return args, nil
+	return e.registry.Execute(ctx, toolName, args, agentCtx)
+}
+
+// echoArguments handles tools that only shape the reply, such as asking a
+// question or giving a hint: the model's own arguments are the result.
+func echoArguments(ctx context.Context, args map[string]any, agentCtx *models.AgentContext) (map[string]any, error) {
+	return args, nil
+}
+
+// OverbookedError is returned by create_task when the requested due day has
+// no room left in the user's availability. Suggested is the first later day
+// with room, if any within the scheduling horizon.
+type OverbookedError struct {
+	Day         string
+	TaskMinutes int
+	Booked      int
+	Capacity    int
+	Suggested   string
+}
+
+func (e *OverbookedError) Error() string {
+	return fmt.Sprintf("%s is overbooked: %d of %d minutes already planned, task needs %d", e.Day, e.Booked, e.Capacity, e.TaskMinutes)
+}
+
+// NoRoomError is returned by create_task when a task without a due date
+// fits on no day of the scheduling horizon starting at From.
+type NoRoomError struct {
+	From        string
+	TaskMinutes int
+	Days        int
+}
+
+func (e *NoRoomError) Error() string {
+	return fmt.Sprintf("no day in the %d days from %s has room for a %d-minute task", e.Days, e.From, e.TaskMinutes)
+}
+
+// ErrorResult is the tool result reported to the model when a call fails.
+// Validation errors are listed per field so the model can fix its call,
+// overbooked days come with a suggested alternative, and a full schedule is
+// reported with the days that were searched.
+func ErrorResult(err error) map[string]any {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return map[string]any{
+			"error":   "invalid arguments",
+			"details": validationErr.Errors,
+			"hint":    "Fix the listed arguments and call " + validationErr.Tool + " again.",
+		}
 	}
+	var overbookedErr *OverbookedError
+	if errors.As(err, &overbookedErr) {
+		result := map[string]any{
+			"error":            "overbooked",
+			"requested_date":   overbookedErr.Day,
+			"task_minutes":     overbookedErr.TaskMinutes,
+			"booked_minutes":   overbookedErr.Booked,
+			"capacity_minutes": overbookedErr.Capacity,
+			"hint":             "Pick another day, or set allow_overbooking only if the user explicitly wants this day.",
+		}
+		if overbookedErr.Suggested != "" {
+			result["suggested_date"] = overbookedErr.Suggested
+			result["hint"] = "Call create_task again with due_date " + overbookedErr.Suggested + ", or set allow_overbooking only if the user explicitly wants this day."
+		}
+		return result
+	}
+	var noRoomErr *NoRoomError
+	if errors.As(err, &noRoomErr) {
+		return map[string]any{
+			"error":        "no room",
+			"from_date":    noRoomErr.From,
+			"days_checked": noRoomErr.Days,
+			"task_minutes": noRoomErr.TaskMinutes,
+			"hint":         "Tell the user their schedule is full. Split the task into smaller ones, or give it a due_date and set allow_overbooking if the user agrees.",
+		}
+	}
+	return map[string]any{"error": err.Error()}
+}
+
+// goalTask loads a task the model referred to, refusing tasks of other goals
+// so a stale or invented ID cannot touch another goal's plan.
+func (e *ToolExecutor) goalTask(ctx context.Context, taskID string, agentCtx *models.AgentContext) (*models.Task, error) {
+	if agentCtx.Goal == nil {
+		return nil, errNoGoal
+	}
+	task, err := e.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil || task.GoalID != agentCtx.Goal.ID {
+		return nil, fmt.Errorf("task %s not found for this goal", taskID)
+	}
+	return task, nil
+}
+
+func (e *ToolExecutor) executeCreateMilestone(ctx context.Context, args map[string]any, agentCtx *models.AgentContext) (map[string]any, error) {
+	if agentCtx.Goal == nil {
+		return nil, errNoGoal
+	}
+
+	var input createMilestoneArgs
+	if err := decodeArguments(args, &input); err != nil {
+		return nil, err
+	}
+	if input.WeekEnd < input.WeekStart {
+		return nil, &ValidationError{Tool: ToolCreateMilestone.Name, Errors: []FieldError{
+			{Field: "week_end", Message: fmt.Sprintf("must not be before week_start (%d)", input.WeekStart)},
+		}}
+	}
+
+	milestone := &models.Milestone{
+		GoalID:      agentCtx.Goal.ID,
+		Title:       input.Title,
+		Description: input.Description,
+		WeekStart:   input.WeekStart,
+		WeekEnd:     input.WeekEnd,
+		Status:      models.MilestoneStatusPending,
+	}
+
+	if err := e.milestoneRepo.Create(ctx, milestone); err != nil {
+		return nil, err
+	}
+	agentCtx.Milestones = append(agentCtx.Milestones, milestone)
+
+	return map[string]any{
+		"milestone_id": milestone.ID,
+		"message":      "Milestone created successfully; pass milestone_id to create_task to link tasks to it",
+	}, nil
 }
 
 func (e *ToolExecutor) executeCreateTask(ctx context.Context, args map[string]any, agentCtx *models.AgentContext) (map[string]any, error) {
-	task := &models.Task{
-		GoalID: agentCtx.Goal.ID,
-		Title:  args["title"].(string),
-		Status: models.TaskStatusPending,
+	if agentCtx.Goal == nil {
+		return nil, errNoGoal
 	}
 
-	if desc, ok := args["description"].(string); ok {
-		task.Description = desc
+	var input createTaskArgs
+	if err := decodeArguments(args, &input); err != nil {
+		return nil, err
 	}
-	if dueStr, ok := args["due_date"].(string); ok {
-		if due, err := time.Parse("2006-01-02", dueStr); err == nil {
-			task.DueDate = &due
+
+	task := &models.Task{
+		GoalID:           agentCtx.Goal.ID,
+		Title:            input.Title,
+		Description:      input.Description,
+		Status:           models.TaskStatusPending,
+		Priority:         input.Priority,
+		EstimatedMinutes: input.EstimatedMinutes,
+		DifficultyRating: input.Difficulty,
+	}
+	due, kind, err := parseDue(input.DueDate, input.DueTime, agentCtx.TimeZone())
+	if err != nil {
+		return nil, err
+	}
+	task.DueDate, task.DueKind = due, kind
+	if input.MilestoneID != "" {
+		milestone, err := e.milestoneRepo.GetByID(ctx, input.MilestoneID)
+		if err != nil {
+			return nil, err
+		}
+		if milestone == nil || milestone.GoalID != task.GoalID {
+			return nil, fmt.Errorf("milestone %s not found for this goal", input.MilestoneID)
 		}
+		task.MilestoneID = &input.MilestoneID
 	}
-	if mins, ok := args["estimated_minutes"].(float64); ok {
-		m := int(mins)
-		task.EstimatedMinutes = &m
+	prerequisites, err := e.loadPrerequisites(ctx, task, input.DependsOn, agentCtx.TimeZone())
+	if err != nil {
+		return nil, err
 	}
-	if diff, ok := args["difficulty"].(float64); ok {
-		d := int(diff)
-		task.DifficultyRating = &d
+	var earliest time.Time
+	for _, prerequisite := range prerequisites {
+		if day, ok := prerequisite.DueDay(agentCtx.TimeZone()); ok && day.After(earliest) {
+			earliest = day
+		}
 	}
-	if prio, ok := args["priority"].(float64); ok {
-		task.Priority = int(prio)
+
+	placement, err := e.scheduleTask(ctx, task, earliest, input.AllowOverbooking, agentCtx)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := e.taskRepo.Create(ctx, task); err != nil {
+	if err := e.taskRepo.Create(ctx, task, input.DependsOn...); err != nil {
 		return nil, err
 	}
 
-	return map[string]any{
+	result := map[string]any{
 		"task_id": task.ID,
 		"message": "Task created successfully",
+	}
+	if task.DueDate != nil {
+		result["due"] = task.FormatDue(agentCtx.TimeZone())
+	}
+	result["day_load_minutes"] = placement.load
+	result["day_capacity_minutes"] = placement.capacity
+	if placement.assigned {
+		result["message"] = "Task created and scheduled on the first day with room"
+	}
+	if placement.load > placement.capacity {
+		result["warning"] = "This day is over the user's available time"
+	}
+	if len(prerequisites) > 0 {
+		result["depends_on"] = input.DependsOn
+		blocked := false
+		for _, prerequisite := range prerequisites {
+			blocked = blocked || prerequisite.IsOpen()
+		}
+		result["blocked"] = blocked
+	}
+	return result, nil
+}
+
+// loadPrerequisites looks up the tasks a new task depends on. They must
+// belong to the task's goal and be due no later than the task's own due day.
+func (e *ToolExecutor) loadPrerequisites(ctx context.Context, task *models.Task, ids []string, loc *time.Location) ([]*models.Task, error) {
+	due, hasDue := task.DueDay(loc)
+	var prerequisites []*models.Task
+	var errs []FieldError
+	for _, id := range ids {
+		prerequisite, err := e.taskRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if prerequisite == nil || prerequisite.GoalID != task.GoalID {
+			errs = append(errs, FieldError{Field: "depends_on", Message: fmt.Sprintf("task %s not found for this goal", id)})
+			continue
+		}
+		if day, ok := prerequisite.DueDay(loc); ok && hasDue && day.After(due) {
+			errs = append(errs, FieldError{Field: "due_date", Message: fmt.Sprintf("is before prerequisite %q, due %s", prerequisite.Title, prerequisite.FormatDue(loc))})
+		}
+		prerequisites = append(prerequisites, prerequisite)
+	}
+	if len(errs) > 0 {
+		return nil, &ValidationError{Tool: ToolCreateTask.Name, Errors: errs}
+	}
+	return prerequisites, nil
+}
+
+// placement is where scheduleTask put a task and that day's load with it.
+type placement struct {
+	assigned bool
+	load     int
+	capacity int
+}
+
+// scheduleTask checks the task's due day against the user's availability,
+// or gives it the first day with room, not before earliest, when it has no
+// due date. A requested day without room is refused with an OverbookedError
+// unless allowOverbooking is set, a day before today with a ValidationError,
+// and a task that fits nowhere with a NoRoomError.
+func (e *ToolExecutor) scheduleTask(ctx context.Context, task *models.Task, earliest time.Time, allowOverbooking bool, agentCtx *models.AgentContext) (*placement, error) {
+	loc := agentCtx.TimeZone()
+	today := models.CalendarDate(agentCtx.Now())
+	minutes := schedule.TaskMinutes(task)
+
+	requested, hasDue := task.DueDay(loc)
+	if hasDue && requested.Before(today) {
+		return nil, &ValidationError{Tool: ToolCreateTask.Name, Errors: []FieldError{
+			{Field: "due_date", Message: fmt.Sprintf("must not be before today (%s)", today.Format("2006-01-02"))},
+		}}
+	}
+
+	settings, err := e.settingsRepo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	availability, err := e.availRepo.Get(ctx, settings.DailyMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	// FirstFit searches a horizon from the requested day, or from earliest
+	// for a task without a due date, so the bookings must reach its end.
+	from := today
+	if hasDue {
+		from = requested
+	} else if earliest.After(from) {
+		from = earliest
+	}
+	last := today.AddDate(0, 0, schedule.Horizon-1)
+	if end := from.AddDate(0, 0, schedule.Horizon-1); end.After(last) {
+		last = end
+	}
+	booked, err := e.taskRepo.GetDueBetween(ctx, today, last, loc)
+	if err != nil {
+		return nil, err
+	}
+	scheduler := schedule.NewScheduler(availability.MinutesOn, booked, loc)
+
+	if !hasDue {
+		day, ok := scheduler.FirstFit(from, minutes)
+		if !ok {
+			return nil, &NoRoomError{From: from.Format("2006-01-02"), TaskMinutes: minutes, Days: schedule.Horizon}
+		}
+		task.DueDate, task.DueKind = &day, models.DueKindDate
+		return &placement{assigned: true, load: scheduler.Load(day) + minutes, capacity: scheduler.Capacity(day)}, nil
+	}
+
+	if !scheduler.Fits(requested, minutes) && !allowOverbooking {
+		overbooked := &OverbookedError{
+			Day:         requested.Format("2006-01-02"),
+			TaskMinutes: minutes,
+			Booked:      scheduler.Load(requested),
+			Capacity:    scheduler.Capacity(requested),
+		}
+		if day, ok := scheduler.FirstFit(requested, minutes); ok {
+			overbooked.Suggested = day.Format("2006-01-02")
+		}
+		return nil, overbooked
+	}
+	return &placement{load: scheduler.Load(requested) + minutes, capacity: scheduler.Capacity(requested)}, nil
+}
+
+// parseDue turns create_task's due_date and optional due_time into a stored
+// due date. A date alone is a date-only due date; with a time it is the
+// instant at that local time in loc.
+func parseDue(date, clock string, loc *time.Location) (*time.Time, models.DueKind, error) {
+	if date == "" {
+		if clock != "" {
+			return nil, "", &ValidationError{Tool: ToolCreateTask.Name, Errors: []FieldError{
+				{Field: "due_time", Message: "requires due_date"},
+			}}
+		}
+		return nil, models.DueKindDate, nil
+	}
+
+	if clock == "" {
+		due, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return nil, "", err
+		}
+		return &due, models.DueKindDate, nil
+	}
+
+	local, err := time.ParseInLocation("2006-01-02 15:04", date+" "+clock, loc)
+	if err != nil {
+		return nil, "", &ValidationError{Tool: ToolCreateTask.Name, Errors: []FieldError{
+			{Field: "due_time", Message: "must be a time in HH:MM format"},
+		}}
+	}
+	due := local.UTC()
+	return &due, models.DueKindDateTime, nil
+}
+
+func (e *ToolExecutor) executeSuggestResources(ctx context.Context, args map[string]any, agentCtx *models.AgentContext) (map[string]any, error) {
+	if agentCtx.Goal == nil {
+		return nil, errNoGoal
+	}
+
+	var input suggestResourcesArgs
+	if err := decodeArguments(args, &input); err != nil {
+		return nil, err
+	}
+
+	var milestoneID, taskID *string
+	if input.MilestoneID != "" {
+		milestone, err := e.milestoneRepo.GetByID(ctx, input.MilestoneID)
+		if err != nil {
+			return nil, err
+		}
+		if milestone == nil || milestone.GoalID != agentCtx.Goal.ID {
+			return nil, fmt.Errorf("milestone %s not found for this goal", input.MilestoneID)
+		}
+		milestoneID = &input.MilestoneID
+	}
+	if input.TaskID != "" {
+		if _, err := e.goalTask(ctx, input.TaskID, agentCtx); err != nil {
+			return nil, err
+		}
+		taskID = &input.TaskID
+	}
+
+	ids := make([]string, 0, len(input.Resources))
+	for _, item := range input.Resources {
+		resource := &models.Resource{
+			GoalID:      agentCtx.Goal.ID,
+			MilestoneID: milestoneID,
+			TaskID:      taskID,
+			Title:       item.Title,
+			Type:        item.Type,
+			URL:         item.URL,
+			Notes:       item.Notes,
+			Status:      models.ResourceStatusToRead,
+		}
+		if err := e.resourceRepo.Create(ctx, resource); err != nil {
+			return nil, err
+		}
+		ids = append(ids, resource.ID)
+	}
+
+	return map[string]any{
+		"resource_ids": ids,
+		"message":      fmt.Sprintf("Saved %d resource(s)", len(ids)),
 	}, nil
 }
 
-func (e *ToolExecutor) executeMarkComplete(ctx context.Context, args map[string]any) (map[string]any, error) {
-	taskID := args["task_id"].(string)
-	var mins *int
-	if m, ok := args["actual_minutes"].(float64); ok {
-		mi := int(m)
-		mins = &mi
+// executePresentTask looks up the task so the model presents it with its real
+// details, any resources attached to it or to its milestone, and the
+// unfinished prerequisites it is waiting on.
+func (e *ToolExecutor) executePresentTask(ctx context.Context, args map[string]any, agentCtx *models.AgentContext) (map[string]any, error) {
+	var input presentTaskArgs
+	if err := decodeArguments(args, &input); err != nil {
+		return nil, err
+	}
+
+	task, err := e.goalTask(ctx, input.TaskID, agentCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := e.resourceRepo.GetForTask(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+
+	prerequisites, err := e.depRepo.GetPrerequisites(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	var blockedBy []map[string]any
+	for _, prerequisite := range prerequisites {
+		if prerequisite.IsOpen() {
+			task.BlockedBy = append(task.BlockedBy, prerequisite.ID)
+			blockedBy = append(blockedBy, map[string]any{"task_id": prerequisite.ID, "title": prerequisite.Title, "status": prerequisite.Status})
+		}
+	}
+
+	result := map[string]any{
+		"task":      task,
+		"resources": resources,
+	}
+	if len(blockedBy) > 0 {
+		result["blocked_by"] = blockedBy
+		result["hint"] = "This task is waiting on unfinished prerequisites; suggest starting with one of them instead."
+	}
+	if input.Approach != "" {
+		result["approach"] = input.Approach
+	}
+	if len(input.Tips) > 0 {
+		result["tips"] = input.Tips
+	}
+	return result, nil
+}
+
+func (e *ToolExecutor) executeMarkComplete(ctx context.Context, args map[string]any, agentCtx *models.AgentContext) (map[string]any, error) {
+	var input markCompleteArgs
+	if err := decodeArguments(args, &input); err != nil {
+		return nil, err
+	}
+	if _, err := e.goalTask(ctx, input.TaskID, agentCtx); err != nil {
+		return nil, err
 	}
 
-	if err := e.taskRepo.MarkComplete(ctx, taskID, mins); err != nil {
+	if err := e.taskRepo.MarkComplete(ctx, input.TaskID, input.ActualMinutes); err != nil {
 		return nil, err
 	}
 
@@ -86,11 +550,16 @@ func (e *ToolExecutor) executeMarkComplete(ctx context.Context, args map[string]
 	}, nil
 }
 
-func (e *ToolExecutor) executeLogStruggle(ctx context.Context, args map[string]any) (map[string]any, error) {
-	taskID := args["task_id"].(string)
-	notes := args["notes"].(string)
+func (e *ToolExecutor) executeLogStruggle(ctx context.Context, args map[string]any, agentCtx *models.AgentContext) (map[string]any, error) {
+	var input logStruggleArgs
+	if err := decodeArguments(args, &input); err != nil {
+		return nil, err
+	}
+	if _, err := e.goalTask(ctx, input.TaskID, agentCtx); err != nil {
+		return nil, err
+	}
 
-	if err := e.taskRepo.LogStruggle(ctx, taskID, notes); err != nil {
+	if err := e.taskRepo.LogStruggle(ctx, input.TaskID, input.Notes); err != nil {
 		return nil, err
 	}
 
@@ -98,3 +567,178 @@ func (e *ToolExecutor) executeLogStruggle(ctx context.Context, args map[string]a
 		"message": "Struggle logged",
 	}, nil
 }
+
+func (e *ToolExecutor) executeRatePerformance(ctx context.Context, args map[string]any, agentCtx *models.AgentContext) (map[string]any, error) {
+	if agentCtx.Goal == nil {
+		return nil, errNoGoal
+	}
+
+	var input ratePerformanceArgs
+	if err := decodeArguments(args, &input); err != nil {
+		return nil, err
+	}
+
+	if _, err := e.goalTask(ctx, input.TaskID, agentCtx); err != nil {
+		return nil, err
+	}
+
+	entry := &models.PerformanceRating{
+		GoalID:   agentCtx.Goal.ID,
+		TaskID:   input.TaskID,
+		Rating:   input.Rating,
+		Feedback: input.Feedback,
+	}
+	if err := e.evalRepo.CreateRating(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"rating_id": entry.ID,
+		"message":   "Performance rating recorded",
+	}, nil
+}
+
+func (e *ToolExecutor) executeIdentifyWeakness(ctx context.Context, args map[string]any, agentCtx *models.AgentContext) (map[string]any, error) {
+	if agentCtx.Goal == nil {
+		return nil, errNoGoal
+	}
+
+	var input identifyWeaknessArgs
+	if err := decodeArguments(args, &input); err != nil {
+		return nil, err
+	}
+
+	weakness := &models.Weakness{
+		GoalID:     agentCtx.Goal.ID,
+		Area:       input.Area,
+		Evidence:   input.Evidence,
+		Suggestion: input.Suggestion,
+	}
+	if err := e.evalRepo.CreateWeakness(ctx, weakness); err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"weakness_id": weakness.ID,
+		"message":     "Weakness recorded",
+	}, nil
+}
+
+func (e *ToolExecutor) executeRecordCheckIn(ctx context.Context, args map[string]any, agentCtx *models.AgentContext) (map[string]any, error) {
+	if agentCtx.Goal == nil {
+		return nil, errNoGoal
+	}
+
+	var input recordCheckInArgs
+	if err := decodeArguments(args, &input); err != nil {
+		return nil, err
+	}
+
+	checkIn := &models.CheckIn{
+		GoalID:     agentCtx.Goal.ID,
+		Summary:    input.Summary,
+		Wins:       input.Wins,
+		Struggles:  input.Struggles,
+		MoodRating: input.MoodRating,
+	}
+	if err := e.checkInRepo.Create(ctx, checkIn); err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"checkin_id": checkIn.ID,
+		"message":    "Check-in recorded",
+	}, nil
+}
+
+// paceFactors scale how far away each pending task's due date is.
+var paceFactors = map[string]float64{
+	"slower":   1.5,
+	"faster":   0.75,
+	"maintain": 1,
+}
+
+func (e *ToolExecutor) executeAdjustPace(ctx context.Context, args map[string]any, agentCtx *models.AgentContext) (map[string]any, error) {
+	if agentCtx.Goal == nil {
+		return nil, errNoGoal
+	}
+
+	var input adjustPaceArgs
+	if err := decodeArguments(args, &input); err != nil {
+		return nil, err
+	}
+	factor := paceFactors[input.Adjustment]
+
+	tasks, err := e.taskRepo.GetPendingByGoalID(ctx, agentCtx.Goal.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	edges, err := e.depRepo.GetByGoalID(ctx, agentCtx.Goal.ID)
+	if err != nil {
+		return nil, err
+	}
+	dependencies := schedule.NewDependencies(tasks, edges)
+
+	loc := agentCtx.TimeZone()
+	today := models.CalendarDate(agentCtx.Now())
+	// Prerequisites are moved first so that days holds their new due days
+	// by the time their dependents are scaled.
+	days := make(map[string]time.Time)
+	dueDates := make(map[string]time.Time)
+	var rescheduled []map[string]any
+	for _, task := range dependencies.Sort(tasks) {
+		dueDay, ok := task.DueDay(loc)
+		if !ok || factor == 1 {
+			continue
+		}
+		day := scaleDueDate(dueDay, today, factor)
+		if earliest := dependencies.Earliest(task.ID, days, loc); earliest.After(day) {
+			day = earliest
+		}
+		days[task.ID] = day
+		if day.Equal(dueDay) {
+			continue
+		}
+		oldDue := task.FormatDue(loc)
+		due := task.MoveDueDay(day, loc)
+		dueDates[task.ID] = due
+		task.DueDate = &due
+		rescheduled = append(rescheduled, map[string]any{
+			"task_id":  task.ID,
+			"title":    task.Title,
+			"old_date": oldDue,
+			"new_date": task.FormatDue(loc),
+		})
+	}
+	if err := e.taskRepo.Reschedule(ctx, dueDates); err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"adjustment":  input.Adjustment,
+		"rescheduled": rescheduled,
+		"message":     fmt.Sprintf("Rescheduled %d pending tasks", len(rescheduled)),
+	}, nil
+}
+
+// scaleDueDate stretches or compresses the distance from today to due by
+// factor. Days are counted from 1 (today) so that tasks due today still move
+// when slowing down; overdue tasks are treated as due today.
+func scaleDueDate(due, today time.Time, factor float64) time.Time {
+	today = models.CalendarDate(today)
+	day := int(models.CalendarDate(due).Sub(today).Hours()/24) + 1
+	if day < 1 {
+		day = 1
+	}
+	scaled := day
+	if factor > 1 {
+		scaled = int(math.Ceil(float64(day) * factor))
+	} else {
+		scaled = int(math.Floor(float64(day) * factor))
+	}
+	if scaled < 1 {
+		scaled = 1
+	}
+	return today.AddDate(0, 0, scaled-1)
+}
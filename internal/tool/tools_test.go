@@ -0,0 +1,214 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"agent-coach/internal/models"
+	"agent-coach/internal/storage"
+)
+
+func TestScaleDueDate(t *testing.T) {
+	today := time.Date(2026, 1, 20, 15, 30, 0, 0, time.Local)
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name   string
+		due    time.Time
+		factor float64
+		want   time.Time
+	}{
+		{"slower moves today's task to tomorrow", day(20), 1.5, day(21)},
+		{"slower stretches later tasks", day(23), 1.5, day(25)},
+		{"slower treats overdue as today", day(15), 1.5, day(21)},
+		{"faster pulls tasks closer", day(23), 0.75, day(22)},
+		{"faster never schedules before today", day(21), 0.75, day(20)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scaleDueDate(tt.due, today, tt.factor)
+			if !got.Equal(tt.want) {
+				t.Errorf("scaleDueDate(%s, %v) = %s, want %s",
+					tt.due.Format("2006-01-02"), tt.factor, got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestParseDue(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load zone: %v", err)
+	}
+
+	due, kind, err := parseDue("2026-03-09", "", ny)
+	if err != nil || kind != models.DueKindDate || !due.Equal(time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("date only = %v, %s, %v", due, kind, err)
+	}
+
+	// 09:00 is 14:00 UTC before the spring-forward change and 13:00 after.
+	for date, wantHour := range map[string]int{"2026-03-07": 14, "2026-03-09": 13} {
+		due, kind, err := parseDue(date, "09:00", ny)
+		if err != nil || kind != models.DueKindDateTime || due.Hour() != wantHour || due.Location() != time.UTC {
+			t.Errorf("%s 09:00 = %v, %s, %v; want %02d:00 UTC", date, due, kind, err, wantHour)
+		}
+	}
+
+	var validationErr *ValidationError
+	if _, _, err := parseDue("", "09:00", ny); !errors.As(err, &validationErr) {
+		t.Errorf("time without date error = %v, want ValidationError", err)
+	}
+	if _, _, err := parseDue("2026-03-09", "9am", ny); !errors.As(err, &validationErr) {
+		t.Errorf("malformed time error = %v, want ValidationError", err)
+	}
+}
+
+func TestTaskToolsRefuseTasksOfOtherGoals(t *testing.T) {
+	ctx := context.Background()
+	db, agentCtx := newTestGoal(t)
+
+	other := &models.Goal{Title: "Learn Rust", Status: models.GoalStatusActive}
+	if err := storage.NewGoalRepository(db).Create(ctx, other); err != nil {
+		t.Fatalf("create goal: %v", err)
+	}
+	taskRepo := storage.NewTaskRepository(db)
+	foreign := &models.Task{GoalID: other.ID, Title: "Read the Rust book", Status: models.TaskStatusPending}
+	if err := taskRepo.Create(ctx, foreign); err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	executor := NewToolExecutor(db)
+	calls := map[string]map[string]any{
+		"present_task":     {"task_id": foreign.ID},
+		"mark_complete":    {"task_id": foreign.ID, "actual_minutes": 30.0},
+		"log_struggle":     {"task_id": foreign.ID, "notes": "Lifetimes are confusing"},
+		"rate_performance": {"task_id": foreign.ID, "rating": 4.0, "feedback": "Good work"},
+	}
+	for name, args := range calls {
+		_, err := executor.ExecuteTool(ctx, name, args, agentCtx)
+		if err == nil || !strings.Contains(err.Error(), "not found for this goal") {
+			t.Errorf("%s error = %v, want task not found for this goal", name, err)
+		}
+		if result := ErrorResult(err); result["error"] == nil {
+			t.Errorf("%s error result = %v", name, result)
+		}
+	}
+
+	stored, err := taskRepo.GetByID(ctx, foreign.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.Status != models.TaskStatusPending || stored.StruggleNotes != "" {
+		t.Errorf("task of the other goal was changed: %+v", stored)
+	}
+
+	own := &models.Task{GoalID: agentCtx.Goal.ID, Title: "Tour of Go", Status: models.TaskStatusPending}
+	if err := taskRepo.Create(ctx, own); err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if _, err := executor.ExecuteTool(ctx, "mark_complete", map[string]any{"task_id": own.ID}, agentCtx); err != nil {
+		t.Errorf("mark_complete on the goal's own task: %v", err)
+	}
+}
+
+func TestCreateMilestone(t *testing.T) {
+	ctx := context.Background()
+	db, agentCtx := newTestGoal(t)
+	executor := NewToolExecutor(db)
+
+	result, err := executor.ExecuteTool(ctx, "create_milestone", map[string]any{
+		"title": "Go basics", "description": "Syntax, types and the standard tooling", "week_start": 1.0, "week_end": 2.0,
+	}, agentCtx)
+	if err != nil {
+		t.Fatalf("create_milestone: %v", err)
+	}
+	id, _ := result["milestone_id"].(string)
+
+	stored, err := storage.NewMilestoneRepository(db).GetByID(ctx, id)
+	if err != nil || stored == nil {
+		t.Fatalf("GetByID(%q) = %v, %v", id, stored, err)
+	}
+	if stored.GoalID != agentCtx.Goal.ID || stored.Title != "Go basics" || stored.WeekStart != 1 || stored.WeekEnd != 2 || stored.Status != models.MilestoneStatusPending {
+		t.Errorf("stored milestone = %+v", stored)
+	}
+	if len(agentCtx.Milestones) != 1 || agentCtx.Milestones[0].ID != id {
+		t.Errorf("context milestones = %+v, want the new milestone", agentCtx.Milestones)
+	}
+
+	_, err = executor.ExecuteTool(ctx, "create_milestone", map[string]any{
+		"title": "Backwards", "description": "Ends before it starts", "week_start": 3.0, "week_end": 2.0,
+	}, agentCtx)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Errors) != 1 || validationErr.Errors[0].Field != "week_end" {
+		t.Errorf("week_end before week_start error = %v, want ValidationError on week_end", err)
+	}
+
+	if _, err := executor.ExecuteTool(ctx, "create_milestone", map[string]any{
+		"title": "No goal", "description": "", "week_start": 1.0, "week_end": 1.0,
+	}, &models.AgentContext{}); !errors.Is(err, errNoGoal) {
+		t.Errorf("without a goal error = %v, want errNoGoal", err)
+	}
+}
+
+func TestSuggestResources(t *testing.T) {
+	ctx := context.Background()
+	db, agentCtx := newTestGoal(t)
+	executor := NewToolExecutor(db)
+
+	milestone := &models.Milestone{GoalID: agentCtx.Goal.ID, Title: "Go basics", WeekStart: 1, WeekEnd: 2}
+	if err := storage.NewMilestoneRepository(db).Create(ctx, milestone); err != nil {
+		t.Fatalf("create milestone: %v", err)
+	}
+
+	result, err := executor.ExecuteTool(ctx, "suggest_resources", map[string]any{
+		"milestone_id": milestone.ID,
+		"resources": []any{
+			map[string]any{"title": "A Tour of Go", "type": "course", "url": "https://go.dev/tour"},
+			map[string]any{"title": "Effective Go", "type": "docs", "notes": "Read the section on interfaces"},
+		},
+	}, agentCtx)
+	if err != nil {
+		t.Fatalf("suggest_resources: %v", err)
+	}
+	if ids, _ := result["resource_ids"].([]string); len(ids) != 2 {
+		t.Errorf("resource_ids = %v, want two", result["resource_ids"])
+	}
+
+	resources, err := storage.NewResourceRepository(db).GetByGoalID(ctx, agentCtx.Goal.ID)
+	if err != nil {
+		t.Fatalf("GetByGoalID: %v", err)
+	}
+	if len(resources) != 2 {
+		t.Fatalf("resources = %+v, want two", resources)
+	}
+	for _, resource := range resources {
+		if resource.MilestoneID == nil || *resource.MilestoneID != milestone.ID || resource.TaskID != nil || resource.Status != models.ResourceStatusToRead {
+			t.Errorf("resource = %+v, want it attached to the milestone and unread", resource)
+		}
+	}
+	if resources[0].URL != "https://go.dev/tour" || resources[1].Notes != "Read the section on interfaces" {
+		t.Errorf("resources = %+v, %+v", resources[0], resources[1])
+	}
+
+	other := &models.Milestone{GoalID: "another-goal", Title: "Rust basics", WeekStart: 1, WeekEnd: 1}
+	if err := storage.NewMilestoneRepository(db).Create(ctx, other); err != nil {
+		t.Fatalf("create milestone: %v", err)
+	}
+	for name, args := range map[string]map[string]any{
+		"milestone of another goal": {"milestone_id": other.ID, "resources": []any{map[string]any{"title": "The Rust book"}}},
+		"unknown task":              {"task_id": "missing", "resources": []any{map[string]any{"title": "The Rust book"}}},
+	} {
+		if _, err := executor.ExecuteTool(ctx, "suggest_resources", args, agentCtx); err == nil || !strings.Contains(err.Error(), "not found for this goal") {
+			t.Errorf("%s error = %v, want not found for this goal", name, err)
+		}
+	}
+
+	var validationErr *ValidationError
+	if _, err := executor.ExecuteTool(ctx, "suggest_resources", map[string]any{"resources": []any{}}, agentCtx); !errors.As(err, &validationErr) {
+		t.Errorf("no resources error = %v, want ValidationError", err)
+	}
+}